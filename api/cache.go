@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Validades padrão das entradas do cache
+const (
+	DefaultCacheTTL         = 30 * 24 * time.Hour
+	DefaultNegativeCacheTTL = 24 * time.Hour
+)
+
+// CacheConfig contém as configurações do cache de metadados
+type CacheConfig struct {
+	Dir         string        // diretório das entradas
+	TTL         time.Duration // validade de livros encontrados
+	NegativeTTL time.Duration // validade de respostas "não encontrado"
+	Refresh     bool          // ignora as entradas existentes, mas grava as novas respostas
+}
+
+// Cache é um cache em disco de consultas a provedores de metadados, com uma
+// entrada JSON por provedor+ISBN
+type Cache struct {
+	dir         string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	refresh     bool
+}
+
+// cacheEntry é o conteúdo gravado para cada provedor+ISBN
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Found     bool      `json:"found"`
+	Book      *BookData `json:"book,omitempty"`
+}
+
+// NewCache cria o cache, criando o diretório se necessário
+func NewCache(config CacheConfig) (*Cache, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("diretório do cache não configurado")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do cache: %w", err)
+	}
+
+	ttl := config.TTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	negativeTTL := config.NegativeTTL
+	if negativeTTL == 0 {
+		negativeTTL = DefaultNegativeCacheTTL
+	}
+
+	return &Cache{
+		dir:         config.Dir,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		refresh:     config.Refresh,
+	}, nil
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// path retorna o arquivo da entrada de um provedor+ISBN
+func (c *Cache) path(provider, isbn string) string {
+	return filepath.Join(c.dir, unsafeKeyChars.ReplaceAllString(provider, "_"),
+		unsafeKeyChars.ReplaceAllString(isbn, "_")+".json")
+}
+
+// get retorna a entrada válida de um provedor+ISBN, se houver
+func (c *Cache) get(provider, isbn string) (*cacheEntry, bool) {
+	if c.refresh {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(provider, isbn))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	ttl := c.ttl
+	if !entry.Found {
+		ttl = c.negativeTTL
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// put grava a entrada de um provedor+ISBN. A escrita em arquivo temporário
+// seguida de rename evita entradas corrompidas com vários workers.
+func (c *Cache) put(provider, isbn string, entry *cacheEntry) error {
+	path := c.path(provider, isbn)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do cache: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar entrada do cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+	return nil
+}
+
+// CachedProvider consulta o cache antes do provedor e grava as respostas,
+// inclusive "não encontrado" (cache negativo). Erros transitórios não são gravados.
+type CachedProvider struct {
+	provider MetadataProvider
+	cache    *Cache
+	key      string // nome das entradas do provedor no cache
+}
+
+// NewCachedProvider envolve um provedor com o cache informado. As entradas
+// ficam sob o nome do provedor.
+func NewCachedProvider(provider MetadataProvider, cache *Cache) *CachedProvider {
+	return &CachedProvider{provider: provider, cache: cache, key: provider.Name()}
+}
+
+// withCache envolve o provedor com o cache, se houver, gravando as entradas
+// sob a chave informada
+func withCache(provider MetadataProvider, cache *Cache, key string) MetadataProvider {
+	if cache == nil {
+		return provider
+	}
+	return &CachedProvider{provider: provider, cache: cache, key: key}
+}
+
+// cacheKey retorna a chave das entradas de um provedor no cache. O endpoint
+// público usa só o nome, mantendo as entradas já gravadas; outros endpoints
+// (ex.: um espelho) têm entradas próprias, pois podem responder diferente.
+func cacheKey(name, baseURL, defaultURL string) string {
+	if baseURL == "" || baseURL == defaultURL {
+		return name
+	}
+	endpoint := strings.TrimPrefix(strings.TrimPrefix(baseURL, "https://"), "http://")
+	return name + "@" + strings.TrimSuffix(endpoint, "/")
+}
+
+// GetBookData retorna a entrada do cache ou consulta o provedor
+func (p *CachedProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	if entry, ok := p.cache.get(p.key, isbn); ok {
+		if !entry.Found || entry.Book == nil {
+			return nil, fmt.Errorf("%w (cache)", notFoundError(isbn))
+		}
+		book := *entry.Book
+		return &book, nil
+	}
+
+	book, err := p.provider.GetBookData(ctx, isbn)
+
+	var entry *cacheEntry
+	switch {
+	case err == nil:
+		entry = &cacheEntry{FetchedAt: time.Now(), Found: true, Book: book}
+	case ClassOf(err) == ErrorNotFound:
+		entry = &cacheEntry{FetchedAt: time.Now(), Found: false}
+	}
+
+	// Falha ao gravar o cache não impede o uso da resposta
+	if entry != nil {
+		if err := p.cache.put(p.key, isbn, entry); err != nil {
+			log.Printf("Aviso: %v", err)
+		}
+	}
+
+	return book, err
+}
+
+// Name retorna o nome do provedor original
+func (p *CachedProvider) Name() string {
+	return p.provider.Name()
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const cachedISBN = "9780132350884"
+
+// newTestCache cria um cache em diretório temporário
+func newTestCache(t *testing.T, config CacheConfig) *Cache {
+	t.Helper()
+	if config.Dir == "" {
+		config.Dir = t.TempDir()
+	}
+	cache, err := NewCache(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+// fetch consulta o provedor e falha o teste se o título não for o esperado
+func fetch(t *testing.T, p MetadataProvider, want string) {
+	t.Helper()
+	book, err := p.GetBookData(context.Background(), cachedISBN)
+	if err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+	if book.Title != want {
+		t.Errorf("título = %q, esperado %q", book.Title, want)
+	}
+}
+
+func TestCachedProviderHitMiss(t *testing.T) {
+	cache := newTestCache(t, CacheConfig{})
+	stub := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Clean code"}}
+	p := NewCachedProvider(stub, cache)
+
+	fetch(t, p, "Clean code")
+	fetch(t, p, "Clean code")
+	if stub.calls != 1 {
+		t.Errorf("provedor consultado %d vezes, esperado 1", stub.calls)
+	}
+
+	// Outro ISBN é uma entrada diferente
+	if _, err := p.GetBookData(context.Background(), "9780596007126"); err != nil {
+		t.Fatal(err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("provedor consultado %d vezes, esperado 2", stub.calls)
+	}
+	if p.Name() != ProviderOpenLibrary {
+		t.Errorf("Name = %q", p.Name())
+	}
+}
+
+func TestCachedProviderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"não encontrado é gravado", notFoundError(cachedISBN), 1},
+		{"erro transitório não é gravado", &APIError{Class: ErrorServer, ISBN: cachedISBN, StatusCode: 502}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubProvider{name: ProviderOpenLibrary, err: tt.err}
+			p := NewCachedProvider(stub, newTestCache(t, CacheConfig{}))
+
+			for i := 0; i < 2; i++ {
+				_, err := p.GetBookData(context.Background(), cachedISBN)
+				if ClassOf(err) != ClassOf(tt.err) {
+					t.Fatalf("consulta %d: erro = %v", i+1, err)
+				}
+			}
+			if stub.calls != tt.wantCalls {
+				t.Errorf("provedor consultado %d vezes, esperado %d", stub.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   cacheEntry
+		expired bool
+	}{
+		{"livro dentro da validade", cacheEntry{FetchedAt: time.Now().Add(-time.Hour), Found: true, Book: &BookData{Title: "Em cache"}}, false},
+		{"livro vencido", cacheEntry{FetchedAt: time.Now().Add(-3 * time.Hour), Found: true, Book: &BookData{Title: "Em cache"}}, true},
+		{"não encontrado dentro da validade", cacheEntry{FetchedAt: time.Now().Add(-30 * time.Minute)}, false},
+		{"não encontrado vencido", cacheEntry{FetchedAt: time.Now().Add(-time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newTestCache(t, CacheConfig{TTL: 2 * time.Hour, NegativeTTL: 45 * time.Minute})
+			if err := cache.put(ProviderOpenLibrary, cachedISBN, &tt.entry); err != nil {
+				t.Fatal(err)
+			}
+
+			stub := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Da API"}}
+			NewCachedProvider(stub, cache).GetBookData(context.Background(), cachedISBN)
+
+			wantCalls := 0
+			if tt.expired {
+				wantCalls = 1
+			}
+			if stub.calls != wantCalls {
+				t.Errorf("provedor consultado %d vezes, esperado %d", stub.calls, wantCalls)
+			}
+
+			// Entrada vencida é substituída pela nova resposta
+			if tt.expired {
+				entry, ok := cache.get(ProviderOpenLibrary, cachedISBN)
+				if !ok || entry.Book == nil || entry.Book.Title != "Da API" {
+					t.Errorf("entrada = %+v, %v", entry, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	dir := t.TempDir()
+	cache := newTestCache(t, CacheConfig{Dir: dir})
+	if err := cache.put(ProviderOpenLibrary, cachedISBN, &cacheEntry{FetchedAt: time.Now(), Found: true, Book: &BookData{Title: "Antigo"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refresh ignora a entrada existente e grava a resposta nova
+	stub := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Novo"}}
+	refresh := NewCachedProvider(stub, newTestCache(t, CacheConfig{Dir: dir, Refresh: true}))
+	fetch(t, refresh, "Novo")
+	fetch(t, refresh, "Novo")
+	if stub.calls != 2 {
+		t.Errorf("provedor consultado %d vezes, esperado 2", stub.calls)
+	}
+
+	fetch(t, NewCachedProvider(stub, cache), "Novo")
+	if stub.calls != 2 {
+		t.Errorf("cache normal consultou o provedor após o refresh")
+	}
+}
+
+func TestCacheCorruptFile(t *testing.T) {
+	cache := newTestCache(t, CacheConfig{})
+	path := cache.path(ProviderOpenLibrary, cachedISBN)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"fetched_at": "ontem", "found": tr`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entrada ilegível conta como ausente e é regravada
+	stub := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Clean code"}}
+	p := NewCachedProvider(stub, cache)
+	fetch(t, p, "Clean code")
+	fetch(t, p, "Clean code")
+	if stub.calls != 1 {
+		t.Errorf("provedor consultado %d vezes, esperado 1", stub.calls)
+	}
+}
+
+func TestNewProvidersCacheKeyByBaseURL(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "openlibrary_9780132350884.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// serve conta as consultas a um endpoint da OpenLibrary
+	serve := func(hits *int32) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(fixture)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	var primaryHits, mirrorHits int32
+	primary, mirror := serve(&primaryHits), serve(&mirrorHits)
+	cache := newTestCache(t, CacheConfig{})
+
+	for _, srv := range []*httptest.Server{primary, mirror, primary, mirror} {
+		p, err := NewProvider(ProviderConfig{Provider: ProviderOpenLibrary, BaseURL: srv.URL, Timeout: 5, Cache: cache})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fetch(t, p, "Clean code")
+	}
+
+	if primaryHits != 1 || mirrorHits != 1 {
+		t.Errorf("consultas: principal %d, espelho %d; esperado 1 em cada", primaryHits, mirrorHits)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"", ProviderOpenLibrary},
+		{DefaultOpenLibraryURL, ProviderOpenLibrary},
+		{"http://espelho.local:8080/api/books/", ProviderOpenLibrary + "@espelho.local:8080/api/books"},
+	}
+
+	for _, tt := range tests {
+		if got := cacheKey(ProviderOpenLibrary, tt.baseURL, DefaultOpenLibraryURL); got != tt.want {
+			t.Errorf("cacheKey(%q) = %q, esperado %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChainProvider consulta vários provedores em ordem de prioridade e mescla os
+// resultados campo a campo: por padrão vence o primeiro valor não vazio, mas a
+// ordem pode ser definida por campo
+type ChainProvider struct {
+	providers     []MetadataProvider
+	fieldPriority map[string][]string
+}
+
+// NewChainProvider cria um provedor encadeado. fieldPriority mapeia um campo
+// (ex.: "description") para os nomes dos provedores preferidos para ele; os
+// provedores não listados seguem na ordem da cadeia.
+func NewChainProvider(providers []MetadataProvider, fieldPriority map[string][]string) (*ChainProvider, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("nenhum provedor configurado na cadeia")
+	}
+
+	names := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		names[p.Name()] = true
+	}
+
+	known := make(map[string]bool, len(BookFields))
+	for _, f := range BookFields {
+		known[f] = true
+	}
+
+	for field, order := range fieldPriority {
+		if !known[field] {
+			return nil, fmt.Errorf("campo desconhecido na prioridade de provedores: %s", field)
+		}
+		for _, name := range order {
+			if !names[name] {
+				return nil, fmt.Errorf("provedor %q da prioridade de %s não está na cadeia", name, field)
+			}
+		}
+	}
+
+	return &ChainProvider{
+		providers:     providers,
+		fieldPriority: fieldPriority,
+	}, nil
+}
+
+// GetBookData consulta os provedores e mescla os dados encontrados
+func (c *ChainProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	// Resultados pela posição na cadeia: provedores podem ter o mesmo nome
+	// (ex.: dois espelhos da OpenLibrary)
+	found := make([]*BookData, len(c.providers))
+	hits := 0
+	var errs []error
+
+	for i, p := range c.providers {
+		data, err := p.GetBookData(ctx, isbn)
+		if err != nil {
+			// Cancelamento não é falha do provedor: não consultar os demais
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("consulta do ISBN %s interrompida: %w", isbn, ctx.Err())
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		found[i] = data
+		hits++
+
+		// Sem preferências por campo, não há por que consultar os demais
+		// provedores quando todos os campos já estão preenchidos
+		if len(c.fieldPriority) == 0 && c.merge(isbn, found).complete() {
+			break
+		}
+	}
+
+	if hits == 0 {
+		return nil, fmt.Errorf("ISBN %s não encontrado em nenhum provedor: %w", isbn, errors.Join(errs...))
+	}
+
+	return c.merge(isbn, found), nil
+}
+
+// merge combina os resultados de cada provedor respeitando a prioridade por campo
+func (c *ChainProvider) merge(isbn string, found []*BookData) *BookData {
+	merged := &BookData{
+		ISBN:    isbn,
+		Sources: make(map[string]string),
+	}
+
+	for _, field := range BookFields {
+		for _, i := range c.order(field) {
+			data := found[i]
+			if data == nil || data.field(field) == "" {
+				continue
+			}
+			merged.copyField(field, data)
+			merged.Sources[field] = sourceOf(data, field, c.providers[i].Name())
+			break
+		}
+	}
+
+	return merged
+}
+
+// order retorna as posições dos provedores na ordem de consulta de um campo:
+// primeiro os preferidos para ele, depois os demais na ordem da cadeia
+func (c *ChainProvider) order(field string) []int {
+	order := make([]int, 0, len(c.providers))
+	seen := make([]bool, len(c.providers))
+
+	for _, name := range c.fieldPriority[field] {
+		for i, p := range c.providers {
+			if !seen[i] && p.Name() == name {
+				order = append(order, i)
+				seen[i] = true
+			}
+		}
+	}
+	for i := range c.providers {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// sourceOf preserva a proveniência informada pelo provedor (ex.: cadeias aninhadas)
+func sourceOf(data *BookData, field, provider string) string {
+	if source := data.Sources[field]; source != "" {
+		return source
+	}
+	return provider
+}
+
+// complete indica se todos os campos estão preenchidos
+func (d *BookData) complete() bool {
+	for _, field := range BookFields {
+		if d.field(field) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Name retorna os provedores da cadeia (ex.: "openlibrary+googlebooks")
+func (c *ChainProvider) Name() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
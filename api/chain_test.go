@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubProvider devolve sempre os mesmos dados ou o mesmo erro
+type stubProvider struct {
+	name  string
+	data  *BookData
+	err   error
+	calls int
+}
+
+func (s *stubProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	copied := *s.data
+	return &copied, nil
+}
+
+func (s *stubProvider) Name() string {
+	return s.name
+}
+
+func TestChainProviderSameName(t *testing.T) {
+	// Dois espelhos com o mesmo nome: o segundo completa o que falta no primeiro
+	primary := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Clean Code"}}
+	mirror := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Clean code (espelho)", Pages: 431, Publisher: "Prentice Hall"}}
+
+	chain, err := NewChainProvider([]MetadataProvider{primary, mirror}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := chain.GetBookData(context.Background(), "9780132350884")
+	if err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+	if book.Title != "Clean Code" || book.Pages != 431 || book.Publisher != "Prentice Hall" {
+		t.Errorf("livro = %+v", book)
+	}
+	if mirror.calls != 1 {
+		t.Errorf("espelho consultado %d vezes", mirror.calls)
+	}
+}
+
+func TestChainProviderFieldPriority(t *testing.T) {
+	ol := &stubProvider{name: ProviderOpenLibrary, data: &BookData{Title: "Clean code", Description: "curta", Pages: 431}}
+	gb := &stubProvider{name: ProviderGoogleBooks, data: &BookData{Title: "Clean Code", Description: "longa"}}
+
+	chain, err := NewChainProvider([]MetadataProvider{ol, gb}, map[string][]string{
+		FieldDescription: {ProviderGoogleBooks},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := chain.GetBookData(context.Background(), "9780132350884")
+	if err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+	if book.Title != "Clean code" || book.Description != "longa" || book.Pages != 431 {
+		t.Errorf("livro = %+v", book)
+	}
+	if book.Sources[FieldDescription] != ProviderGoogleBooks || book.Sources[FieldTitle] != ProviderOpenLibrary {
+		t.Errorf("fontes = %v", book.Sources)
+	}
+}
+
+func TestChainProviderErrors(t *testing.T) {
+	missing := &stubProvider{name: ProviderOpenLibrary, err: notFoundError("9780132350884")}
+	down := &stubProvider{name: ProviderGoogleBooks, err: &APIError{Class: ErrorServer, ISBN: "9780132350884", StatusCode: 502}}
+
+	chain, err := NewChainProvider([]MetadataProvider{missing, down}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chain.GetBookData(context.Background(), "9780132350884")
+	if !errors.Is(err, ErrNotFound) || ClassOf(err) != ErrorServer {
+		t.Errorf("erro = %v (classe %s)", err, ClassOf(err))
+	}
+
+	if _, err := NewChainProvider([]MetadataProvider{missing}, map[string][]string{FieldTitle: {"outro"}}); err == nil {
+		t.Error("esperado erro para provedor fora da cadeia na prioridade")
+	}
+}
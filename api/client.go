@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,17 +11,17 @@ import (
 
 // OpenLibraryResponse representa a resposta da API OpenLibrary
 type OpenLibraryResponse struct {
-	ISBN             string       `json:"isbn"`
-	Title            string       `json:"title"`
-	Authors          []AuthorInfo `json:"authors"`
-	NumberOfPages    int          `json:"number_of_pages"`
-	Publishers       []struct {
+	ISBN          string       `json:"isbn"`
+	Title         string       `json:"title"`
+	Authors       []AuthorInfo `json:"authors"`
+	NumberOfPages int          `json:"number_of_pages"`
+	Publishers    []struct {
 		Name string `json:"name"`
 	} `json:"publishers"`
 	PublishDate      string      `json:"publish_date"`
 	FirstPublishDate string      `json:"first_publish_date"`
 	Description      Description `json:"description"`
-	Covers           []int        `json:"covers"`
+	Covers           []int       `json:"covers"`
 }
 
 type AuthorInfo struct {
@@ -32,6 +33,9 @@ type Description struct {
 	Value string `json:"value"`
 }
 
+// DefaultOpenLibraryURL é o endpoint padrão da API de livros da OpenLibrary
+const DefaultOpenLibraryURL = "https://openlibrary.org/api/books"
+
 // BookAPIClient para consumir a API de livros
 type BookAPIClient struct {
 	baseURL string
@@ -52,28 +56,39 @@ func NewBookAPIClient(baseURL string, timeoutSeconds int) *BookAPIClient {
 
 // GetBookByISBN consulta a API OpenLibrary por ISBN
 func (c *BookAPIClient) GetBookByISBN(isbn string) (*OpenLibraryResponse, error) {
+	return c.GetBookByISBNContext(context.Background(), isbn)
+}
+
+// GetBookByISBNContext consulta a API OpenLibrary por ISBN; o cancelamento do
+// contexto interrompe a requisição em andamento
+func (c *BookAPIClient) GetBookByISBNContext(ctx context.Context, isbn string) (*OpenLibraryResponse, error) {
 	url := fmt.Sprintf("%s?bibkeys=ISBN:%s&format=json&jscmd=data", c.baseURL, isbn)
 
-	resp, err := c.client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição para ISBN %s: %w", isbn, err)
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao fazer requisição para ISBN %s: %w", isbn, err)
+		return nil, &APIError{Class: ErrorNetwork, ISBN: isbn, Err: err}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code inválido para ISBN %s: %d", isbn, resp.StatusCode)
+	if err := checkStatus(resp, isbn); err != nil {
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta para ISBN %s: %w", isbn, err)
+		return nil, &APIError{Class: ErrorNetwork, ISBN: isbn, Err: err}
 	}
 
 	// Parse a resposta JSON
 	var result map[string]OpenLibraryResponse
 	err = json.Unmarshal(body, &result)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao fazer parse JSON para ISBN %s: %w", isbn, err)
+		return nil, &APIError{Class: ErrorDecode, ISBN: isbn, Err: err}
 	}
 
 	// Procurar a chave ISBN
@@ -83,25 +98,39 @@ func (c *BookAPIClient) GetBookByISBN(isbn string) (*OpenLibraryResponse, error)
 		return &book, nil
 	}
 
-	return nil, fmt.Errorf("ISBN %s não encontrado na API", isbn)
+	return nil, notFoundError(isbn)
 }
 
 // GetBookByISBNWithRetry tenta obter o livro com retry automático
 func (c *BookAPIClient) GetBookByISBNWithRetry(isbn string, maxRetries int) (*OpenLibraryResponse, error) {
+	return c.GetBookByISBNWithRetryContext(context.Background(), isbn, maxRetries)
+}
+
+// GetBookByISBNWithRetryContext tenta obter o livro com retry automático apenas
+// para erros transitórios, abortando a requisição e a espera entre tentativas
+// se o contexto for cancelado
+func (c *BookAPIClient) GetBookByISBNWithRetryContext(ctx context.Context, isbn string, maxRetries int) (*OpenLibraryResponse, error) {
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		book, err := c.GetBookByISBN(isbn)
+		book, err := c.GetBookByISBNContext(ctx, isbn)
 		if err == nil {
 			return book, nil
 		}
 
 		lastErr = err
 
+		// ISBN inexistente, resposta inválida etc. não mudam com nova tentativa
+		if !IsTransient(err) {
+			return nil, err
+		}
+
 		if attempt < maxRetries {
-			// Aguardar antes de tentar novamente (backoff exponencial)
-			wait := time.Duration(attempt*attempt) * time.Second
-			time.Sleep(wait)
+			select {
+			case <-time.After(Backoff(attempt, err)):
+			case <-ctx.Done():
+				return nil, fmt.Errorf("tentativas interrompidas para ISBN %s: %w", isbn, ctx.Err())
+			}
 		}
 	}
 
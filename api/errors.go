@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ErrorClass classifica as falhas de consulta aos provedores
+type ErrorClass string
+
+// Classes de erro retornadas pelo pacote api
+const (
+	ErrorNotFound    ErrorClass = "not_found"    // o provedor não conhece o ISBN
+	ErrorRateLimited ErrorClass = "rate_limited" // 429/503: excesso de requisições
+	ErrorServer      ErrorClass = "server"       // 5xx
+	ErrorClient      ErrorClass = "client"       // demais 4xx (ex.: chave inválida)
+	ErrorNetwork     ErrorClass = "network"      // conexão, DNS, timeout
+	ErrorDecode      ErrorClass = "decode"       // resposta em formato inesperado
+	ErrorUnknown     ErrorClass = "unknown"
+)
+
+// ErrNotFound indica que o provedor respondeu, mas não conhece o ISBN
+var ErrNotFound = errors.New("não encontrado")
+
+// APIError é o erro tipado de uma consulta a um provedor
+type APIError struct {
+	Class      ErrorClass
+	ISBN       string
+	StatusCode int           // status HTTP, se houver
+	RetryAfter time.Duration // pausa pedida pela API (rate_limited)
+	Err        error         // causa original
+}
+
+func (e *APIError) Error() string {
+	switch e.Class {
+	case ErrorNotFound:
+		return fmt.Sprintf("ISBN %s não encontrado na API", e.ISBN)
+	case ErrorRateLimited:
+		if e.RetryAfter > 0 {
+			return fmt.Sprintf("limite de requisições da API para ISBN %s (status %d, aguardar %s)", e.ISBN, e.StatusCode, e.RetryAfter)
+		}
+		return fmt.Sprintf("limite de requisições da API para ISBN %s (status %d)", e.ISBN, e.StatusCode)
+	case ErrorServer, ErrorClient:
+		return fmt.Sprintf("status code inválido para ISBN %s: %d", e.ISBN, e.StatusCode)
+	case ErrorNetwork:
+		return fmt.Sprintf("erro ao fazer requisição para ISBN %s: %v", e.ISBN, e.Err)
+	case ErrorDecode:
+		return fmt.Sprintf("erro ao fazer parse JSON para ISBN %s: %v", e.ISBN, e.Err)
+	default:
+		return fmt.Sprintf("erro ao consultar ISBN %s: %v", e.ISBN, e.Err)
+	}
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Transient indica se vale a pena tentar novamente
+func (e *APIError) Transient() bool {
+	return e.Class.Transient()
+}
+
+// Transient indica se a classe de erro é transitória
+func (c ErrorClass) Transient() bool {
+	switch c {
+	case ErrorRateLimited, ErrorServer, ErrorNetwork:
+		return true
+	}
+	return false
+}
+
+// notFoundError cria o erro de ISBN desconhecido pelo provedor
+func notFoundError(isbn string) *APIError {
+	return &APIError{Class: ErrorNotFound, ISBN: isbn, Err: ErrNotFound}
+}
+
+// ClassOf retorna a classe de um erro. Em erros compostos (ex.: falha de todos
+// os provedores de uma cadeia) prevalece uma classe transitória, se houver,
+// pois outra tentativa ainda pode ter sucesso.
+func ClassOf(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	class := ErrorUnknown
+	found := false
+	walkErrors(err, func(e error) bool {
+		apiErr, ok := e.(*APIError)
+		if !ok {
+			return true
+		}
+		if !found || apiErr.Class.Transient() {
+			class = apiErr.Class
+			found = true
+		}
+		return !apiErr.Class.Transient()
+	})
+	return class
+}
+
+// IsTransient indica se o erro é transitório e a consulta pode ser repetida
+func IsTransient(err error) bool {
+	return ClassOf(err).Transient()
+}
+
+// RetryAfterOf retorna a maior pausa pedida pela API entre os erros informados
+func RetryAfterOf(err error) time.Duration {
+	var wait time.Duration
+	walkErrors(err, func(e error) bool {
+		if apiErr, ok := e.(*APIError); ok && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		return true
+	})
+	return wait
+}
+
+// walkErrors percorre a árvore de erros (Unwrap simples e múltiplo) até fn retornar false
+func walkErrors(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if !walkErrors(inner, fn) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walkErrors(e.Unwrap(), fn)
+	}
+	return true
+}
+
+// Limites do backoff entre tentativas
+const (
+	backoffBase = 1 * time.Second
+	backoffMax  = 30 * time.Second
+)
+
+// Backoff retorna a espera antes da próxima tentativa: exponencial a partir de
+// 1s com jitter (entre metade e o valor cheio), para que workers que falharam
+// juntos não repitam juntos. Respeita a pausa pedida pela API, se maior.
+func Backoff(attempt int, err error) time.Duration {
+	d := backoffBase << uint(attempt-1)
+	if d > backoffMax || d <= 0 {
+		d = backoffMax
+	}
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+
+	if retryAfter := RetryAfterOf(err); retryAfter > d {
+		return retryAfter
+	}
+	return d
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGoogleBooksURL é o endpoint de volumes da API Google Books v1
+const DefaultGoogleBooksURL = "https://www.googleapis.com/books/v1/volumes"
+
+// GoogleBooksResponse representa a resposta da busca de volumes do Google Books
+type GoogleBooksResponse struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		ID         string     `json:"id"`
+		VolumeInfo VolumeInfo `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+// VolumeInfo contém os metadados de um volume do Google Books
+type VolumeInfo struct {
+	Title               string   `json:"title"`
+	Subtitle            string   `json:"subtitle"`
+	Authors             []string `json:"authors"`
+	Publisher           string   `json:"publisher"`
+	PublishedDate       string   `json:"publishedDate"`
+	Description         string   `json:"description"`
+	PageCount           int      `json:"pageCount"`
+	IndustryIdentifiers []struct {
+		Type       string `json:"type"`
+		Identifier string `json:"identifier"`
+	} `json:"industryIdentifiers"`
+	ImageLinks struct {
+		SmallThumbnail string `json:"smallThumbnail"`
+		Thumbnail      string `json:"thumbnail"`
+	} `json:"imageLinks"`
+}
+
+// GoogleBooksProvider consulta metadados na API Google Books v1
+type GoogleBooksProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewGoogleBooksProvider cria uma nova instância do provedor Google Books
+func NewGoogleBooksProvider(baseURL, apiKey string, timeoutSeconds int) *GoogleBooksProvider {
+	if baseURL == "" {
+		baseURL = DefaultGoogleBooksURL
+	}
+
+	return &GoogleBooksProvider{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: time.Duration(timeoutSeconds) * time.Second,
+		},
+	}
+}
+
+// GetBookData consulta o Google Books por ISBN
+func (p *GoogleBooksProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	query := url.Values{}
+	query.Set("q", "isbn:"+isbn)
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição para ISBN %s: %w", isbn, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, &APIError{Class: ErrorNetwork, ISBN: isbn, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp, isbn); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APIError{Class: ErrorNetwork, ISBN: isbn, Err: err}
+	}
+
+	var result GoogleBooksResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, &APIError{Class: ErrorDecode, ISBN: isbn, Err: err}
+	}
+
+	if len(result.Items) == 0 {
+		return nil, notFoundError(isbn)
+	}
+
+	return withSources(ConvertVolumeToBookData(isbn, &result.Items[0].VolumeInfo), p.Name()), nil
+}
+
+// Name retorna o nome do provedor
+func (p *GoogleBooksProvider) Name() string {
+	return ProviderGoogleBooks
+}
+
+// ConvertVolumeToBookData converte um volume do Google Books para o formato padronizado
+func ConvertVolumeToBookData(isbn string, info *VolumeInfo) *BookData {
+	title := info.Title
+	if info.Subtitle != "" {
+		title = title + ": " + info.Subtitle
+	}
+
+	author := ""
+	if len(info.Authors) > 0 {
+		author = info.Authors[0]
+	}
+
+	coverURL := info.ImageLinks.Thumbnail
+	if coverURL == "" {
+		coverURL = info.ImageLinks.SmallThumbnail
+	}
+	// A API devolve miniaturas em http; o mesmo endereço funciona em https
+	coverURL = strings.Replace(coverURL, "http://", "https://", 1)
+
+	return &BookData{
+		ISBN:        isbn,
+		Title:       title,
+		Author:      author,
+		Authors:     info.Authors,
+		Publisher:   info.Publisher,
+		PublishDate: info.PublishedDate,
+		Pages:       info.PageCount,
+		Description: info.Description,
+		CoverURL:    coverURL,
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGoogleBooksProvider(t *testing.T) {
+	srv := serveFixture(t, http.StatusOK, "googlebooks_9780132350884.json", func(r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("q") != "isbn:9780132350884" || q.Get("key") != "chave-teste" {
+			t.Errorf("consulta = %s", r.URL.RawQuery)
+		}
+	})
+
+	provider, err := NewProvider(ProviderConfig{Provider: ProviderGoogleBooks, BaseURL: srv.URL, APIKey: "chave-teste", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := provider.GetBookData(context.Background(), "9780132350884")
+	if err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+
+	want := &BookData{
+		ISBN:        "9780132350884",
+		Title:       "Clean Code: A Handbook of Agile Software Craftsmanship",
+		Author:      "Robert C. Martin",
+		Authors:     []string{"Robert C. Martin"},
+		Publisher:   "Pearson Education",
+		PublishDate: "2008-08-01",
+		Pages:       464,
+		Description: "Even bad code can function. But if code isn't clean, it can bring a development organization to its knees.",
+		CoverURL:    "https://books.google.com/books/content?id=hjEFCAAAQBAJ&printsec=frontcover&img=1&zoom=1&source=gbs_api",
+		Sources: map[string]string{
+			FieldTitle: ProviderGoogleBooks, FieldAuthor: ProviderGoogleBooks,
+			FieldPublisher: ProviderGoogleBooks, FieldPublishDate: ProviderGoogleBooks,
+			FieldPages: ProviderGoogleBooks, FieldDescription: ProviderGoogleBooks,
+			FieldCoverURL: ProviderGoogleBooks,
+		},
+	}
+	if !reflect.DeepEqual(book, want) {
+		t.Errorf("livro =\n%+v\nesperado\n%+v", book, want)
+	}
+}
+
+func TestGoogleBooksProviderWithoutKey(t *testing.T) {
+	srv := serveFixture(t, http.StatusOK, "googlebooks_9780132350884.json", func(r *http.Request) {
+		if _, ok := r.URL.Query()["key"]; ok {
+			t.Errorf("parâmetro key enviado sem chave configurada: %s", r.URL.RawQuery)
+		}
+	})
+
+	if _, err := NewGoogleBooksProvider(srv.URL, "", 5).GetBookData(context.Background(), "9780132350884"); err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+}
+
+func TestGoogleBooksProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		fixture string
+		class   ErrorClass
+	}{
+		{"nenhum volume", http.StatusOK, "googlebooks_empty.json", ErrorNotFound},
+		{"404", http.StatusNotFound, `{"error": {"code": 404, "message": "Not Found"}}`, ErrorNotFound},
+		{"JSON malformado", http.StatusOK, `{"totalItems": 1, "items": [`, ErrorDecode},
+		{"tipo inesperado", http.StatusOK, `{"totalItems": 1, "items": [{"volumeInfo": {"pageCount": "464"}}]}`, ErrorDecode},
+		{"chave inválida", http.StatusBadRequest, `{"error": {"code": 400, "message": "API key not valid"}}`, ErrorClient},
+		{"429", http.StatusTooManyRequests, `{"error": {"code": 429}}`, ErrorRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveFixture(t, tt.status, tt.fixture, nil)
+			provider := NewGoogleBooksProvider(srv.URL, "", 5)
+
+			book, err := provider.GetBookData(context.Background(), "9780132350884")
+			if book != nil {
+				t.Errorf("livro = %+v, esperado nil", book)
+			}
+			assertClass(t, err, tt.class)
+			if tt.class == ErrorNotFound && !errors.Is(err, ErrNotFound) {
+				t.Errorf("errors.Is(err, ErrNotFound) = false para %v", err)
+			}
+		})
+	}
+}
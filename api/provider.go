@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Provedores de metadados suportados
+const (
+	ProviderOpenLibrary = "openlibrary"
+	ProviderGoogleBooks = "googlebooks"
+	ProviderChain       = "chain"
+)
+
+// MetadataProvider é uma fonte de metadados de livros consultada por ISBN
+type MetadataProvider interface {
+	// GetBookData retorna os dados padronizados do livro; o cancelamento do
+	// contexto interrompe a consulta em andamento
+	GetBookData(ctx context.Context, isbn string) (*BookData, error)
+	// Name retorna o nome do provedor (ex.: "openlibrary")
+	Name() string
+}
+
+// ProviderConfig contém as configurações para criar um provedor
+type ProviderConfig struct {
+	Provider string // "openlibrary" (padrão), "googlebooks" ou "chain"
+	BaseURL  string // vazio usa o endpoint público do provedor
+	APIKey   string // chave opcional (Google Books)
+	Timeout  int    // timeout das requisições, em segundos
+
+	// Provedor "chain": fontes consultadas em ordem de prioridade e, opcionalmente,
+	// a ordem de preferência por campo (ex.: "description": ["googlebooks"])
+	Providers     []ProviderConfig
+	FieldPriority map[string][]string
+
+	// Cache em disco das consultas (nil desativa); aplicado a cada provedor
+	// individualmente, inclusive dentro de uma cadeia
+	Cache *Cache
+
+	// Limite de requisições por segundo e rajada, por provedor, compartilhado
+	// por todos os workers (0 = sem limite)
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// NewProvider cria o provedor de metadados indicado na configuração
+func NewProvider(config ProviderConfig) (MetadataProvider, error) {
+	providers, err := NewProviders(config, config.Cache)
+	if err != nil {
+		return nil, err
+	}
+	return providers[0], nil
+}
+
+// NewProviders cria um provedor para cada cache informado (nil = sem cache),
+// todos sobre as mesmas fontes e os mesmos limitadores de requisições. Serve
+// para usar caches diferentes com a mesma configuração (ex.: uma nova consulta
+// que ignora o cache) sem multiplicar o limite de requisições às APIs.
+// Provedores da cadeia com cache próprio usam esse cache em todas as pilhas.
+func NewProviders(config ProviderConfig, caches ...*Cache) ([]MetadataProvider, error) {
+	if len(caches) == 0 {
+		caches = []*Cache{nil}
+	}
+
+	var source MetadataProvider
+	var key string
+	switch strings.ToLower(config.Provider) {
+	case "", ProviderOpenLibrary:
+		baseURL := config.BaseURL
+		if baseURL == "" {
+			baseURL = DefaultOpenLibraryURL
+		}
+		source = NewOpenLibraryProvider(NewBookAPIClient(baseURL, config.Timeout))
+		key = cacheKey(source.Name(), baseURL, DefaultOpenLibraryURL)
+	case ProviderGoogleBooks, "google":
+		source = NewGoogleBooksProvider(config.BaseURL, config.APIKey, config.Timeout)
+		key = cacheKey(source.Name(), config.BaseURL, DefaultGoogleBooksURL)
+	case ProviderChain:
+		return newChainProviders(config, caches)
+	default:
+		return nil, fmt.Errorf("provedor de metadados desconhecido: %s", config.Provider)
+	}
+
+	// O cache fica à frente do limitador para que respostas em cache não
+	// consumam o limite
+	limited := withRateLimit(source, config.RequestsPerSecond, config.Burst)
+	providers := make([]MetadataProvider, len(caches))
+	for i, cache := range caches {
+		providers[i] = withCache(limited, cache, key)
+	}
+	return providers, nil
+}
+
+// newChainProviders cria uma cadeia para cada cache; cada provedor da cadeia é
+// criado uma vez e compartilhado entre as cadeias
+func newChainProviders(config ProviderConfig, caches []*Cache) ([]MetadataProvider, error) {
+	stacks := make([][]MetadataProvider, len(caches))
+
+	for _, pc := range config.Providers {
+		if pc.Timeout == 0 {
+			pc.Timeout = config.Timeout
+		}
+		if pc.RequestsPerSecond == 0 {
+			pc.RequestsPerSecond = config.RequestsPerSecond
+			pc.Burst = config.Burst
+		}
+
+		childCaches := caches
+		if pc.Cache != nil {
+			childCaches = make([]*Cache, len(caches))
+			for i := range childCaches {
+				childCaches[i] = pc.Cache
+			}
+		}
+
+		children, err := NewProviders(pc, childCaches...)
+		if err != nil {
+			return nil, err
+		}
+		for i, child := range children {
+			stacks[i] = append(stacks[i], child)
+		}
+	}
+
+	providers := make([]MetadataProvider, len(caches))
+	for i, stack := range stacks {
+		chain, err := NewChainProvider(stack, config.FieldPriority)
+		if err != nil {
+			return nil, err
+		}
+		providers[i] = chain
+	}
+	return providers, nil
+}
+
+// OpenLibraryProvider adapta o BookAPIClient à interface MetadataProvider
+type OpenLibraryProvider struct {
+	client *BookAPIClient
+}
+
+// NewOpenLibraryProvider cria um provedor a partir de um cliente OpenLibrary
+func NewOpenLibraryProvider(client *BookAPIClient) *OpenLibraryProvider {
+	return &OpenLibraryProvider{client: client}
+}
+
+// GetBookData consulta a OpenLibrary e converte a resposta
+func (p *OpenLibraryProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	book, err := p.client.GetBookByISBNContext(ctx, isbn)
+	if err != nil {
+		return nil, err
+	}
+	return withSources(ConvertToBookData(book), p.Name()), nil
+}
+
+// Name retorna o nome do provedor
+func (p *OpenLibraryProvider) Name() string {
+	return ProviderOpenLibrary
+}
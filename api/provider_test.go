@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// serveFixture responde com o status e o arquivo de testdata informados
+// (corpo literal se o nome não terminar em .json)
+func serveFixture(t *testing.T, status int, fixture string, check func(*http.Request)) *httptest.Server {
+	t.Helper()
+
+	body := []byte(fixture)
+	if filepath.Ext(fixture) == ".json" {
+		var err error
+		if body, err = os.ReadFile(filepath.Join("testdata", fixture)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// assertClass verifica a classe do erro retornado pelo provedor
+func assertClass(t *testing.T, err error, want ErrorClass) {
+	t.Helper()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("erro = %v, esperado *APIError da classe %s", err, want)
+	}
+	if apiErr.Class != want {
+		t.Errorf("classe = %s, esperado %s (%v)", apiErr.Class, want, err)
+	}
+}
+
+func TestOpenLibraryProvider(t *testing.T) {
+	srv := serveFixture(t, http.StatusOK, "openlibrary_9780132350884.json", func(r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("bibkeys") != "ISBN:9780132350884" || q.Get("format") != "json" || q.Get("jscmd") != "data" {
+			t.Errorf("consulta = %s", r.URL.RawQuery)
+		}
+	})
+
+	provider, err := NewProvider(ProviderConfig{Provider: ProviderOpenLibrary, BaseURL: srv.URL, Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := provider.GetBookData(context.Background(), "9780132350884")
+	if err != nil {
+		t.Fatalf("GetBookData: %v", err)
+	}
+
+	want := &BookData{
+		ISBN:        "9780132350884",
+		Title:       "Clean code",
+		Author:      "Robert C. Martin",
+		Authors:     []string{"Robert C. Martin"},
+		Publisher:   "Prentice Hall",
+		PublishDate: "2009",
+		Pages:       431,
+		Description: "Even bad code can function.",
+		CoverURL:    "https://covers.openlibrary.org/b/id/9641375-M.jpg",
+		Sources: map[string]string{
+			FieldTitle: ProviderOpenLibrary, FieldAuthor: ProviderOpenLibrary,
+			FieldPublisher: ProviderOpenLibrary, FieldPublishDate: ProviderOpenLibrary,
+			FieldPages: ProviderOpenLibrary, FieldDescription: ProviderOpenLibrary,
+			FieldCoverURL: ProviderOpenLibrary,
+		},
+	}
+	if !reflect.DeepEqual(book, want) {
+		t.Errorf("livro =\n%+v\nesperado\n%+v", book, want)
+	}
+}
+
+func TestOpenLibraryProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		class  ErrorClass
+	}{
+		{"ISBN ausente da resposta", http.StatusOK, `{}`, ErrorNotFound},
+		{"404", http.StatusNotFound, `{"error": "notfound"}`, ErrorNotFound},
+		{"JSON malformado", http.StatusOK, `{"ISBN:9780132350884": {"title": `, ErrorDecode},
+		{"tipo inesperado", http.StatusOK, `{"ISBN:9780132350884": {"number_of_pages": "431"}}`, ErrorDecode},
+		{"HTML no lugar de JSON", http.StatusOK, `<html>manutenção</html>`, ErrorDecode},
+		{"503", http.StatusServiceUnavailable, ``, ErrorRateLimited},
+		{"500", http.StatusInternalServerError, ``, ErrorServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveFixture(t, tt.status, tt.body, nil)
+			provider := NewOpenLibraryProvider(NewBookAPIClient(srv.URL, 5))
+
+			book, err := provider.GetBookData(context.Background(), "9780132350884")
+			if book != nil {
+				t.Errorf("livro = %+v, esperado nil", book)
+			}
+			assertClass(t, err, tt.class)
+			if tt.class == ErrorNotFound && !errors.Is(err, ErrNotFound) {
+				t.Errorf("errors.Is(err, ErrNotFound) = false para %v", err)
+			}
+		})
+	}
+}
+
+// limiterOf retorna o limitador por trás de um provedor (com ou sem cache)
+func limiterOf(t *testing.T, p MetadataProvider) *RateLimiter {
+	t.Helper()
+	if cached, ok := p.(*CachedProvider); ok {
+		p = cached.provider
+	}
+	limited, ok := p.(*RateLimitedProvider)
+	if !ok {
+		t.Fatalf("provedor %T sem limitador", p)
+	}
+	return limited.limiter
+}
+
+func TestNewProvidersShareLimiter(t *testing.T) {
+	cache, err := NewCache(CacheConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := NewCache(CacheConfig{Dir: t.TempDir(), Refresh: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	providers, err := NewProviders(ProviderConfig{Provider: ProviderGoogleBooks, RequestsPerSecond: 2, Burst: 1}, cache, refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("%d provedores, esperado 2", len(providers))
+	}
+	if providers[0].(*CachedProvider).cache != cache || providers[1].(*CachedProvider).cache != refresh {
+		t.Error("caches trocados entre as pilhas")
+	}
+	if limiterOf(t, providers[0]) != limiterOf(t, providers[1]) {
+		t.Error("as pilhas usam limitadores diferentes")
+	}
+
+	chains, err := NewProviders(ProviderConfig{
+		Provider:          ProviderChain,
+		RequestsPerSecond: 2,
+		Providers:         []ProviderConfig{{Provider: ProviderOpenLibrary}, {Provider: ProviderGoogleBooks}},
+	}, cache, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normal, fresh := chains[0].(*ChainProvider), chains[1].(*ChainProvider)
+	for i := range normal.providers {
+		if limiterOf(t, normal.providers[i]) != limiterOf(t, fresh.providers[i]) {
+			t.Errorf("provedor %d da cadeia com limitadores diferentes", i)
+		}
+	}
+	if limiterOf(t, normal.providers[0]) == limiterOf(t, normal.providers[1]) {
+		t.Error("provedores diferentes da cadeia compartilham o limitador")
+	}
+	if _, ok := fresh.providers[0].(*RateLimitedProvider); !ok {
+		t.Errorf("pilha sem cache com provedor %T", fresh.providers[0])
+	}
+}
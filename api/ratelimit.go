@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter é a pausa aplicada quando a API responde 429/503 sem Retry-After
+const defaultRetryAfter = 5 * time.Second
+
+// checkStatus converte respostas HTTP de erro em APIError
+func checkStatus(resp *http.Response, isbn string) error {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return &APIError{
+			Class:      ErrorRateLimited,
+			ISBN:       isbn,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		err := notFoundError(isbn)
+		err.StatusCode = resp.StatusCode
+		return err
+	case resp.StatusCode >= 500:
+		return &APIError{Class: ErrorServer, ISBN: isbn, StatusCode: resp.StatusCode}
+	default:
+		return &APIError{Class: ErrorClient, ISBN: isbn, StatusCode: resp.StatusCode}
+	}
+}
+
+// parseRetryAfter interpreta o cabeçalho Retry-After em segundos ou como data HTTP
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// RateLimiter é um token bucket compartilhado por todos os workers: permite
+// rajadas de até burst requisições e, em média, rate requisições por segundo
+type RateLimiter struct {
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	mu          sync.Mutex
+}
+
+// NewRateLimiter cria um limitador com a taxa (requisições por segundo) e a rajada informadas
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait bloqueia até haver uma requisição disponível ou o contexto ser cancelado
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(l.pausedUntil):
+			wait = l.pausedUntil.Sub(now)
+		case l.tokens >= 1:
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		default:
+			wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Pause suspende as requisições de todos os workers pelo tempo informado
+// (ex.: Retry-After de uma resposta 429). Ao fim da pausa as requisições
+// retomam no ritmo normal, sem rajada.
+func (l *RateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		l.tokens = 1
+		l.last = until
+	}
+}
+
+// refill repõe as fichas proporcionalmente ao tempo decorrido
+func (l *RateLimiter) refill(now time.Time) {
+	if now.Before(l.last) {
+		return
+	}
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// RateLimitedProvider aplica um RateLimiter às consultas de um provedor e
+// pausa as demais consultas quando a API sinaliza excesso de requisições
+type RateLimitedProvider struct {
+	provider MetadataProvider
+	limiter  *RateLimiter
+}
+
+// NewRateLimitedProvider envolve um provedor com o limitador informado
+func NewRateLimitedProvider(provider MetadataProvider, limiter *RateLimiter) *RateLimitedProvider {
+	return &RateLimitedProvider{provider: provider, limiter: limiter}
+}
+
+// withRateLimit envolve o provedor com um limitador próprio, se houver taxa configurada
+func withRateLimit(provider MetadataProvider, rate float64, burst int) MetadataProvider {
+	if rate <= 0 {
+		return provider
+	}
+	return NewRateLimitedProvider(provider, NewRateLimiter(rate, burst))
+}
+
+// GetBookData aguarda o limitador e consulta o provedor
+func (p *RateLimitedProvider) GetBookData(ctx context.Context, isbn string) (*BookData, error) {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("consulta do ISBN %s interrompida: %w", isbn, err)
+	}
+
+	book, err := p.provider.GetBookData(ctx, isbn)
+
+	if ClassOf(err) == ErrorRateLimited {
+		pause := RetryAfterOf(err)
+		if pause <= 0 {
+			pause = defaultRetryAfter
+		}
+		p.limiter.Pause(pause)
+	}
+
+	return book, err
+}
+
+// Name retorna o nome do provedor original
+func (p *RateLimitedProvider) Name() string {
+	return p.provider.Name()
+}
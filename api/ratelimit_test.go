@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"0", 0},
+		{"-5", 0},
+		{"amanhã", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"Fri, 01 Mar 2024 12:01:30 GMT", 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, esperado %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+// waitFor mede quanto tempo Wait bloqueou
+func waitFor(t *testing.T, l *RateLimiter) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	return time.Since(start)
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(10, 3) // uma ficha a cada 100ms
+
+	for i := 0; i < 3; i++ {
+		if d := waitFor(t, l); d > 50*time.Millisecond {
+			t.Errorf("requisição %d da rajada esperou %s", i+1, d)
+		}
+	}
+	if d := waitFor(t, l); d < 50*time.Millisecond {
+		t.Errorf("requisição além da rajada esperou só %s", d)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(0.1, 1) // próxima ficha em 10s
+	waitFor(t, l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := l.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, esperado DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Wait retornou %s após o cancelamento", d)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait com contexto cancelado = %v", err)
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	l := NewRateLimiter(1000, 5)
+
+	l.Pause(150 * time.Millisecond)
+	l.Pause(10 * time.Millisecond) // pausa menor não encurta a atual
+
+	if d := waitFor(t, l); d < 130*time.Millisecond {
+		t.Errorf("Wait durante a pausa esperou só %s", d)
+	}
+	if d := waitFor(t, l); d > 50*time.Millisecond {
+		t.Errorf("Wait após a pausa esperou %s", d)
+	}
+}
+
+func TestRateLimitedProviderRetryAfter(t *testing.T) {
+	providers := []struct {
+		name string
+		new  func(baseURL string) MetadataProvider
+	}{
+		{ProviderOpenLibrary, func(baseURL string) MetadataProvider {
+			return NewOpenLibraryProvider(NewBookAPIClient(baseURL, 5))
+		}},
+		{ProviderGoogleBooks, func(baseURL string) MetadataProvider {
+			return NewGoogleBooksProvider(baseURL, "", 5)
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter func() string
+		wantPause  time.Duration // aproximada, pela resolução de segundos da data HTTP
+		wantClass  ErrorClass
+	}{
+		{"429 em segundos", http.StatusTooManyRequests, func() string { return "30" }, 30 * time.Second, ErrorRateLimited},
+		{"503 com data HTTP", http.StatusServiceUnavailable, func() string {
+			return time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+		}, 2 * time.Minute, ErrorRateLimited},
+		{"429 sem Retry-After", http.StatusTooManyRequests, func() string { return "" }, defaultRetryAfter, ErrorRateLimited},
+		{"500 não pausa", http.StatusInternalServerError, func() string { return "30" }, 0, ErrorServer},
+	}
+
+	for _, p := range providers {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if v := tt.retryAfter(); v != "" {
+						w.Header().Set("Retry-After", v)
+					}
+					w.WriteHeader(tt.status)
+				}))
+				defer srv.Close()
+
+				limiter := NewRateLimiter(100, 5)
+				provider := NewRateLimitedProvider(p.new(srv.URL), limiter)
+
+				_, err := provider.GetBookData(context.Background(), "9780132350884")
+				assertClass(t, err, tt.wantClass)
+
+				limiter.mu.Lock()
+				pause := time.Until(limiter.pausedUntil)
+				limiter.mu.Unlock()
+
+				if tt.wantPause == 0 {
+					if pause > 0 {
+						t.Errorf("limitador pausado por %s", pause)
+					}
+					return
+				}
+				if pause > tt.wantPause || pause < tt.wantPause-2*time.Second {
+					t.Errorf("pausa = %s, esperado cerca de %s", pause, tt.wantPause)
+				}
+				if tt.retryAfter() != "" && RetryAfterOf(err) <= 0 {
+					t.Errorf("erro sem RetryAfter: %v", err)
+				}
+
+				// As próximas consultas aguardam a pausa
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				defer cancel()
+				if _, err := provider.GetBookData(ctx, "9780132350884"); !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("consulta durante a pausa = %v", err)
+				}
+			})
+		}
+	}
+}
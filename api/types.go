@@ -6,19 +6,100 @@ import "fmt"
 type BookData struct {
 	ISBN        string
 	Title       string
-	Author      string
+	Author      string   // primeiro autor
+	Authors     []string // todos os autores, na ordem de crédito
 	Publisher   string
 	PublishDate string
 	Pages       int
 	Description string
 	CoverURL    string
+	Sources     map[string]string // campo -> provedor que o forneceu
+}
+
+// Campos de BookData usados na mesclagem e na proveniência
+const (
+	FieldTitle       = "title"
+	FieldAuthor      = "author"
+	FieldPublisher   = "publisher"
+	FieldPublishDate = "publish_date"
+	FieldPages       = "pages"
+	FieldDescription = "description"
+	FieldCoverURL    = "cover_url"
+)
+
+// BookFields lista os campos mesclados de BookData, na ordem de exibição
+var BookFields = []string{
+	FieldTitle, FieldAuthor, FieldPublisher, FieldPublishDate,
+	FieldPages, FieldDescription, FieldCoverURL,
+}
+
+// field retorna o valor textual de um campo ("" se vazio)
+func (d *BookData) field(name string) string {
+	switch name {
+	case FieldTitle:
+		return d.Title
+	case FieldAuthor:
+		return d.Author
+	case FieldPublisher:
+		return d.Publisher
+	case FieldPublishDate:
+		return d.PublishDate
+	case FieldPages:
+		if d.Pages > 0 {
+			return fmt.Sprint(d.Pages)
+		}
+	case FieldDescription:
+		return d.Description
+	case FieldCoverURL:
+		return d.CoverURL
+	}
+	return ""
+}
+
+// copyField copia um campo de src para d
+func (d *BookData) copyField(name string, src *BookData) {
+	switch name {
+	case FieldTitle:
+		d.Title = src.Title
+	case FieldAuthor:
+		d.Author = src.Author
+		d.Authors = src.Authors
+	case FieldPublisher:
+		d.Publisher = src.Publisher
+	case FieldPublishDate:
+		d.PublishDate = src.PublishDate
+	case FieldPages:
+		d.Pages = src.Pages
+	case FieldDescription:
+		d.Description = src.Description
+	case FieldCoverURL:
+		d.CoverURL = src.CoverURL
+	}
+}
+
+// withSources registra o provedor como fonte de todos os campos preenchidos
+func withSources(d *BookData, provider string) *BookData {
+	d.Sources = make(map[string]string)
+	for _, name := range BookFields {
+		if d.field(name) != "" {
+			d.Sources[name] = provider
+		}
+	}
+	return d
 }
 
 // ConvertToBookData converte a resposta da OpenLibrary para um formato padronizado
 func ConvertToBookData(apiResponse *OpenLibraryResponse) *BookData {
+	var authors []string
+	for _, a := range apiResponse.Authors {
+		if a.Name != "" {
+			authors = append(authors, a.Name)
+		}
+	}
+
 	author := ""
-	if len(apiResponse.Authors) > 0 {
-		author = apiResponse.Authors[0].Name
+	if len(authors) > 0 {
+		author = authors[0]
 	}
 
 	publisher := ""
@@ -41,6 +122,7 @@ func ConvertToBookData(apiResponse *OpenLibraryResponse) *BookData {
 		ISBN:        apiResponse.ISBN,
 		Title:       apiResponse.Title,
 		Author:      author,
+		Authors:     authors,
 		Publisher:   publisher,
 		PublishDate: apiResponse.PublishDate,
 		Pages:       apiResponse.NumberOfPages,
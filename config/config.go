@@ -9,9 +9,9 @@ import (
 
 // Config contém todas as configurações da aplicação
 type Config struct {
-	Database DatabaseConfig `json:"database"`
-	API      APIConfig      `json:"api"`
-	Reader   ReaderConfig   `json:"reader"`
+	Database  DatabaseConfig  `json:"database"`
+	API       APIConfig       `json:"api"`
+	Reader    ReaderConfig    `json:"reader"`
 	Processor ProcessorConfig `json:"processor"`
 }
 
@@ -23,23 +23,66 @@ type DatabaseConfig struct {
 
 // APIConfig configurações da API
 type APIConfig struct {
-	Provider string `json:"provider"`
-	BaseURL  string `json:"baseUrl"`
+	Provider string `json:"provider"` // "openlibrary", "googlebooks" ou "chain"
+	BaseURL  string `json:"baseUrl"`  // vazio usa o endpoint público do provedor
+	APIKey   string `json:"apiKey"`   // chave opcional do Google Books
 	Timeout  int    `json:"timeout"`
+
+	// Limite global de requisições por provedor, compartilhado pelos workers
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+
+	// Cache em disco das consultas (validades em horas)
+	CacheDir         string `json:"cacheDir"`
+	CacheTTL         int    `json:"cacheTTL"`
+	NegativeCacheTTL int    `json:"negativeCacheTTL"`
+
+	// Provedor "chain": fontes em ordem de prioridade e preferência por campo
+	Providers     []APIConfig         `json:"providers"`
+	FieldPriority map[string][]string `json:"fieldPriority"`
 }
 
 // ReaderConfig configurações do leitor
 type ReaderConfig struct {
-	InputFile string `json:"inputFile"`
-	Type      string `json:"type"`
-	Verbose   bool   `json:"verbose"`
+	InputFile  string `json:"inputFile"`
+	Type       string `json:"type"`
+	DevicePath string `json:"devicePath"`
+	Timeout    int    `json:"timeout"`
+	GrabDevice bool   `json:"grabDevice"`
+	Verbose    bool   `json:"verbose"`
+
+	// Leitor "serial" (CDC-ACM / RS-232)
+	BaudRate       int    `json:"baudRate"`
+	Parity         string `json:"parity"`
+	LineTerminator string `json:"lineTerminator"`
+	StripPrefix    string `json:"stripPrefix"`
+	StripSuffix    string `json:"stripSuffix"`
+
+	// Leitor "image" (fotos de códigos de barras)
+	ImageDir string `json:"imageDir"`
+
+	// Leitor "watch" (pasta monitorada)
+	WatchDir     string `json:"watchDir"`
+	PollInterval int    `json:"pollInterval"`
+
+	// Leitor "stdin" (scanner em modo keyboard-wedge)
+	KeystrokeInterval int  `json:"keystrokeInterval"`
+	RejectManualInput bool `json:"rejectManualInput"`
+
+	// Leitor "csv" (planilhas com colunas extras por exemplar)
+	Delimiter string            `json:"delimiter"`
+	Header    string            `json:"header"`
+	Columns   map[string]string `json:"columns"`
+
+	// Leitor "multi": leitores executados simultaneamente
+	Sources []ReaderConfig `json:"sources"`
 }
 
 // ProcessorConfig configurações do processador
 type ProcessorConfig struct {
-	MaxWorkers           int `json:"maxWorkers"`
-	DelayBetweenRequests int `json:"delayBetweenRequests"`
-	MaxRetries           int `json:"maxRetries"`
+	MaxWorkers           int  `json:"maxWorkers"`
+	DelayBetweenRequests int  `json:"delayBetweenRequests"` // obsoleto: use api.requestsPerSecond
+	MaxRetries           int  `json:"maxRetries"`
 	Verbose              bool `json:"verbose"`
 }
 
@@ -69,8 +112,20 @@ func LoadConfig(filePath string) (*Config, error) {
 	if config.Database.Path == "" {
 		config.Database.Path = "./books.db"
 	}
-	if config.API.BaseURL == "" {
-		config.API.BaseURL = "https://openlibrary.org/api/books"
+	if config.API.Provider == "" {
+		config.API.Provider = "openlibrary"
+		if len(config.API.Providers) > 0 {
+			config.API.Provider = "chain"
+		}
+	}
+	if config.API.CacheDir == "" {
+		config.API.CacheDir = "./.cache/metadata"
+	}
+	if config.API.CacheTTL == 0 {
+		config.API.CacheTTL = 30 * 24
+	}
+	if config.API.NegativeCacheTTL == 0 {
+		config.API.NegativeCacheTTL = 24
 	}
 	if config.API.Timeout == 0 {
 		config.API.Timeout = 10
@@ -84,8 +139,15 @@ func LoadConfig(filePath string) (*Config, error) {
 	if config.Processor.MaxWorkers == 0 {
 		config.Processor.MaxWorkers = 1
 	}
-	if config.Processor.DelayBetweenRequests == 0 {
-		config.Processor.DelayBetweenRequests = 500
+	// delayBetweenRequests é mantido por compatibilidade: vira o limite global
+	if config.API.RequestsPerSecond == 0 {
+		config.API.RequestsPerSecond = 2
+		if config.Processor.DelayBetweenRequests > 0 {
+			config.API.RequestsPerSecond = 1000 / float64(config.Processor.DelayBetweenRequests)
+		}
+	}
+	if config.API.Burst == 0 {
+		config.API.Burst = 1
 	}
 	if config.Processor.MaxRetries == 0 {
 		config.Processor.MaxRetries = 3
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Papéis de um autor em um livro
+const (
+	RoleAuthor      = "author"
+	RoleEditor      = "editor"
+	RoleTranslator  = "translator"
+	RoleIllustrator = "illustrator"
+)
+
+// validRoles contém os papéis aceitos em book_authors
+var validRoles = map[string]bool{
+	RoleAuthor:      true,
+	RoleEditor:      true,
+	RoleTranslator:  true,
+	RoleIllustrator: true,
+}
+
+// BookAuthor representa um autor creditado em um livro
+type BookAuthor struct {
+	AuthorID int    `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Position int    `json:"position"`
+}
+
+// IsValidRole indica se o papel é aceito em book_authors (vazio equivale a "author")
+func IsValidRole(role string) bool {
+	role = strings.ToLower(strings.TrimSpace(role))
+	return role == "" || validRoles[role]
+}
+
+// SetBookAuthors substitui a lista de autores de um livro. A ordem da lista
+// define a ordem de crédito; autores sem ID são obtidos ou criados pelo nome
+// e papel vazio equivale a "author".
+func (db *Database) SetBookAuthors(bookID int, authors []BookAuthor) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	resolved, err := resolveBookAuthors(tx, authors)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceBookAuthors(tx, bookID, resolved); err != nil {
+		return err
+	}
+
+	// books.author_id continua apontando para o primeiro autor
+	var primary *int
+	if len(resolved) > 0 {
+		primary = &resolved[0].AuthorID
+	}
+	if _, err := tx.Exec("UPDATE books SET author_id = ? WHERE id = ?", primary, bookID); err != nil {
+		return fmt.Errorf("erro ao atualizar autor principal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar autores do livro: %w", err)
+	}
+	return nil
+}
+
+// resolveBookAuthors valida os papéis e obtém ou cria os autores informados
+// apenas pelo nome
+func resolveBookAuthors(q querier, authors []BookAuthor) ([]BookAuthor, error) {
+	resolved := make([]BookAuthor, 0, len(authors))
+	seen := make(map[string]bool)
+	for _, a := range authors {
+		a.Role = strings.ToLower(strings.TrimSpace(a.Role))
+		if a.Role == "" {
+			a.Role = RoleAuthor
+		}
+		if !validRoles[a.Role] {
+			return nil, fmt.Errorf("papel de autor inválido: %s", a.Role)
+		}
+
+		if a.AuthorID == 0 {
+			name := strings.TrimSpace(a.Name)
+			if name == "" {
+				continue
+			}
+			author, err := upsertAuthor(q, name)
+			if err != nil {
+				return nil, err
+			}
+			a.AuthorID = author.ID
+			a.Name = author.Name
+		}
+
+		// Duplicatas violariam a chave primária (livro, autor, papel)
+		key := fmt.Sprintf("%d/%s", a.AuthorID, a.Role)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		a.Position = len(resolved)
+		resolved = append(resolved, a)
+	}
+	return resolved, nil
+}
+
+// replaceBookAuthors grava a lista de autores já resolvida de um livro
+func replaceBookAuthors(q querier, bookID int, authors []BookAuthor) error {
+	if _, err := q.Exec("DELETE FROM book_authors WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("erro ao remover autores do livro: %w", err)
+	}
+
+	for _, a := range authors {
+		_, err := q.Exec(`
+			INSERT OR IGNORE INTO book_authors (book_id, author_id, position, role)
+			VALUES (?, ?, ?, ?)
+		`, bookID, a.AuthorID, a.Position, a.Role)
+		if err != nil {
+			return fmt.Errorf("erro ao gravar autor do livro: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetBookAuthors retorna os autores de um livro na ordem de crédito
+func (db *Database) GetBookAuthors(bookID int) ([]BookAuthor, error) {
+	authors, err := db.getAuthorsForBooks([]int{bookID})
+	if err != nil {
+		return nil, err
+	}
+	return authors[bookID], nil
+}
+
+// getAuthorsForBooks retorna os autores de vários livros em uma única consulta;
+// bookIDs nil retorna os autores de todos os livros
+func (db *Database) getAuthorsForBooks(bookIDs []int) (map[int][]BookAuthor, error) {
+	result := make(map[int][]BookAuthor)
+	if bookIDs != nil && len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT ba.book_id, ba.author_id, a.name, ba.role, ba.position
+		FROM book_authors ba
+		JOIN authors a ON a.id = ba.author_id`
+	var args []interface{}
+	if bookIDs != nil {
+		query += ` WHERE ba.book_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(bookIDs)), ",") + `)`
+		for _, id := range bookIDs {
+			args = append(args, id)
+		}
+	}
+	query += ` ORDER BY ba.book_id, ba.position`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar autores dos livros: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookID int
+		var a BookAuthor
+		if err := rows.Scan(&bookID, &a.AuthorID, &a.Name, &a.Role, &a.Position); err != nil {
+			return nil, fmt.Errorf("erro ao ler autor do livro: %w", err)
+		}
+		result[bookID] = append(result[bookID], a)
+	}
+
+	return result, rows.Err()
+}
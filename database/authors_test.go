@@ -0,0 +1,159 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+// authorCredits resume os autores como "nome/papel" na ordem de crédito
+func authorCredits(t *testing.T, db *Database, bookID int) []string {
+	t.Helper()
+	authors, err := db.GetBookAuthors(bookID)
+	if err != nil {
+		t.Fatalf("GetBookAuthors: %v", err)
+	}
+	credits := []string{}
+	for i, a := range authors {
+		if a.Position != i {
+			t.Errorf("%s na posição %d, esperado %d", a.Name, a.Position, i)
+		}
+		credits = append(credits, a.Name+"/"+a.Role)
+	}
+	return credits
+}
+
+// primaryAuthor retorna o nome em books.author_id ("" se nulo)
+func primaryAuthor(t *testing.T, db *Database, bookID int) string {
+	t.Helper()
+	var name string
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(a.name, '') FROM books b LEFT JOIN authors a ON a.id = b.author_id WHERE b.id = ?
+	`, bookID).Scan(&name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestSetBookAuthors(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+	book, err := db.SaveBook(&Book{ISBN: "9780596007126", Title: "Head First Design Patterns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		authors []BookAuthor
+		want    []string
+	}{
+		{
+			"ordem da lista e papéis normalizados",
+			[]BookAuthor{
+				{Name: "Eric Freeman"},
+				{Name: "Elisabeth Robson", Role: " Editor "},
+				{Name: "Eric Freeman", Role: RoleAuthor}, // duplicata ignorada
+				{Name: "  "},                             // sem nome ignorado
+				{Name: "Eric Freeman", Role: RoleTranslator},
+			},
+			[]string{"Eric Freeman/author", "Elisabeth Robson/editor", "Eric Freeman/translator"},
+		},
+		{
+			"reordenação",
+			[]BookAuthor{{Name: "Elisabeth Robson"}, {Name: "Eric Freeman"}},
+			[]string{"Elisabeth Robson/author", "Eric Freeman/author"},
+		},
+		{"lista vazia", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.SetBookAuthors(book.ID, tt.authors); err != nil {
+				t.Fatalf("SetBookAuthors: %v", err)
+			}
+			got := authorCredits(t, db, book.ID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("autores = %v, esperado %v", got, tt.want)
+			}
+
+			// books.author_id acompanha o primeiro autor
+			wantPrimary := ""
+			if len(tt.authors) > 0 {
+				wantPrimary = tt.authors[0].Name
+			}
+			if primary := primaryAuthor(t, db, book.ID); primary != wantPrimary {
+				t.Errorf("autor principal = %q, esperado %q", primary, wantPrimary)
+			}
+		})
+	}
+}
+
+func TestSetBookAuthorsInvalidRole(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+	book, err := db.SaveBook(&Book{ISBN: "9780132350884", Title: "Clean Code"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetBookAuthors(book.ID, []BookAuthor{{Name: "Robert C. Martin"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.SetBookAuthors(book.ID, []BookAuthor{{Name: "Dean Wampler"}, {Name: "Robert C. Martin", Role: "revisor"}})
+	if err == nil {
+		t.Fatal("papel inválido aceito")
+	}
+
+	// Nada da lista rejeitada é gravado
+	if got := authorCredits(t, db, book.ID); !reflect.DeepEqual(got, []string{"Robert C. Martin/author"}) {
+		t.Errorf("autores = %v", got)
+	}
+	var created int
+	db.conn.QueryRow("SELECT COUNT(*) FROM authors WHERE name = 'Dean Wampler'").Scan(&created)
+	if created != 0 {
+		t.Error("autor da lista rejeitada foi criado")
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	for role, want := range map[string]bool{
+		"":              true,
+		RoleAuthor:      true,
+		" Translator ":  true,
+		RoleIllustrator: true,
+		"revisor":       false,
+	} {
+		if got := IsValidRole(role); got != want {
+			t.Errorf("IsValidRole(%q) = %v, esperado %v", role, got, want)
+		}
+	}
+}
+
+func TestMigrateBookAuthorsBackfill(t *testing.T) {
+	db := newTestDatabase(t, 3)
+
+	_, err := db.conn.Exec(`
+		INSERT INTO authors (id, name) VALUES (1, 'Robert C. Martin'), (2, 'Eric Freeman');
+		INSERT INTO books (id, isbn, title, author_id) VALUES
+			(1, '9780132350884', 'Clean Code', 1),
+			(2, '9780596007126', 'Head First Design Patterns', 2),
+			(3, '9780201633610', 'Design Patterns', NULL);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.MigrateTo(4); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int][]string{
+		1: {"Robert C. Martin/author"},
+		2: {"Eric Freeman/author"},
+		3: {},
+	}
+	for bookID, credits := range want {
+		if got := authorCredits(t, db, bookID); !reflect.DeepEqual(got, credits) {
+			t.Errorf("livro %d: autores = %v, esperado %v", bookID, got, credits)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BookCopy representa um exemplar físico de um livro com seus dados próprios
+type BookCopy struct {
+	ID        int
+	BookID    int
+	Quantity  int
+	Condition string
+	Shelf     string
+	Donor     string
+	Source    string            // origem da leitura (ex.: "linha 7 de doacoes.csv")
+	Metadata  map[string]string // demais colunas da importação
+
+	// Arquivo e linha da importação. Quando informados identificam o
+	// exemplar: reimportar a mesma planilha atualiza o registro em vez de
+	// criar outro.
+	SourceFile string
+	SourceLine int
+
+	CreatedAt time.Time
+}
+
+// AddBookCopy registra um exemplar de um livro. Um exemplar com o mesmo
+// SourceFile e SourceLine de outro já gravado substitui o anterior.
+func (db *Database) AddBookCopy(c *BookCopy) (*BookCopy, error) {
+	if err := insertBookCopy(db.conn, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SaveBookWithCopy grava o livro como SaveBookWithRelations e registra o
+// exemplar na mesma transação: se o exemplar não puder ser gravado, o livro
+// também não é, e a leitura pode ser repetida sem duplicar nada
+func (db *Database) SaveBookWithCopy(book *Book, authors []BookAuthor, publisher string, c *BookCopy) (*Book, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveBookTx(tx, book, authors, publisher, sourcesOrigin(book.Sources)); err != nil {
+		return nil, err
+	}
+
+	c.BookID = book.ID
+	if err := insertBookCopy(tx, c); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar livro: %w", err)
+	}
+	return book, nil
+}
+
+// insertBookCopy grava o exemplar e preenche seu ID e data de criação. Se
+// arquivo e linha de origem já existirem, atualiza aquele exemplar.
+func insertBookCopy(q querier, c *BookCopy) error {
+	if c.Quantity <= 0 {
+		c.Quantity = 1
+	}
+
+	var metadata []byte
+	if len(c.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(c.Metadata)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar metadados do exemplar: %w", err)
+		}
+	}
+
+	// Sem arquivo de origem o exemplar não tem chave e é sempre inserido
+	var sourceFile, sourceLine interface{}
+	if c.SourceFile != "" {
+		sourceFile, sourceLine = c.SourceFile, c.SourceLine
+	}
+
+	err := q.QueryRow(`
+		INSERT INTO book_copies (book_id, quantity, condition, shelf, donor, source, metadata, source_file, source_line, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source_file, source_line) WHERE source_file IS NOT NULL DO UPDATE SET
+			book_id = excluded.book_id,
+			quantity = excluded.quantity,
+			condition = excluded.condition,
+			shelf = excluded.shelf,
+			donor = excluded.donor,
+			source = excluded.source,
+			metadata = excluded.metadata
+		RETURNING id, created_at
+	`,
+		c.BookID, c.Quantity, c.Condition, c.Shelf, c.Donor, c.Source, string(metadata), sourceFile, sourceLine, time.Now(),
+	).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao criar exemplar: %w", err)
+	}
+	return nil
+}
+
+// GetBookCopies retorna os exemplares de um livro
+func (db *Database) GetBookCopies(bookID int) ([]*BookCopy, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, book_id, quantity, condition, shelf, donor, source, metadata, source_file, source_line, created_at
+		FROM book_copies
+		WHERE book_id = ?
+		ORDER BY created_at
+	`, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar exemplares: %w", err)
+	}
+	defer rows.Close()
+
+	var copies []*BookCopy
+	for rows.Next() {
+		var c BookCopy
+		var condition, shelf, donor, source, metadata, sourceFile sql.NullString
+		var sourceLine sql.NullInt64
+
+		err := rows.Scan(&c.ID, &c.BookID, &c.Quantity, &condition, &shelf, &donor, &source, &metadata, &sourceFile, &sourceLine, &c.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan do exemplar: %w", err)
+		}
+
+		c.Condition = condition.String
+		c.Shelf = shelf.String
+		c.Donor = donor.String
+		c.Source = source.String
+		c.SourceFile = sourceFile.String
+		c.SourceLine = int(sourceLine.Int64)
+		if metadata.String != "" {
+			if err := json.Unmarshal([]byte(metadata.String), &c.Metadata); err != nil {
+				return nil, fmt.Errorf("erro ao ler metadados do exemplar: %w", err)
+			}
+		}
+
+		copies = append(copies, &c)
+	}
+
+	return copies, rows.Err()
+}
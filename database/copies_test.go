@@ -0,0 +1,123 @@
+package database
+
+import "testing"
+
+func TestSaveBookWithCopy(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+
+	var book *Book
+	for i := 0; i < 2; i++ {
+		var err error
+		book, err = db.SaveBookWithCopy(
+			&Book{ISBN: "9780132350884", Title: "Clean Code"},
+			[]BookAuthor{{Name: "Robert C. Martin"}},
+			"Prentice Hall",
+			&BookCopy{Quantity: 2, Donor: "Maria", Metadata: map[string]string{"obs": "capa rasgada"}, SourceFile: "/doacoes/marco.csv", SourceLine: 7},
+		)
+		if err != nil {
+			t.Fatalf("SaveBookWithCopy (importação %d): %v", i+1, err)
+		}
+	}
+
+	copies, err := db.GetBookCopies(book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 1 || copies[0].BookID != book.ID || copies[0].Quantity != 2 || copies[0].Donor != "Maria" || copies[0].Metadata["obs"] != "capa rasgada" {
+		t.Errorf("exemplares = %+v", copies)
+	}
+}
+
+func TestSaveBookWithCopyRollsBack(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+
+	// Sem a tabela de exemplares a gravação do exemplar falha depois da do livro
+	if _, err := db.conn.Exec("DROP TABLE book_copies"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := db.SaveBookWithCopy(
+		&Book{ISBN: "9780132350884", Title: "Clean Code"},
+		[]BookAuthor{{Name: "Robert C. Martin"}},
+		"Prentice Hall",
+		&BookCopy{Quantity: 1},
+	)
+	if err == nil {
+		t.Fatal("esperado erro ao gravar o exemplar")
+	}
+
+	if n, _ := db.CountBooks(); n != 0 {
+		t.Errorf("%d livros gravados após falha no exemplar", n)
+	}
+	var authors, publishers int
+	db.conn.QueryRow("SELECT COUNT(*) FROM authors").Scan(&authors)
+	db.conn.QueryRow("SELECT COUNT(*) FROM publishers").Scan(&publishers)
+	if authors != 0 || publishers != 0 {
+		t.Errorf("%d autores e %d editoras órfãos", authors, publishers)
+	}
+}
+
+func TestAddBookCopyBySourceLine(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+
+	cleanCode, err := db.SaveBook(&Book{ISBN: "9780132350884", Title: "Clean Code"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := db.SaveBook(&Book{ISBN: "9780201633610", Title: "Design Patterns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := db.AddBookCopy(&BookCopy{BookID: cleanCode.ID, Quantity: 1, Donor: "Maria", SourceFile: "/doacoes/marco.csv", SourceLine: 2})
+	if err != nil {
+		t.Fatalf("AddBookCopy: %v", err)
+	}
+
+	// Mesma planilha importada de novo, com a quantidade corrigida
+	again, err := db.AddBookCopy(&BookCopy{BookID: cleanCode.ID, Quantity: 3, Donor: "Maria", SourceFile: "/doacoes/marco.csv", SourceLine: 2})
+	if err != nil {
+		t.Fatalf("AddBookCopy (reimportação): %v", err)
+	}
+	if again.ID != first.ID || !again.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("reimportação criou outro exemplar: %d, esperado %d", again.ID, first.ID)
+	}
+
+	// Outra linha e outra planilha são exemplares distintos; sem origem, sempre insere
+	for _, c := range []*BookCopy{
+		{BookID: cleanCode.ID, SourceFile: "/doacoes/marco.csv", SourceLine: 3},
+		{BookID: cleanCode.ID, SourceFile: "/doacoes/abril.csv", SourceLine: 2},
+		{BookID: cleanCode.ID},
+		{BookID: cleanCode.ID},
+	} {
+		if _, err := db.AddBookCopy(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copies, err := db.GetBookCopies(cleanCode.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 5 {
+		t.Fatalf("%d exemplares, esperado 5", len(copies))
+	}
+	if c := copies[0]; c.ID != first.ID || c.Quantity != 3 || c.SourceFile != "/doacoes/marco.csv" || c.SourceLine != 2 {
+		t.Errorf("exemplar reimportado = %+v", c)
+	}
+
+	// A linha corrigida para outro ISBN leva o exemplar para o outro livro
+	moved, err := db.AddBookCopy(&BookCopy{BookID: patterns.ID, SourceFile: "/doacoes/marco.csv", SourceLine: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ID != first.ID {
+		t.Errorf("exemplar movido com ID %d, esperado %d", moved.ID, first.ID)
+	}
+	if copies, _ := db.GetBookCopies(cleanCode.ID); len(copies) != 4 {
+		t.Errorf("%d exemplares de Clean Code, esperado 4", len(copies))
+	}
+	if copies, _ := db.GetBookCopies(patterns.ID); len(copies) != 1 {
+		t.Errorf("%d exemplares de Design Patterns, esperado 1", len(copies))
+	}
+}
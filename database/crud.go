@@ -0,0 +1,349 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Erros das operações de edição; use errors.Is para identificá-los
+var (
+	ErrNotFound = errors.New("não encontrado")
+	ErrConflict = errors.New("conflito")
+	ErrStale    = errors.New("registro alterado desde a última leitura")
+)
+
+// GetBookDetail obtém um livro com autores e editora pelo ISBN (nil se não existir)
+func (db *Database) GetBookDetail(isbn string) (*BookDetail, error) {
+	rows, err := db.conn.Query(bookDetailSelect+` WHERE b.isbn = ?`, isbn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar livro: %w", err)
+	}
+	defer rows.Close()
+
+	books, err := scanBookDetails(rows)
+	if err != nil || len(books) == 0 {
+		return nil, err
+	}
+
+	authors, err := db.getAuthorsForBooks([]int{books[0].ID})
+	if err != nil {
+		return nil, err
+	}
+	fillAuthors(books, authors)
+	return books[0], nil
+}
+
+// CreateBook cadastra um livro novo com sua editora e seus autores. Retorna
+// ErrConflict se o ISBN já estiver cadastrado.
+func (db *Database) CreateBook(book *Book, authors []BookAuthor, publisher string) (*Book, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM books WHERE isbn = ?", book.ISBN).Scan(&id)
+	if err == nil {
+		return nil, fmt.Errorf("%w: o ISBN %s já está cadastrado", ErrConflict, book.ISBN)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("erro ao verificar livro existente: %w", err)
+	}
+
+	if err := saveBookTx(tx, book, authors, publisher, OriginManual); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar livro: %w", err)
+	}
+	return book, nil
+}
+
+// UpdateBook substitui os dados de um livro existente, identificado pelo ISBN.
+// Se expected não for zero, a gravação só acontece se updated_at ainda for
+// igual a ele (ErrStale caso contrário); ErrNotFound se o livro não existir.
+func (db *Database) UpdateBook(book *Book, authors []BookAuthor, publisher string, expected time.Time) (*Book, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := checkVersion(tx, "books", "isbn", book.ISBN, expected); err != nil {
+		return nil, err
+	}
+
+	if err := saveBookTx(tx, book, authors, publisher, OriginManual); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar livro: %w", err)
+	}
+	return book, nil
+}
+
+// DeleteBook remove um livro, seus exemplares, sua lista de autores e seu
+// histórico. expected tem o mesmo papel que em UpdateBook.
+func (db *Database) DeleteBook(isbn string, expected time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := checkVersion(tx, "books", "isbn", isbn, expected)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM book_copies WHERE book_id = ?",
+		"DELETE FROM book_authors WHERE book_id = ?",
+		"DELETE FROM book_history WHERE book_id = ?",
+		"DELETE FROM books WHERE id = ?",
+	} {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return fmt.Errorf("erro ao remover livro: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar remoção do livro: %w", err)
+	}
+	return nil
+}
+
+// checkVersion confere, dentro da transação, se o registro existe e se
+// updated_at ainda é o esperado. Retorna o id do registro.
+func checkVersion(tx *sql.Tx, table, key string, value interface{}, expected time.Time) (int, error) {
+	var id int
+	var created time.Time
+	var updated sql.NullTime
+	err := tx.QueryRow(
+		fmt.Sprintf("SELECT id, created_at, updated_at FROM %s WHERE %s = ?", table, key),
+		value,
+	).Scan(&id, &created, &updated)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %v", ErrNotFound, value)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar registro em %s: %w", table, err)
+	}
+
+	if !expected.IsZero() && !updatedAt(created, updated).Equal(expected) {
+		return 0, ErrStale
+	}
+	return id, nil
+}
+
+// updatedAt é a data de alteração do registro, ou a de criação para linhas
+// gravadas antes da coluna existir
+func updatedAt(created time.Time, updated sql.NullTime) time.Time {
+	if updated.Valid {
+		return updated.Time
+	}
+	return created
+}
+
+// namedRecord é a forma comum de authors e publishers
+type namedRecord struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// namedTable descreve uma tabela de nomes únicos (autores ou editoras)
+type namedTable struct {
+	table string
+	label string // nome usado nas mensagens de erro
+	usage string // conta os livros que referenciam o registro
+}
+
+var (
+	authorsTable = namedTable{
+		table: "authors",
+		label: "autor",
+		usage: `SELECT count(*) FROM books WHERE author_id = ?1
+			OR id IN (SELECT book_id FROM book_authors WHERE author_id = ?1)`,
+	}
+	publishersTable = namedTable{
+		table: "publishers",
+		label: "editora",
+		usage: `SELECT count(*) FROM books WHERE publisher_id = ?1`,
+	}
+)
+
+// get busca um registro pelo id (nil se não existir)
+func (t namedTable) get(q querier, id int) (*namedRecord, error) {
+	var r namedRecord
+	var updated sql.NullTime
+	err := q.QueryRow(
+		fmt.Sprintf("SELECT id, name, created_at, updated_at FROM %s WHERE id = ?", t.table),
+		id,
+	).Scan(&r.ID, &r.Name, &r.CreatedAt, &updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar %s: %w", t.label, err)
+	}
+	r.UpdatedAt = updatedAt(r.CreatedAt, updated)
+	return &r, nil
+}
+
+// checkName rejeita nomes já usados por outro registro
+func (t namedTable) checkName(q querier, id int, name string) error {
+	var other int
+	err := q.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE name = ? AND id <> ?", t.table), name, id).Scan(&other)
+	if err == nil {
+		return fmt.Errorf("%w: o nome %q já está em uso (%s %d)", ErrConflict, name, t.label, other)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("erro ao verificar %s existente: %w", t.label, err)
+	}
+	return nil
+}
+
+// create cadastra um registro novo; ErrConflict se o nome já existir
+func (t namedTable) create(conn *sql.DB, name string) (*namedRecord, error) {
+	name = strings.TrimSpace(name)
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := t.checkName(tx, 0, name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (name, created_at, updated_at) VALUES (?, ?, ?)", t.table), name, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar %s: %w", t.label, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter ID (%s): %w", t.label, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar %s: %w", t.label, err)
+	}
+	return &namedRecord{ID: int(id), Name: name, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// rename altera o nome de um registro, com a mesma verificação de versão de UpdateBook
+func (t namedTable) rename(conn *sql.DB, id int, name string, expected time.Time) (*namedRecord, error) {
+	name = strings.TrimSpace(name)
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := checkVersion(tx, t.table, "id", id, expected); err != nil {
+		return nil, err
+	}
+	if err := t.checkName(tx, id, name); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET name = ?, updated_at = ? WHERE id = ?", t.table), name, time.Now(), id); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar %s: %w", t.label, err)
+	}
+	r, err := t.get(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar %s: %w", t.label, err)
+	}
+	return r, nil
+}
+
+// remove apaga um registro que não seja usado por nenhum livro
+func (t namedTable) remove(conn *sql.DB, id int, expected time.Time) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := checkVersion(tx, t.table, "id", id, expected); err != nil {
+		return err
+	}
+
+	var books int
+	if err := tx.QueryRow(t.usage, id).Scan(&books); err != nil {
+		return fmt.Errorf("erro ao verificar livros (%s): %w", t.label, err)
+	}
+	if books > 0 {
+		return fmt.Errorf("%w: %s %d ainda é referenciado por %d livro(s)", ErrConflict, t.label, id, books)
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.table), id); err != nil {
+		return fmt.Errorf("erro ao remover %s: %w", t.label, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar remoção (%s): %w", t.label, err)
+	}
+	return nil
+}
+
+// GetAuthor obtém um autor pelo id (nil se não existir)
+func (db *Database) GetAuthor(id int) (*Author, error) {
+	r, err := authorsTable.get(db.conn, id)
+	return (*Author)(r), err
+}
+
+// CreateAuthor cadastra um autor; ErrConflict se o nome já existir
+func (db *Database) CreateAuthor(name string) (*Author, error) {
+	r, err := authorsTable.create(db.conn, name)
+	return (*Author)(r), err
+}
+
+// UpdateAuthor renomeia um autor. expected tem o mesmo papel que em UpdateBook.
+func (db *Database) UpdateAuthor(id int, name string, expected time.Time) (*Author, error) {
+	r, err := authorsTable.rename(db.conn, id, name, expected)
+	return (*Author)(r), err
+}
+
+// DeleteAuthor remove um autor; ErrConflict se algum livro o referenciar
+func (db *Database) DeleteAuthor(id int, expected time.Time) error {
+	return authorsTable.remove(db.conn, id, expected)
+}
+
+// GetPublisher obtém uma editora pelo id (nil se não existir)
+func (db *Database) GetPublisher(id int) (*Publisher, error) {
+	r, err := publishersTable.get(db.conn, id)
+	return (*Publisher)(r), err
+}
+
+// CreatePublisher cadastra uma editora; ErrConflict se o nome já existir
+func (db *Database) CreatePublisher(name string) (*Publisher, error) {
+	r, err := publishersTable.create(db.conn, name)
+	return (*Publisher)(r), err
+}
+
+// UpdatePublisher renomeia uma editora. expected tem o mesmo papel que em UpdateBook.
+func (db *Database) UpdatePublisher(id int, name string, expected time.Time) (*Publisher, error) {
+	r, err := publishersTable.rename(db.conn, id, name, expected)
+	return (*Publisher)(r), err
+}
+
+// DeletePublisher remove uma editora; ErrConflict se algum livro a referenciar
+func (db *Database) DeletePublisher(id int, expected time.Time) error {
+	return publishersTable.remove(db.conn, id, expected)
+}
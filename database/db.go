@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -14,7 +15,7 @@ type Database struct {
 
 // NewDatabase cria uma nova conexão com o banco de dados
 func NewDatabase(filepath string) (*Database, error) {
-	conn, err := sql.Open("sqlite3", filepath)
+	conn, err := sql.Open("sqlite3", withConnParams(filepath))
 	if err != nil {
 		return nil, fmt.Errorf("erro ao abrir banco de dados: %w", err)
 	}
@@ -27,53 +28,23 @@ func NewDatabase(filepath string) (*Database, error) {
 	return &Database{conn: conn}, nil
 }
 
-// InitSchema cria as tabelas se não existirem
-func (db *Database) InitSchema() error {
-	schema := `
-	-- Tabela de autores
-	CREATE TABLE IF NOT EXISTS authors (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL UNIQUE,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Tabela de editoras
-	CREATE TABLE IF NOT EXISTS publishers (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL UNIQUE,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Tabela de livros
-	CREATE TABLE IF NOT EXISTS books (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		isbn TEXT NOT NULL UNIQUE,
-		title TEXT NOT NULL,
-		author_id INTEGER,
-		publisher_id INTEGER,
-		publish_date TEXT,
-		pages INTEGER,
-		description TEXT,
-		cover_url TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (author_id) REFERENCES authors(id),
-		FOREIGN KEY (publisher_id) REFERENCES publishers(id)
-	);
-
-	-- Índices para melhorar performance
-	CREATE INDEX IF NOT EXISTS idx_books_isbn ON books(isbn);
-	CREATE INDEX IF NOT EXISTS idx_books_author_id ON books(author_id);
-	CREATE INDEX IF NOT EXISTS idx_books_publisher_id ON books(publisher_id);
-	CREATE INDEX IF NOT EXISTS idx_authors_name ON authors(name);
-	CREATE INDEX IF NOT EXISTS idx_publishers_name ON publishers(name);
-	`
+// withConnParams acrescenta ao DSN a espera por locks e transações
+// "BEGIN IMMEDIATE", para que vários workers gravando ao mesmo tempo
+// aguardem a vez em vez de falhar com "database is locked"
+func withConnParams(dsn string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_busy_timeout=5000&_txlock=immediate"
+}
 
-	_, err := db.conn.Exec(schema)
-	if err != nil {
+// InitSchema cria ou atualiza o schema aplicando as migrações pendentes
+// (ver migrations.go)
+func (db *Database) InitSchema() error {
+	if err := db.Migrate(); err != nil {
 		return fmt.Errorf("erro ao criar schema: %w", err)
 	}
-
 	return nil
 }
 
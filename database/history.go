@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OriginManual identifica no histórico as gravações feitas por edição (API ou
+// interface web); as consultas aos provedores usam o nome dos provedores
+const OriginManual = "manual"
+
+// FieldChange é o valor de um campo antes e depois de uma gravação
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// BookChange é uma entrada do histórico de um livro: a origem da gravação e
+// os campos alterados (com os mesmos nomes usados em Sources)
+type BookChange struct {
+	ID        int                    `json:"id"`
+	BookID    int                    `json:"book_id"`
+	Origin    string                 `json:"origin"` // "manual" ou provedores (ex.: "openlibrary+googlebooks")
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// sourcesOrigin resume os provedores que forneceram os metadados de um livro
+// (ex.: "googlebooks+openlibrary")
+func sourcesOrigin(sources map[string]string) string {
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(sources))
+	for _, provider := range sources {
+		if provider != "" && !seen[provider] {
+			seen[provider] = true
+			names = append(names, provider)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "+")
+}
+
+// bookSnapshot lê os campos de um livro que entram no histórico; autores e
+// editora aparecem pelo nome. Retorna um mapa vazio se o livro não existir.
+func bookSnapshot(q querier, bookID int) (map[string]string, error) {
+	var title string
+	var publishDate, description, coverURL, publisher, authors sql.NullString
+	var pages sql.NullInt64
+	err := q.QueryRow(`
+		SELECT b.title, b.publish_date, b.pages, b.description, b.cover_url, p.name,
+			(SELECT group_concat(name, '; ') FROM (
+				SELECT a.name || CASE WHEN ba.role <> 'author' THEN ' (' || ba.role || ')' ELSE '' END AS name
+				FROM book_authors ba
+				JOIN authors a ON a.id = ba.author_id
+				WHERE ba.book_id = b.id
+				ORDER BY ba.position
+			))
+		FROM books b
+		LEFT JOIN publishers p ON p.id = b.publisher_id
+		WHERE b.id = ?
+	`, bookID).Scan(&title, &publishDate, &pages, &description, &coverURL, &publisher, &authors)
+	if err == sql.ErrNoRows {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler livro para o histórico: %w", err)
+	}
+
+	snapshot := map[string]string{
+		"title":        title,
+		"author":       authors.String,
+		"publisher":    publisher.String,
+		"publish_date": publishDate.String,
+		"description":  description.String,
+		"cover_url":    coverURL.String,
+		"pages":        "",
+	}
+	if pages.Int64 > 0 {
+		snapshot["pages"] = strconv.FormatInt(pages.Int64, 10)
+	}
+	return snapshot, nil
+}
+
+// recordBookChange grava no histórico os campos que mudaram entre os dois
+// retratos do livro; nada é gravado se nenhum campo mudou
+func recordBookChange(q querier, bookID int, origin string, before, after map[string]string) error {
+	changes := make(map[string]FieldChange)
+	for field, value := range after {
+		if before[field] != value {
+			changes[field] = FieldChange{Old: before[field], New: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar histórico: %w", err)
+	}
+	_, err = q.Exec("INSERT INTO book_history (book_id, origin, changes, created_at) VALUES (?, ?, ?, ?)",
+		bookID, origin, string(data), time.Now())
+	if err != nil {
+		return fmt.Errorf("erro ao gravar histórico do livro: %w", err)
+	}
+	return nil
+}
+
+// GetBookHistory retorna o histórico de alterações de um livro, da mais
+// recente para a mais antiga
+func (db *Database) GetBookHistory(bookID int) ([]*BookChange, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, book_id, origin, changes, created_at
+		FROM book_history
+		WHERE book_id = ?
+		ORDER BY created_at DESC, id DESC
+	`, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar histórico do livro: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]*BookChange, 0)
+	for rows.Next() {
+		c := &BookChange{}
+		var changes string
+		if err := rows.Scan(&c.ID, &c.BookID, &c.Origin, &changes, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler histórico do livro: %w", err)
+		}
+		if err := json.Unmarshal([]byte(changes), &c.Changes); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar histórico do livro: %w", err)
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,263 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tamanho de página padrão e máximo das listagens
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Campos de ordenação de ListBooks
+const (
+	SortCreatedAt   = "created_at"
+	SortTitle       = "title"
+	SortAuthor      = "author"
+	SortPublishDate = "publish_date"
+	SortPages       = "pages"
+)
+
+// sortColumn é a expressão SQL de um campo de ordenação. missing, quando
+// preenchida, identifica valores ausentes, que ficam no fim em qualquer direção.
+type sortColumn struct {
+	expr    string
+	missing string
+}
+
+var sortColumns = map[string]sortColumn{
+	SortCreatedAt:   {expr: "b.created_at"},
+	SortTitle:       {expr: "b.title COLLATE NOCASE"},
+	SortAuthor:      {expr: "a.name COLLATE NOCASE", missing: "a.name IS NULL"},
+	SortPublishDate: {expr: publishYearExpr, missing: publishYearExpr + " IS NULL"},
+	SortPages:       {expr: "b.pages", missing: "COALESCE(b.pages, 0) = 0"},
+}
+
+// missingConditions indica, para cada campo de metadados, quando ele está ausente
+var missingConditions = map[string]string{
+	"title":        "COALESCE(b.title, '') = ''",
+	"author":       "b.author_id IS NULL",
+	"publisher":    "b.publisher_id IS NULL",
+	"publish_date": "COALESCE(b.publish_date, '') = ''",
+	"pages":        "COALESCE(b.pages, 0) = 0",
+	"description":  "COALESCE(b.description, '') = ''",
+	"cover_url":    "COALESCE(b.cover_url, '') = ''",
+}
+
+// missingOrder é a ordem em que "any" expande os campos de missingConditions
+var missingOrder = []string{"title", "author", "publisher", "publish_date", "pages", "description", "cover_url"}
+
+// publishYearExpr extrai o ano de publish_date, que vem das APIs em formatos
+// como "2008", "2008-08-01" ou "August 2008"; NULL se não houver ano
+const publishYearExpr = `(CASE
+	WHEN b.publish_date GLOB '[0-9][0-9][0-9][0-9]*' THEN CAST(substr(b.publish_date, 1, 4) AS INTEGER)
+	WHEN b.publish_date GLOB '*[0-9][0-9][0-9][0-9]' THEN CAST(substr(b.publish_date, -4) AS INTEGER)
+END)`
+
+// BookFilters restringe uma listagem de livros. Campos vazios não filtram;
+// autor e editora aceitam parte do nome, sem diferenciar maiúsculas.
+type BookFilters struct {
+	Author    string
+	Publisher string
+	YearFrom  int      // ano de publicação mínimo
+	YearTo    int      // ano de publicação máximo
+	Missing   []string // livros sem algum destes campos (ver ParseMissingFields)
+}
+
+// ParseMissingFields converte uma lista separada por vírgulas ("pages,cover_url")
+// nos campos de metadados ausentes a filtrar; "any" seleciona todos
+func ParseMissingFields(value string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "":
+		case name == "any":
+			return append([]string(nil), missingOrder...), nil
+		case missingConditions[name] != "":
+			fields = append(fields, name)
+		default:
+			return nil, fmt.Errorf("campo de metadados desconhecido: %s", name)
+		}
+	}
+	return fields, nil
+}
+
+// BookSort define a ordenação de ListBooks
+type BookSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseBookSort interpreta "campo" (crescente) ou "-campo" (decrescente);
+// vazio equivale a "-created_at", os cadastrados mais recentemente primeiro
+func ParseBookSort(value string) (BookSort, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return BookSort{Field: SortCreatedAt, Desc: true}, nil
+	}
+
+	sort := BookSort{Field: strings.ToLower(strings.TrimPrefix(value, "-")), Desc: strings.HasPrefix(value, "-")}
+	if _, ok := sortColumns[sort.Field]; !ok {
+		return sort, fmt.Errorf("ordenação desconhecida: %s", sort.Field)
+	}
+	return sort, nil
+}
+
+// String retorna a ordenação no formato aceito por ParseBookSort
+func (s BookSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// orderBy monta a cláusula ORDER BY, com o id como desempate para que as
+// páginas sejam estáveis
+func (s BookSort) orderBy() string {
+	column, ok := sortColumns[s.Field]
+	if !ok {
+		s = BookSort{Field: SortCreatedAt, Desc: true}
+		column = sortColumns[SortCreatedAt]
+	}
+
+	dir := " ASC"
+	if s.Desc {
+		dir = " DESC"
+	}
+
+	order := column.expr + dir + ", b.id" + dir
+	if column.missing != "" {
+		order = column.missing + ", " + order
+	}
+	return order
+}
+
+// Page seleciona uma página de resultados (Number começa em 1)
+type Page struct {
+	Number int
+	Size   int
+}
+
+// normalize aplica os valores padrão e o tamanho máximo da página
+func (p Page) normalize() Page {
+	if p.Number < 1 {
+		p.Number = 1
+	}
+	if p.Size < 1 {
+		p.Size = DefaultPageSize
+	}
+	if p.Size > MaxPageSize {
+		p.Size = MaxPageSize
+	}
+	return p
+}
+
+func (p Page) offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// BookList é uma página de ListBooks
+type BookList struct {
+	Books    []*BookDetail `json:"books"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// filterClauses monta as condições SQL de BookFilters sobre books b e publishers p
+func filterClauses(filters BookFilters) ([]string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	if author := strings.TrimSpace(filters.Author); author != "" {
+		clauses = append(clauses, `EXISTS (
+			SELECT 1 FROM book_authors ba JOIN authors au ON au.id = ba.author_id
+			WHERE ba.book_id = b.id AND au.name LIKE ? ESCAPE '\')`)
+		args = append(args, containsPattern(author))
+	}
+	if publisher := strings.TrimSpace(filters.Publisher); publisher != "" {
+		clauses = append(clauses, `p.name LIKE ? ESCAPE '\'`)
+		args = append(args, containsPattern(publisher))
+	}
+	if filters.YearFrom > 0 {
+		clauses = append(clauses, publishYearExpr+` >= ?`)
+		args = append(args, filters.YearFrom)
+	}
+	if filters.YearTo > 0 {
+		clauses = append(clauses, publishYearExpr+` <= ?`)
+		args = append(args, filters.YearTo)
+	}
+
+	var missing []string
+	for _, field := range filters.Missing {
+		if cond, ok := missingConditions[field]; ok {
+			missing = append(missing, cond)
+		}
+	}
+	if len(missing) > 0 {
+		clauses = append(clauses, "("+strings.Join(missing, " OR ")+")")
+	}
+	return clauses, args
+}
+
+// likeEscaper protege os curingas do LIKE para que "%" e "_" digitados no
+// filtro sejam procurados literalmente
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern monta o padrão LIKE ... ESCAPE '\' que encontra o texto em
+// qualquer posição
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+// ListBooks retorna uma página de livros filtrada e ordenada, junto com o
+// total de livros que atendem aos filtros
+func (db *Database) ListBooks(filters BookFilters, sort BookSort, page Page) (*BookList, error) {
+	page = page.normalize()
+	list := &BookList{
+		Books:    []*BookDetail{},
+		Page:     page.Number,
+		PageSize: page.Size,
+	}
+
+	where := ""
+	clauses, args := filterClauses(filters)
+	if len(clauses) > 0 {
+		where = ` WHERE ` + strings.Join(clauses, " AND ")
+	}
+
+	count := `SELECT count(*) FROM books b LEFT JOIN publishers p ON b.publisher_id = p.id` + where
+	if err := db.conn.QueryRow(count, args...).Scan(&list.Total); err != nil {
+		return nil, fmt.Errorf("erro ao contar livros: %w", err)
+	}
+	if list.Total <= page.offset() {
+		return list, nil
+	}
+
+	query := bookDetailSelect + where + ` ORDER BY ` + sort.orderBy() + ` LIMIT ? OFFSET ?`
+	rows, err := db.conn.Query(query, append(args, page.Size, page.offset())...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar livros com detalhes: %w", err)
+	}
+	defer rows.Close()
+
+	books, err := scanBookDetails(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(books))
+	for i, d := range books {
+		ids[i] = d.ID
+	}
+	authors, err := db.getAuthorsForBooks(ids)
+	if err != nil {
+		return nil, err
+	}
+	fillAuthors(books, authors)
+
+	list.Books = books
+	return list, nil
+}
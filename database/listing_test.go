@@ -0,0 +1,56 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestListBooksFiltersMatchWildcardsLiterally(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+
+	books := []struct {
+		isbn, title, author, publisher string
+	}{
+		{"9780132350884", "Clean Code", "Robert C. Martin", "100% Livros"},
+		{"9780201633610", "Design Patterns", "Erich Gamma", "1000 Livros"},
+		{"9780596007126", "Head First Design Patterns", "Eric_Freeman", "Editora_X"},
+		{"9780134685991", "Effective Java", "EricXFreeman", "EditoraYX"},
+		{"9780262033848", "Introduction to Algorithms", `T. H. Cormen\Leiserson`, `C:\Livros`},
+	}
+	for _, b := range books {
+		_, err := db.SaveBookWithRelations(&Book{ISBN: b.isbn, Title: b.title}, []BookAuthor{{Name: b.author}}, b.publisher)
+		if err != nil {
+			t.Fatalf("SaveBookWithRelations(%s): %v", b.isbn, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters BookFilters
+		want    []string
+	}{
+		{"porcentagem na editora", BookFilters{Publisher: "0%"}, []string{"9780132350884"}},
+		{"sublinhado na editora", BookFilters{Publisher: "a_X"}, []string{"9780596007126"}},
+		{"sublinhado no autor", BookFilters{Author: "c_f"}, []string{"9780596007126"}},
+		{"barra invertida", BookFilters{Publisher: `:\l`}, []string{"9780262033848"}},
+		{"barra invertida no autor", BookFilters{Author: `n\L`}, []string{"9780262033848"}},
+		{"sem curingas continua por substring", BookFilters{Publisher: "livros"}, []string{"9780132350884", "9780201633610", "9780262033848"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := db.ListBooks(tt.filters, BookSort{}, Page{})
+			if err != nil {
+				t.Fatalf("ListBooks: %v", err)
+			}
+			var got []string
+			for _, b := range list.Books {
+				got = append(got, b.ISBN)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || list.Total != len(tt.want) {
+				t.Errorf("ISBNs = %v (total %d), esperado %v", got, list.Total, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository é um BookRepository em memória, com as mesmas regras do
+// SQLite (ISBN único, autores e editoras sem duplicatas), para testes e
+// execuções que não devem gravar em disco
+type MemoryRepository struct {
+	authors     map[int]*Author
+	publishers  map[int]*Publisher
+	books       map[int]*Book
+	bookAuthors map[int][]BookAuthor
+	copies      map[int][]*BookCopy
+	nextID      map[string]int
+	mu          sync.Mutex
+}
+
+var _ BookRepository = (*MemoryRepository)(nil)
+
+// NewMemoryRepository cria um repositório em memória vazio
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		authors:     make(map[int]*Author),
+		publishers:  make(map[int]*Publisher),
+		books:       make(map[int]*Book),
+		bookAuthors: make(map[int][]BookAuthor),
+		copies:      make(map[int][]*BookCopy),
+		nextID:      make(map[string]int),
+	}
+}
+
+// id retorna o próximo ID da "tabela" informada
+func (m *MemoryRepository) id(table string) int {
+	m.nextID[table]++
+	return m.nextID[table]
+}
+
+// GetOrCreateAuthor obtém um autor existente ou cria um novo
+func (m *MemoryRepository) GetOrCreateAuthor(name string) (*Author, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.getOrCreateAuthor(name), nil
+}
+
+func (m *MemoryRepository) getOrCreateAuthor(name string) *Author {
+	for _, a := range m.authors {
+		if a.Name == name {
+			author := *a
+			return &author
+		}
+	}
+
+	now := time.Now()
+	a := &Author{ID: m.id("authors"), Name: name, CreatedAt: now, UpdatedAt: now}
+	m.authors[a.ID] = a
+	author := *a
+	return &author
+}
+
+// GetOrCreatePublisher obtém uma editora existente ou cria uma nova
+func (m *MemoryRepository) GetOrCreatePublisher(name string) (*Publisher, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.getOrCreatePublisher(name), nil
+}
+
+func (m *MemoryRepository) getOrCreatePublisher(name string) *Publisher {
+	for _, p := range m.publishers {
+		if p.Name == name {
+			publisher := *p
+			return &publisher
+		}
+	}
+
+	now := time.Now()
+	p := &Publisher{ID: m.id("publishers"), Name: name, CreatedAt: now, UpdatedAt: now}
+	m.publishers[p.ID] = p
+	publisher := *p
+	return &publisher
+}
+
+// SaveBook salva um livro (cria ou atualiza pelo ISBN)
+func (m *MemoryRepository) SaveBook(book *Book) (*Book, error) {
+	if book.ISBN == "" {
+		return nil, fmt.Errorf("erro ao criar livro: ISBN vazio")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saveBook(book), nil
+}
+
+func (m *MemoryRepository) saveBook(book *Book) *Book {
+	now := time.Now()
+	if existing := m.findByISBN(book.ISBN); existing != nil {
+		book.ID = existing.ID
+		book.CreatedAt = existing.CreatedAt
+	} else {
+		book.ID = m.id("books")
+		book.CreatedAt = now
+	}
+	book.UpdatedAt = now
+
+	stored := *book
+	m.books[book.ID] = &stored
+	return book
+}
+
+// SaveBookWithRelations grava o livro, sua editora e seus autores de uma vez;
+// nada é alterado se a lista de autores for inválida
+func (m *MemoryRepository) SaveBookWithRelations(book *Book, authors []BookAuthor, publisher string) (*Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.saveBookWithRelations(book, authors, publisher); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// SaveBookWithCopy grava o livro como SaveBookWithRelations e registra o
+// exemplar na mesma operação
+func (m *MemoryRepository) SaveBookWithCopy(book *Book, authors []BookAuthor, publisher string, c *BookCopy) (*Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.saveBookWithRelations(book, authors, publisher); err != nil {
+		return nil, err
+	}
+	c.BookID = book.ID
+	m.addBookCopy(c)
+	return book, nil
+}
+
+func (m *MemoryRepository) saveBookWithRelations(book *Book, authors []BookAuthor, publisher string) error {
+	if book.ISBN == "" {
+		return fmt.Errorf("erro ao criar livro: ISBN vazio")
+	}
+	if err := validateRoles(authors); err != nil {
+		return err
+	}
+
+	book.PublisherID = nil
+	if publisher = strings.TrimSpace(publisher); publisher != "" {
+		id := m.getOrCreatePublisher(publisher).ID
+		book.PublisherID = &id
+	}
+
+	resolved := m.resolveAuthors(authors)
+	book.AuthorID = nil
+	if len(resolved) > 0 {
+		id := resolved[0].AuthorID
+		book.AuthorID = &id
+	}
+
+	m.saveBook(book)
+	m.bookAuthors[book.ID] = resolved
+	return nil
+}
+
+func (m *MemoryRepository) findByISBN(isbn string) *Book {
+	for _, b := range m.books {
+		if b.ISBN == isbn {
+			return b
+		}
+	}
+	return nil
+}
+
+// GetBookByISBN obtém um livro pelo ISBN (nil se não existir)
+func (m *MemoryRepository) GetBookByISBN(isbn string) (*Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.findByISBN(isbn)
+	if b == nil {
+		return nil, nil
+	}
+	book := *b
+	return &book, nil
+}
+
+// CountBooks retorna o número total de livros
+func (m *MemoryRepository) CountBooks() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.books), nil
+}
+
+// SetBookAuthors substitui a lista de autores de um livro
+func (m *MemoryRepository) SetBookAuthors(bookID int, authors []BookAuthor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	book, ok := m.books[bookID]
+	if !ok {
+		return fmt.Errorf("erro ao gravar autor do livro: livro %d não encontrado", bookID)
+	}
+
+	if err := validateRoles(authors); err != nil {
+		return err
+	}
+	resolved := m.resolveAuthors(authors)
+
+	m.bookAuthors[bookID] = resolved
+	book.AuthorID = nil
+	if len(resolved) > 0 {
+		id := resolved[0].AuthorID
+		book.AuthorID = &id
+	}
+	return nil
+}
+
+// validateRoles rejeita papéis desconhecidos antes de qualquer gravação
+func validateRoles(authors []BookAuthor) error {
+	for _, a := range authors {
+		role := strings.ToLower(strings.TrimSpace(a.Role))
+		if role != "" && !validRoles[role] {
+			return fmt.Errorf("papel de autor inválido: %s", role)
+		}
+	}
+	return nil
+}
+
+// resolveAuthors obtém ou cria os autores informados pelo nome e remove
+// duplicatas, como a chave primária de book_authors faz no SQLite
+func (m *MemoryRepository) resolveAuthors(authors []BookAuthor) []BookAuthor {
+	resolved := make([]BookAuthor, 0, len(authors))
+	seen := make(map[string]bool)
+	for _, a := range authors {
+		a.Role = strings.ToLower(strings.TrimSpace(a.Role))
+		if a.Role == "" {
+			a.Role = RoleAuthor
+		}
+
+		if a.AuthorID == 0 {
+			name := strings.TrimSpace(a.Name)
+			if name == "" {
+				continue
+			}
+			a.AuthorID = m.getOrCreateAuthor(name).ID
+		}
+		if author, ok := m.authors[a.AuthorID]; ok {
+			a.Name = author.Name
+		}
+
+		// Mesma chave primária de book_authors: (livro, autor, papel)
+		key := fmt.Sprintf("%d/%s", a.AuthorID, a.Role)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		a.Position = len(resolved)
+		resolved = append(resolved, a)
+	}
+	return resolved
+}
+
+// GetBookAuthors retorna os autores de um livro na ordem de crédito
+func (m *MemoryRepository) GetBookAuthors(bookID int) ([]BookAuthor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]BookAuthor(nil), m.bookAuthors[bookID]...), nil
+}
+
+// AddBookCopy registra um exemplar de um livro
+func (m *MemoryRepository) AddBookCopy(c *BookCopy) (*BookCopy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.books[c.BookID]; !ok {
+		return nil, fmt.Errorf("erro ao criar exemplar: livro %d não encontrado", c.BookID)
+	}
+	m.addBookCopy(c)
+	return c, nil
+}
+
+func (m *MemoryRepository) addBookCopy(c *BookCopy) {
+	if c.Quantity <= 0 {
+		c.Quantity = 1
+	}
+	stored := *c
+
+	// Mesma chave do índice único do SQLite: arquivo e linha de origem
+	if c.SourceFile != "" {
+		for bookID, copies := range m.copies {
+			for i, existing := range copies {
+				if existing.SourceFile != c.SourceFile || existing.SourceLine != c.SourceLine {
+					continue
+				}
+				c.ID, c.CreatedAt = existing.ID, existing.CreatedAt
+				stored.ID, stored.CreatedAt = existing.ID, existing.CreatedAt
+				if bookID == c.BookID {
+					copies[i] = &stored
+					return
+				}
+				m.copies[bookID] = append(copies[:i:i], copies[i+1:]...)
+				m.copies[c.BookID] = append(m.copies[c.BookID], &stored)
+				return
+			}
+		}
+	}
+
+	c.ID = m.id("book_copies")
+	c.CreatedAt = time.Now()
+	stored.ID, stored.CreatedAt = c.ID, c.CreatedAt
+	m.copies[c.BookID] = append(m.copies[c.BookID], &stored)
+}
+
+// GetBookCopies retorna os exemplares de um livro
+func (m *MemoryRepository) GetBookCopies(bookID int) ([]*BookCopy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copies := make([]*BookCopy, len(m.copies[bookID]))
+	for i, c := range m.copies[bookID] {
+		stored := *c
+		copies[i] = &stored
+	}
+	return copies, nil
+}
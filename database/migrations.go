@@ -0,0 +1,523 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// Migration é uma alteração versionada do schema. Up e Down rodam dentro de
+// uma transação junto com o registro em schema_migrations.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+	Down    func(tx *sql.Tx) error
+}
+
+// MigrationStatus descreve se uma migração já foi aplicada ao banco
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// migrations lista as migrações em ordem de versão. Migrações já publicadas
+// não devem ser alteradas: mudanças no schema entram como novas versões.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "schema inicial",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS authors (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS publishers (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS books (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				isbn TEXT NOT NULL UNIQUE,
+				title TEXT NOT NULL,
+				author_id INTEGER,
+				publisher_id INTEGER,
+				publish_date TEXT,
+				pages INTEGER,
+				description TEXT,
+				cover_url TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (author_id) REFERENCES authors(id),
+				FOREIGN KEY (publisher_id) REFERENCES publishers(id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_books_isbn ON books(isbn);
+			CREATE INDEX IF NOT EXISTS idx_books_author_id ON books(author_id);
+			CREATE INDEX IF NOT EXISTS idx_books_publisher_id ON books(publisher_id);
+			CREATE INDEX IF NOT EXISTS idx_authors_name ON authors(name);
+			CREATE INDEX IF NOT EXISTS idx_publishers_name ON publishers(name);
+		`),
+		Down: execSQL(`
+			DROP TABLE IF EXISTS books;
+			DROP TABLE IF EXISTS publishers;
+			DROP TABLE IF EXISTS authors;
+		`),
+	},
+	{
+		Version: 2,
+		Name:    "proveniência dos metadados",
+		// Bancos anteriores ao controle de versões podem já ter a coluna
+		Up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "books", "metadata_sources", "TEXT")
+		},
+		Down: execSQL(`ALTER TABLE books DROP COLUMN metadata_sources;`),
+	},
+	{
+		Version: 3,
+		Name:    "exemplares",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS book_copies (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				book_id INTEGER NOT NULL,
+				quantity INTEGER NOT NULL DEFAULT 1,
+				condition TEXT,
+				shelf TEXT,
+				donor TEXT,
+				source TEXT,
+				metadata TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (book_id) REFERENCES books(id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_book_copies_book_id ON book_copies(book_id);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS book_copies;`),
+	},
+	{
+		Version: 4,
+		Name:    "autores por livro",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS book_authors (
+				book_id INTEGER NOT NULL,
+				author_id INTEGER NOT NULL,
+				position INTEGER NOT NULL DEFAULT 0,
+				role TEXT NOT NULL DEFAULT 'author',
+				PRIMARY KEY (book_id, author_id, role),
+				FOREIGN KEY (book_id) REFERENCES books(id),
+				FOREIGN KEY (author_id) REFERENCES authors(id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_book_authors_author_id ON book_authors(author_id);
+
+			-- Livros existentes têm apenas books.author_id
+			INSERT OR IGNORE INTO book_authors (book_id, author_id, position, role)
+			SELECT id, author_id, 0, 'author' FROM books
+			WHERE author_id IS NOT NULL
+			  AND id NOT IN (SELECT book_id FROM book_authors);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS book_authors;`),
+	},
+	{
+		Version: 5,
+		Name:    "índice de busca textual",
+		Up:      createSearchIndex,
+		Down: execSQL(`
+			DROP TRIGGER IF EXISTS books_fts_insert;
+			DROP TRIGGER IF EXISTS books_fts_update;
+			DROP TRIGGER IF EXISTS books_fts_delete;
+			DROP TRIGGER IF EXISTS book_authors_fts_insert;
+			DROP TRIGGER IF EXISTS book_authors_fts_delete;
+			DROP TRIGGER IF EXISTS authors_fts_update;
+			DROP TRIGGER IF EXISTS publishers_fts_update;
+			DROP TABLE IF EXISTS books_fts;
+		`),
+	},
+	{
+		Version: 6,
+		Name:    "data de alteração de autores e editoras",
+		// ADD COLUMN não aceita DEFAULT CURRENT_TIMESTAMP; linhas sem valor
+		// usam created_at
+		Up: func(tx *sql.Tx) error {
+			for _, table := range []string{"authors", "publishers"} {
+				if err := addColumnIfMissing(tx, table, "updated_at", "DATETIME"); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET updated_at = created_at WHERE updated_at IS NULL", table)); err != nil {
+					return fmt.Errorf("erro ao preencher %s.updated_at: %w", table, err)
+				}
+			}
+			return nil
+		},
+		Down: execSQL(`
+			ALTER TABLE authors DROP COLUMN updated_at;
+			ALTER TABLE publishers DROP COLUMN updated_at;
+		`),
+	},
+	{
+		Version: 7,
+		Name:    "histórico de livros",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS book_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				book_id INTEGER NOT NULL,
+				origin TEXT NOT NULL,
+				changes TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (book_id) REFERENCES books(id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_book_history_book_id ON book_history(book_id);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS book_history;`),
+	},
+	{
+		Version: 8,
+		Name:    "ISBN-13 canônico",
+		Up:      normalizeBookISBNs,
+		// Os ISBNs originais não são guardados; reverter apenas desregistra a versão
+		Down: func(tx *sql.Tx) error { return nil },
+	},
+	{
+		Version: 9,
+		Name:    "exemplares por linha importada",
+		// Exemplares já gravados ficam sem chave: não há como saber de qual
+		// arquivo vieram
+		Up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "book_copies", "source_file", "TEXT"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "book_copies", "source_line", "INTEGER"); err != nil {
+				return err
+			}
+			return execSQL(`
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_book_copies_source_line
+				ON book_copies(source_file, source_line) WHERE source_file IS NOT NULL;
+			`)(tx)
+		},
+		Down: execSQL(`
+			DROP INDEX IF EXISTS idx_book_copies_source_line;
+			ALTER TABLE book_copies DROP COLUMN source_line;
+			ALTER TABLE book_copies DROP COLUMN source_file;
+		`),
+	},
+}
+
+// Migrations retorna as migrações conhecidas, em ordem de versão
+func Migrations() []Migration {
+	return migrations
+}
+
+// LatestVersion retorna a versão mais recente do schema
+func LatestVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// execSQL cria uma etapa de migração a partir de comandos SQL
+func execSQL(statements string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statements)
+		return err
+	}
+}
+
+// ensureMigrationsTable cria a tabela de controle de versões
+func (db *Database) ensureMigrationsTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations retorna as versões aplicadas e quando foram aplicadas
+func (db *Database) appliedMigrations() (map[int]time.Time, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// SchemaVersion retorna a maior versão aplicada (0 para banco vazio)
+func (db *Database) SchemaVersion() (int, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// MigrationStatus retorna o estado de cada migração conhecida
+func (db *Database) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		status[i] = MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		}
+	}
+	return status, nil
+}
+
+// Migrate aplica todas as migrações pendentes
+func (db *Database) Migrate() error {
+	return db.MigrateTo(LatestVersion())
+}
+
+// MigrateTo leva o schema à versão informada, aplicando (up) ou revertendo
+// (down) migrações uma a uma, cada uma em sua própria transação
+func (db *Database) MigrateTo(target int) error {
+	if target < 0 || target > LatestVersion() {
+		return fmt.Errorf("versão de schema inválida: %d (última: %d)", target, LatestVersion())
+	}
+
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok && m.Version <= target {
+			if err := db.apply(m, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; ok && m.Version > target {
+			if err := db.apply(m, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rollback reverte as últimas migrações aplicadas
+func (db *Database) Rollback(steps int) error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	target := version
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		if migrations[i].Version <= target {
+			target = migrations[i].Version - 1
+			steps--
+		}
+	}
+	if target < 0 {
+		target = 0
+	}
+	return db.MigrateTo(target)
+}
+
+// apply executa uma migração e atualiza schema_migrations na mesma transação
+func (db *Database) apply(m Migration, up bool) error {
+	direction := "aplicar"
+	step := m.Up
+	if !up {
+		direction = "reverter"
+		step = m.Down
+	}
+
+	if step == nil {
+		return fmt.Errorf("migração %d (%s) não pode ser revertida", m.Version, m.Name)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := step(tx); err != nil {
+		return fmt.Errorf("erro ao %s migração %d (%s): %w", direction, m.Version, m.Name, err)
+	}
+
+	if up {
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version, m.Name, time.Now())
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao registrar migração %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar migração %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// addColumnIfMissing adiciona uma coluna a uma tabela existente, se necessário
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+
+	exists := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("erro ao adicionar coluna %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// normalizeBookISBNs converte para ISBN-13 os ISBNs gravados antes da
+// normalização (ISBN-10, com hífens ou espaços). Se o ISBN-13 já existir, os
+// dois registros são unidos em um só.
+func normalizeBookISBNs(tx *sql.Tx) error {
+	type stored struct {
+		id   int
+		isbn string
+	}
+
+	rows, err := tx.Query("SELECT id, isbn FROM books ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("erro ao ler ISBNs: %w", err)
+	}
+	var books []stored
+	for rows.Next() {
+		var b stored
+		if err := rows.Scan(&b.id, &b.isbn); err != nil {
+			rows.Close()
+			return fmt.Errorf("erro ao ler ISBNs: %w", err)
+		}
+		books = append(books, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler ISBNs: %w", err)
+	}
+
+	for _, b := range books {
+		// Valores que não são ISBN válido ficam como estão
+		code, err := isbn.Normalize(b.isbn)
+		if err != nil || code == b.isbn {
+			continue
+		}
+
+		var target int
+		err = tx.QueryRow("SELECT id FROM books WHERE isbn = ?", code).Scan(&target)
+		switch {
+		case err == sql.ErrNoRows:
+			if _, err := tx.Exec("UPDATE books SET isbn = ? WHERE id = ?", code, b.id); err != nil {
+				return fmt.Errorf("erro ao normalizar ISBN %s: %w", b.isbn, err)
+			}
+		case err != nil:
+			return fmt.Errorf("erro ao buscar ISBN %s: %w", code, err)
+		default:
+			if err := mergeBooks(tx, b.id, target); err != nil {
+				return fmt.Errorf("erro ao unir ISBN %s a %s: %w", b.isbn, code, err)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeBooks une o livro from ao livro into: campos vazios de into são
+// preenchidos com os de from, exemplares e histórico passam para into e from
+// é removido. Os autores de from só são usados se into não tiver nenhum.
+func mergeBooks(tx *sql.Tx, from, into int) error {
+	statements := []string{
+		`UPDATE books SET
+			title = COALESCE(NULLIF(title, ''), (SELECT title FROM books WHERE id = :from)),
+			author_id = COALESCE(author_id, (SELECT author_id FROM books WHERE id = :from)),
+			publisher_id = COALESCE(publisher_id, (SELECT publisher_id FROM books WHERE id = :from)),
+			publish_date = COALESCE(NULLIF(publish_date, ''), (SELECT publish_date FROM books WHERE id = :from)),
+			pages = COALESCE(NULLIF(pages, 0), (SELECT pages FROM books WHERE id = :from)),
+			description = COALESCE(NULLIF(description, ''), (SELECT description FROM books WHERE id = :from)),
+			cover_url = COALESCE(NULLIF(cover_url, ''), (SELECT cover_url FROM books WHERE id = :from)),
+			metadata_sources = COALESCE(metadata_sources, (SELECT metadata_sources FROM books WHERE id = :from))
+		WHERE id = :into`,
+		`INSERT OR IGNORE INTO book_authors (book_id, author_id, position, role)
+		SELECT :into, author_id, position, role FROM book_authors
+		WHERE book_id = :from AND NOT EXISTS (SELECT 1 FROM book_authors WHERE book_id = :into)`,
+		`DELETE FROM book_authors WHERE book_id = :from`,
+		`UPDATE book_copies SET book_id = :into WHERE book_id = :from`,
+		`UPDATE book_history SET book_id = :into WHERE book_id = :from`,
+		`DELETE FROM books WHERE id = :from`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, sql.Named("from", from), sql.Named("into", into)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestDatabase abre um banco SQLite em um diretório temporário na versão informada
+func newTestDatabase(t *testing.T, version int) *Database {
+	t.Helper()
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "books.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.MigrateTo(version); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestNormalizeBookISBNs(t *testing.T) {
+	db := newTestDatabase(t, 7)
+
+	_, err := db.conn.Exec(`
+		INSERT INTO authors (id, name) VALUES (1, 'Eric Freeman');
+		INSERT INTO books (id, isbn, title, author_id, publish_date, pages, description, cover_url) VALUES
+			(1, '0596007124', 'Head First Design Patterns', 1, '2004', 694, 'Padrões de projeto', ''),
+			(2, '9780596007126', 'Head First Design Patterns (2ª leitura)', NULL, '', 0, '', ''),
+			(3, '978-0201633610', 'Design Patterns', NULL, '', 0, '', ''),
+			(4, '0132350882', 'Clean Code', NULL, '', 0, '', ''),
+			(5, 'sem-isbn', 'Inválido', NULL, '', 0, '', '');
+		INSERT INTO book_authors (book_id, author_id, position, role) VALUES (1, 1, 0, 'author');
+		INSERT INTO book_copies (book_id, quantity) VALUES (1, 2);
+		INSERT INTO book_history (book_id, origin, changes) VALUES (1, 'manual', '{}');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.MigrateTo(8); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]string{2: "9780596007126", 3: "9780201633610", 4: "9780132350884", 5: "sem-isbn"}
+	rows, err := db.conn.Query("SELECT id, isbn FROM books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var code string
+		if err := rows.Scan(&id, &code); err != nil {
+			t.Fatal(err)
+		}
+		got[id] = code
+	}
+	rows.Close()
+	if len(got) != len(want) {
+		t.Fatalf("livros após a migração = %v, esperado %v", got, want)
+	}
+	for id, code := range want {
+		if got[id] != code {
+			t.Errorf("livro %d: ISBN %q, esperado %q", id, got[id], code)
+		}
+	}
+
+	// O registro ISBN-10 foi unido ao ISBN-13 já existente
+	book, err := db.GetBookDetail("9780596007126")
+	if err != nil || book == nil {
+		t.Fatalf("GetBookDetail: %v, %v", book, err)
+	}
+	if book.Title != "Head First Design Patterns (2ª leitura)" {
+		t.Errorf("título %q: o registro existente deve prevalecer", book.Title)
+	}
+	if book.Description != "Padrões de projeto" || book.Pages != 694 {
+		t.Errorf("descrição %q, páginas %d: campos vazios devem vir do registro unido", book.Description, book.Pages)
+	}
+	if len(book.Authors) != 1 || book.Authors[0].Name != "Eric Freeman" {
+		t.Errorf("autores = %+v, esperado Eric Freeman", book.Authors)
+	}
+
+	var copies, history int
+	db.conn.QueryRow("SELECT COUNT(*) FROM book_copies WHERE book_id = 2").Scan(&copies)
+	db.conn.QueryRow("SELECT COUNT(*) FROM book_history WHERE book_id = 2").Scan(&history)
+	if copies != 1 || history != 1 {
+		t.Errorf("exemplares = %d, histórico = %d; esperado 1 e 1", copies, history)
+	}
+}
+
+// schemaObjects lista o que cada versão acrescenta ao schema: tabelas,
+// índices e gatilhos pelo nome, colunas como "tabela.coluna"
+var schemaObjects = []struct {
+	version int
+	objects []string
+	columns []string
+}{
+	{1, []string{"authors", "publishers", "books", "idx_books_isbn"}, nil},
+	{2, nil, []string{"books.metadata_sources"}},
+	{3, []string{"book_copies", "idx_book_copies_book_id"}, nil},
+	{4, []string{"book_authors", "idx_book_authors_author_id"}, nil},
+	{5, []string{"books_fts", "books_fts_insert", "authors_fts_update"}, nil},
+	{6, nil, []string{"authors.updated_at", "publishers.updated_at"}},
+	{7, []string{"book_history"}, nil},
+	{9, []string{"idx_book_copies_source_line"}, []string{"book_copies.source_file", "book_copies.source_line"}},
+}
+
+// hasColumn indica se a tabela existe e tem a coluna
+func hasColumn(t *testing.T, db *Database, table, column string) bool {
+	t.Helper()
+	var n int
+	err := db.conn.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+// assertSchema verifica a versão registrada e que o schema tem exatamente os
+// objetos das migrações até ela
+func assertSchema(t *testing.T, db *Database, version int) {
+	t.Helper()
+
+	got, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != version {
+		t.Fatalf("versão = %d, esperado %d", got, version)
+	}
+
+	for _, s := range schemaObjects {
+		want := s.version <= version
+		for _, name := range s.objects {
+			var n int
+			if err := db.conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = ?", name).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			if (n > 0) != want {
+				t.Errorf("versão %d: %s existe = %v, esperado %v", version, name, n > 0, want)
+			}
+		}
+		for _, column := range s.columns {
+			table, name, _ := strings.Cut(column, ".")
+			if hasColumn(t, db, table, name) != want {
+				t.Errorf("versão %d: coluna %s existe = %v, esperado %v", version, column, !want, want)
+			}
+		}
+	}
+}
+
+func TestMigrateUpDownUp(t *testing.T) {
+	db := newTestDatabase(t, 0)
+	assertSchema(t, db, 0)
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	assertSchema(t, db, LatestVersion())
+
+	for version := LatestVersion() - 1; version >= 0; version-- {
+		if err := db.Rollback(1); err != nil {
+			t.Fatalf("Rollback para %d: %v", version, err)
+		}
+		assertSchema(t, db, version)
+	}
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate após reverter tudo: %v", err)
+	}
+	assertSchema(t, db, LatestVersion())
+}
+
+// withMigration acrescenta uma migração à lista durante o teste
+func withMigration(t *testing.T, m Migration) {
+	t.Helper()
+	original := migrations
+	migrations = append(migrations[:len(migrations):len(migrations)], m)
+	t.Cleanup(func() { migrations = original })
+}
+
+func TestMigrationFailureKeepsVersion(t *testing.T) {
+	latest := LatestVersion()
+	failing := func(tx *sql.Tx) error {
+		if _, err := tx.Exec("CREATE TABLE parcial (id INTEGER)"); err != nil {
+			return err
+		}
+		return errors.New("falha proposital")
+	}
+
+	t.Run("up", func(t *testing.T) {
+		db := newTestDatabase(t, latest)
+		withMigration(t, Migration{Version: latest + 1, Name: "falha", Up: failing, Down: execSQL("")})
+
+		if err := db.Migrate(); err == nil {
+			t.Fatal("Migrate não retornou o erro da migração")
+		}
+		assertSchema(t, db, latest)
+		if hasColumn(t, db, "parcial", "id") {
+			t.Error("alteração da migração que falhou não foi desfeita")
+		}
+	})
+
+	t.Run("down", func(t *testing.T) {
+		db := newTestDatabase(t, latest)
+		withMigration(t, Migration{Version: latest + 1, Name: "falha", Up: execSQL(""), Down: failing})
+		if err := db.Migrate(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.Rollback(1); err == nil {
+			t.Fatal("Rollback não retornou o erro da migração")
+		}
+		if version, _ := db.SchemaVersion(); version != latest+1 {
+			t.Errorf("versão = %d, esperado %d", version, latest+1)
+		}
+		if hasColumn(t, db, "parcial", "id") {
+			t.Error("alteração da migração que falhou não foi desfeita")
+		}
+	})
+}
@@ -2,22 +2,44 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
+// BookRepository é o armazenamento de livros usado pelo processador. Database
+// (SQLite) e MemoryRepository (testes, execuções sem disco) a implementam.
+type BookRepository interface {
+	GetOrCreateAuthor(name string) (*Author, error)
+	GetOrCreatePublisher(name string) (*Publisher, error)
+	SaveBook(book *Book) (*Book, error)
+	SaveBookWithRelations(book *Book, authors []BookAuthor, publisher string) (*Book, error)
+	SaveBookWithCopy(book *Book, authors []BookAuthor, publisher string, c *BookCopy) (*Book, error)
+	GetBookByISBN(isbn string) (*Book, error)
+	CountBooks() (int, error)
+	SetBookAuthors(bookID int, authors []BookAuthor) error
+	GetBookAuthors(bookID int) ([]BookAuthor, error)
+	AddBookCopy(c *BookCopy) (*BookCopy, error)
+	GetBookCopies(bookID int) ([]*BookCopy, error)
+}
+
+var _ BookRepository = (*Database)(nil)
+
 // Author representa um autor no banco de dados
 type Author struct {
-	ID        int
-	Name      string
-	CreatedAt time.Time
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Publisher representa uma editora no banco de dados
 type Publisher struct {
-	ID        int
-	Name      string
-	CreatedAt time.Time
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Book representa um livro no banco de dados
@@ -31,105 +53,189 @@ type Book struct {
 	Pages       int
 	Description string
 	CoverURL    string
+	Sources     map[string]string // campo -> provedor de metadados que o forneceu
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// querier é a parte comum de *sql.DB e *sql.Tx usada pelas operações que
+// rodam tanto isoladas quanto dentro de uma transação
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // GetOrCreateAuthor obtém um autor existente ou cria um novo
 func (db *Database) GetOrCreateAuthor(name string) (*Author, error) {
 	if name == "" {
 		return nil, nil
 	}
+	return upsertAuthor(db.conn, name)
+}
+
+// upsertAuthor obtém ou cria um autor. O INSERT ... ON CONFLICT evita a
+// corrida entre workers que buscam e criam o mesmo autor ao mesmo tempo.
+func upsertAuthor(q querier, name string) (*Author, error) {
+	now := time.Now()
+	_, err := q.Exec("INSERT INTO authors (name, created_at, updated_at) VALUES (?, ?, ?) ON CONFLICT(name) DO NOTHING", name, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar autor: %w", err)
+	}
 
 	var author Author
-
-	// Tentar obter autor existente
-	err := db.conn.QueryRow(
-		"SELECT id, name, created_at FROM authors WHERE name = ?",
+	var updated sql.NullTime
+	err = q.QueryRow(
+		"SELECT id, name, created_at, updated_at FROM authors WHERE name = ?",
 		name,
-	).Scan(&author.ID, &author.Name, &author.CreatedAt)
+	).Scan(&author.ID, &author.Name, &author.CreatedAt, &updated)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar autor: %w", err)
+	}
+	author.UpdatedAt = updatedAt(author.CreatedAt, updated)
 
-	if err == nil {
-		return &author, nil
+	return &author, nil
+}
+
+// GetOrCreatePublisher obtém uma editora existente ou cria uma nova
+func (db *Database) GetOrCreatePublisher(name string) (*Publisher, error) {
+	if name == "" {
+		return nil, nil
 	}
+	return upsertPublisher(db.conn, name)
+}
 
-	if err != sql.ErrNoRows {
-		return nil, fmt.Errorf("erro ao buscar autor: %w", err)
+// upsertPublisher obtém ou cria uma editora
+func upsertPublisher(q querier, name string) (*Publisher, error) {
+	now := time.Now()
+	_, err := q.Exec("INSERT INTO publishers (name, created_at, updated_at) VALUES (?, ?, ?) ON CONFLICT(name) DO NOTHING", name, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar editora: %w", err)
 	}
 
-	// Criar novo autor
-	result, err := db.conn.Exec(
-		"INSERT INTO authors (name) VALUES (?)",
+	var publisher Publisher
+	var updated sql.NullTime
+	err = q.QueryRow(
+		"SELECT id, name, created_at, updated_at FROM publishers WHERE name = ?",
 		name,
-	)
-
+	).Scan(&publisher.ID, &publisher.Name, &publisher.CreatedAt, &updated)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar autor: %w", err)
+		return nil, fmt.Errorf("erro ao buscar editora: %w", err)
 	}
+	publisher.UpdatedAt = updatedAt(publisher.CreatedAt, updated)
 
-	id, err := result.LastInsertId()
+	return &publisher, nil
+}
+
+// SaveBookWithRelations grava o livro, sua editora e seus autores (na ordem de
+// crédito) em uma única transação: uma falha no meio não deixa autores ou
+// editoras órfãos, e os upserts tornam a operação segura com vários workers
+func (db *Database) SaveBookWithRelations(book *Book, authors []BookAuthor, publisher string) (*Book, error) {
+	tx, err := db.conn.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter ID do autor: %w", err)
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
 	}
+	defer tx.Rollback()
 
-	author.ID = int(id)
-	author.Name = name
-	author.CreatedAt = time.Now()
+	if err := saveBookTx(tx, book, authors, publisher, sourcesOrigin(book.Sources)); err != nil {
+		return nil, err
+	}
 
-	return &author, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar livro: %w", err)
+	}
+	return book, nil
 }
 
-// GetOrCreatePublisher obtém uma editora existente ou cria uma nova
-func (db *Database) GetOrCreatePublisher(name string) (*Publisher, error) {
-	if name == "" {
-		return nil, nil
+// saveBookTx é o corpo de SaveBookWithRelations, para uso dentro de uma
+// transação já aberta. Os campos alterados são registrados no histórico do
+// livro com a origem informada.
+func saveBookTx(tx *sql.Tx, book *Book, authors []BookAuthor, publisher, origin string) error {
+	sources, err := encodeSources(book.Sources)
+	if err != nil {
+		return err
 	}
 
-	var publisher Publisher
-
-	// Tentar obter editora existente
-	err := db.conn.QueryRow(
-		"SELECT id, name, created_at FROM publishers WHERE name = ?",
-		name,
-	).Scan(&publisher.ID, &publisher.Name, &publisher.CreatedAt)
+	// Retrato anterior para o histórico (vazio se o livro ainda não existe)
+	var previousID int
+	err = tx.QueryRow("SELECT id FROM books WHERE isbn = ?", book.ISBN).Scan(&previousID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("erro ao verificar livro existente: %w", err)
+	}
+	before, err := bookSnapshot(tx, previousID)
+	if err != nil {
+		return err
+	}
 
-	if err == nil {
-		return &publisher, nil
+	book.PublisherID = nil
+	if publisher = strings.TrimSpace(publisher); publisher != "" {
+		p, err := upsertPublisher(tx, publisher)
+		if err != nil {
+			return err
+		}
+		book.PublisherID = &p.ID
 	}
 
-	if err != sql.ErrNoRows {
-		return nil, fmt.Errorf("erro ao buscar editora: %w", err)
+	resolved, err := resolveBookAuthors(tx, authors)
+	if err != nil {
+		return err
+	}
+	book.AuthorID = nil
+	if len(resolved) > 0 {
+		book.AuthorID = &resolved[0].AuthorID
 	}
 
-	// Criar nova editora
-	result, err := db.conn.Exec(
-		"INSERT INTO publishers (name) VALUES (?)",
-		name,
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO books (isbn, title, author_id, publisher_id, publish_date, pages, description, cover_url, metadata_sources, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(isbn) DO UPDATE SET
+			title = excluded.title,
+			author_id = excluded.author_id,
+			publisher_id = excluded.publisher_id,
+			publish_date = excluded.publish_date,
+			pages = excluded.pages,
+			description = excluded.description,
+			cover_url = excluded.cover_url,
+			metadata_sources = excluded.metadata_sources,
+			updated_at = excluded.updated_at
+	`,
+		book.ISBN, book.Title, book.AuthorID, book.PublisherID,
+		book.PublishDate, book.Pages, book.Description, book.CoverURL, sources, now, now,
 	)
-
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar editora: %w", err)
+		return fmt.Errorf("erro ao salvar livro: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	err = tx.QueryRow("SELECT id, created_at FROM books WHERE isbn = ?", book.ISBN).Scan(&book.ID, &book.CreatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter ID da editora: %w", err)
+		return fmt.Errorf("erro ao obter ID do livro: %w", err)
 	}
+	book.UpdatedAt = now
 
-	publisher.ID = int(id)
-	publisher.Name = name
-	publisher.CreatedAt = time.Now()
+	if err := replaceBookAuthors(tx, book.ID, resolved); err != nil {
+		return err
+	}
 
-	return &publisher, nil
+	after, err := bookSnapshot(tx, book.ID)
+	if err != nil {
+		return err
+	}
+	return recordBookChange(tx, book.ID, origin, before, after)
 }
 
 // SaveBook salva um livro no banco de dados (cria ou atualiza)
 func (db *Database) SaveBook(book *Book) (*Book, error) {
 	now := time.Now()
 
+	sources, err := encodeSources(book.Sources)
+	if err != nil {
+		return nil, err
+	}
+
 	// Verificar se o livro já existe
 	var existingID int
-	err := db.conn.QueryRow("SELECT id FROM books WHERE isbn = ?", book.ISBN).Scan(&existingID)
+	err = db.conn.QueryRow("SELECT id FROM books WHERE isbn = ?", book.ISBN).Scan(&existingID)
 
 	if err == nil {
 		// Atualizar livro existente
@@ -137,18 +243,19 @@ func (db *Database) SaveBook(book *Book) (*Book, error) {
 			UPDATE books 
 			SET title = ?, author_id = ?, publisher_id = ?, 
 			    publish_date = ?, pages = ?, description = ?, 
-			    cover_url = ?, updated_at = ?
+			    cover_url = ?, metadata_sources = ?, updated_at = ?
 			WHERE isbn = ?
 		`,
 			book.Title, book.AuthorID, book.PublisherID,
 			book.PublishDate, book.Pages, book.Description,
-			book.CoverURL, now, book.ISBN,
+			book.CoverURL, sources, now, book.ISBN,
 		)
 
 		if err != nil {
 			return nil, fmt.Errorf("erro ao atualizar livro: %w", err)
 		}
 
+		book.ID = existingID
 		book.UpdatedAt = now
 		return book, nil
 	}
@@ -159,11 +266,11 @@ func (db *Database) SaveBook(book *Book) (*Book, error) {
 
 	// Criar novo livro
 	result, err := db.conn.Exec(`
-		INSERT INTO books (isbn, title, author_id, publisher_id, publish_date, pages, description, cover_url, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO books (isbn, title, author_id, publisher_id, publish_date, pages, description, cover_url, metadata_sources, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		book.ISBN, book.Title, book.AuthorID, book.PublisherID,
-		book.PublishDate, book.Pages, book.Description, book.CoverURL, now, now,
+		book.PublishDate, book.Pages, book.Description, book.CoverURL, sources, now, now,
 	)
 
 	if err != nil {
@@ -185,13 +292,14 @@ func (db *Database) SaveBook(book *Book) (*Book, error) {
 // GetBookByISBN obtém um livro pelo ISBN
 func (db *Database) GetBookByISBN(isbn string) (*Book, error) {
 	var book Book
+	var sources sql.NullString
 
 	err := db.conn.QueryRow(`
-		SELECT id, isbn, title, author_id, publisher_id, publish_date, pages, description, cover_url, created_at, updated_at
+		SELECT id, isbn, title, author_id, publisher_id, publish_date, pages, description, cover_url, metadata_sources, created_at, updated_at
 		FROM books
 		WHERE isbn = ?
 	`, isbn).Scan(&book.ID, &book.ISBN, &book.Title, &book.AuthorID, &book.PublisherID,
-		&book.PublishDate, &book.Pages, &book.Description, &book.CoverURL, &book.CreatedAt, &book.UpdatedAt)
+		&book.PublishDate, &book.Pages, &book.Description, &book.CoverURL, &sources, &book.CreatedAt, &book.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -201,6 +309,10 @@ func (db *Database) GetBookByISBN(isbn string) (*Book, error) {
 		return nil, fmt.Errorf("erro ao buscar livro: %w", err)
 	}
 
+	if book.Sources, err = decodeSources(sources); err != nil {
+		return nil, err
+	}
+
 	return &book, nil
 }
 
@@ -208,7 +320,7 @@ func (db *Database) GetBookByISBN(isbn string) (*Book, error) {
 func (db *Database) GetAllBooks() ([]*Book, error) {
 	rows, err := db.conn.Query(`
 		SELECT b.id, b.isbn, b.title, b.author_id, b.publisher_id, b.publish_date, 
-		       b.pages, b.description, b.cover_url, b.created_at, b.updated_at
+		       b.pages, b.description, b.cover_url, b.metadata_sources, b.created_at, b.updated_at
 		FROM books b
 		ORDER BY b.created_at DESC
 	`)
@@ -221,11 +333,15 @@ func (db *Database) GetAllBooks() ([]*Book, error) {
 	var books []*Book
 	for rows.Next() {
 		var book Book
+		var sources sql.NullString
 		err := rows.Scan(&book.ID, &book.ISBN, &book.Title, &book.AuthorID, &book.PublisherID,
-			&book.PublishDate, &book.Pages, &book.Description, &book.CoverURL, &book.CreatedAt, &book.UpdatedAt)
+			&book.PublishDate, &book.Pages, &book.Description, &book.CoverURL, &sources, &book.CreatedAt, &book.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao fazer scan do livro: %w", err)
 		}
+		if book.Sources, err = decodeSources(sources); err != nil {
+			return nil, err
+		}
 		books = append(books, &book)
 	}
 
@@ -241,3 +357,29 @@ func (db *Database) CountBooks() (int, error) {
 	}
 	return count, nil
 }
+
+// encodeSources serializa a proveniência dos campos em JSON (NULL se vazia)
+func encodeSources(sources map[string]string) (sql.NullString, error) {
+	if len(sources) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("erro ao serializar proveniência: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeSources lê a proveniência dos campos gravada em JSON
+func decodeSources(value sql.NullString) (map[string]string, error) {
+	if !value.Valid || value.String == "" {
+		return nil, nil
+	}
+
+	var sources map[string]string
+	if err := json.Unmarshal([]byte(value.String), &sources); err != nil {
+		return nil, fmt.Errorf("erro ao ler proveniência: %w", err)
+	}
+	return sources, nil
+}
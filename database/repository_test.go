@@ -0,0 +1,32 @@
+package database
+
+import "testing"
+
+func TestSaveBookReturnsExistingID(t *testing.T) {
+	db := newTestDatabase(t, LatestVersion())
+
+	created, err := db.SaveBook(&Book{ISBN: "9780132350884", Title: "Clean Code"})
+	if err != nil {
+		t.Fatalf("SaveBook (novo): %v", err)
+	}
+	if created.ID == 0 {
+		t.Fatal("livro criado sem ID")
+	}
+
+	// Atualizar pelo ISBN com um Book sem ID deve devolver o ID do registro existente
+	updated, err := db.SaveBook(&Book{ISBN: "9780132350884", Title: "Clean Code (2ª ed.)"})
+	if err != nil {
+		t.Fatalf("SaveBook (atualização): %v", err)
+	}
+	if updated.ID != created.ID {
+		t.Errorf("ID após atualização = %d, esperado %d", updated.ID, created.ID)
+	}
+
+	stored, err := db.GetBookByISBN("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != created.ID || stored.Title != "Clean Code (2ª ed.)" {
+		t.Errorf("livro gravado = %+v", stored)
+	}
+}
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Marcadores usados pelo snippet() do SQLite em volta dos termos
+// encontrados; são removidos do texto e viram posições em Matches
+const (
+	snippetOpen  = '\x02'
+	snippetClose = '\x03'
+)
+
+// SnippetMatch é a posição, em bytes, de um termo encontrado no Snippet
+type SnippetMatch struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchResult é um livro encontrado pela busca textual. Snippet é um trecho
+// em texto puro; quem o exibe decide como escapar e destacar os Matches.
+type SearchResult struct {
+	*BookDetail
+	Snippet string         `json:"snippet"`
+	Matches []SnippetMatch `json:"matches,omitempty"`
+}
+
+// SearchResults é uma página de resultados da busca
+type SearchResults struct {
+	Query    string          `json:"query"`
+	Results  []*SearchResult `json:"results"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// createSearchIndex cria o índice textual books_fts (título, autores, editora
+// e descrição) e os triggers que o mantêm sincronizado. Usa FTS5 quando o
+// driver foi compilado com a tag sqlite_fts5 e FTS4 caso contrário.
+func createSearchIndex(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS books_fts USING fts5(title, authors, publisher, description, tokenize='unicode61')`)
+	if err != nil && strings.Contains(err.Error(), "no such module") {
+		_, err = tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS books_fts USING fts4(title, authors, publisher, description, tokenize=unicode61)`)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao criar índice de busca: %w", err)
+	}
+
+	statements := []string{
+		`CREATE TRIGGER IF NOT EXISTS books_fts_insert AFTER INSERT ON books BEGIN ` +
+			ftsRefresh("= new.id") + ` END`,
+		`CREATE TRIGGER IF NOT EXISTS books_fts_update AFTER UPDATE ON books BEGIN ` +
+			`DELETE FROM books_fts WHERE rowid = old.id; ` + ftsRefresh("= new.id") + ` END`,
+		`CREATE TRIGGER IF NOT EXISTS books_fts_delete AFTER DELETE ON books BEGIN ` +
+			`DELETE FROM books_fts WHERE rowid = old.id; END`,
+		`CREATE TRIGGER IF NOT EXISTS book_authors_fts_insert AFTER INSERT ON book_authors BEGIN ` +
+			ftsRefresh("= new.book_id") + ` END`,
+		`CREATE TRIGGER IF NOT EXISTS book_authors_fts_delete AFTER DELETE ON book_authors BEGIN ` +
+			ftsRefresh("= old.book_id") + ` END`,
+		`CREATE TRIGGER IF NOT EXISTS authors_fts_update AFTER UPDATE OF name ON authors BEGIN ` +
+			ftsRefresh("IN (SELECT book_id FROM book_authors WHERE author_id = new.id UNION SELECT id FROM books WHERE author_id = new.id)") + ` END`,
+		`CREATE TRIGGER IF NOT EXISTS publishers_fts_update AFTER UPDATE OF name ON publishers BEGIN ` +
+			ftsRefresh("IN (SELECT id FROM books WHERE publisher_id = new.id)") + ` END`,
+		// Livros já cadastrados
+		`DELETE FROM books_fts`,
+		ftsInsert(""),
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("erro ao criar índice de busca: %w", err)
+		}
+	}
+	return nil
+}
+
+// ftsRefresh regrava no índice os livros cujo id satisfaz a condição
+func ftsRefresh(cond string) string {
+	return `DELETE FROM books_fts WHERE rowid ` + cond + `; ` + ftsInsert(`WHERE b.id `+cond) + `;`
+}
+
+// ftsInsert indexa os livros selecionados. Autores vêm de book_authors, com
+// books.author_id como alternativa para livros sem essa lista.
+func ftsInsert(where string) string {
+	return `INSERT INTO books_fts (rowid, title, authors, publisher, description)
+		SELECT b.id, b.title,
+		       COALESCE(
+		           (SELECT group_concat(au.name, ' ') FROM book_authors ba JOIN authors au ON au.id = ba.author_id WHERE ba.book_id = b.id),
+		           (SELECT au.name FROM authors au WHERE au.id = b.author_id)),
+		       p.name, b.description
+		FROM books b
+		LEFT JOIN publishers p ON p.id = b.publisher_id ` + where
+}
+
+// SearchModule retorna o módulo do índice textual ("fts5" ou "fts4")
+func (db *Database) SearchModule() (string, error) {
+	var ddl string
+	err := db.conn.QueryRow(`SELECT sql FROM sqlite_master WHERE name = 'books_fts'`).Scan(&ddl)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("índice de busca não encontrado; aplique as migrações")
+	}
+	if err != nil {
+		return "", fmt.Errorf("erro ao consultar índice de busca: %w", err)
+	}
+	if strings.Contains(strings.ToLower(ddl), "fts5") {
+		return "fts5", nil
+	}
+	return "fts4", nil
+}
+
+// matchExpression converte o texto digitado em uma expressão MATCH segura:
+// cada palavra vira um termo obrigatório com busca por prefixo, e operadores
+// ou aspas do usuário são descartados
+func matchExpression(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = w + "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+// splitSnippet remove os marcadores do snippet do SQLite e retorna o texto
+// e as posições dos termos que estavam entre eles
+func splitSnippet(raw string) (string, []SnippetMatch) {
+	var text strings.Builder
+	var matches []SnippetMatch
+	start := -1
+	for _, r := range raw {
+		switch r {
+		case snippetOpen:
+			start = text.Len()
+		case snippetClose:
+			if start >= 0 && text.Len() > start {
+				matches = append(matches, SnippetMatch{Start: start, End: text.Len()})
+			}
+			start = -1
+		default:
+			text.WriteRune(r)
+		}
+	}
+	return text.String(), matches
+}
+
+// SearchBooks busca livros pelo título, autores, editora e descrição. Com
+// FTS5 os resultados vêm ordenados por relevância (o título pesa mais); com
+// FTS4, por título.
+func (db *Database) SearchBooks(query string, filters BookFilters, page Page) (*SearchResults, error) {
+	page = page.normalize()
+	results := &SearchResults{
+		Query:    query,
+		Results:  []*SearchResult{},
+		Page:     page.Number,
+		PageSize: page.Size,
+	}
+
+	match := matchExpression(query)
+	if match == "" {
+		return results, nil
+	}
+
+	module, err := db.SearchModule()
+	if err != nil {
+		return nil, err
+	}
+
+	snippet := `snippet(books_fts, -1, char(2), char(3), '…', 12)`
+	order := `bm25(books_fts, 10.0, 5.0, 2.0, 1.0)`
+	if module == "fts4" {
+		snippet = `snippet(books_fts, char(2), char(3), '…', -1, 12)`
+		order = `b.title COLLATE NOCASE`
+	}
+
+	where := []string{`books_fts MATCH ?`}
+	args := []interface{}{match}
+	clauses, filterArgs := filterClauses(filters)
+	where = append(where, clauses...)
+	args = append(args, filterArgs...)
+
+	from := `
+		FROM books_fts
+		JOIN books b ON b.id = books_fts.rowid
+		LEFT JOIN publishers p ON p.id = b.publisher_id
+		WHERE ` + strings.Join(where, " AND ")
+
+	if err := db.conn.QueryRow(`SELECT count(*)`+from, args...).Scan(&results.Total); err != nil {
+		return nil, fmt.Errorf("erro ao contar resultados da busca: %w", err)
+	}
+	if results.Total == 0 {
+		return results, nil
+	}
+
+	rows, err := db.conn.Query(`SELECT b.id, `+snippet+from+` ORDER BY `+order+` LIMIT ? OFFSET ?`,
+		append(args, page.Size, page.offset())...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar livros: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	snippets := make(map[int]string)
+	matches := make(map[int][]SnippetMatch)
+	for rows.Next() {
+		var id int
+		var s sql.NullString
+		if err := rows.Scan(&id, &s); err != nil {
+			return nil, fmt.Errorf("erro ao ler resultado da busca: %w", err)
+		}
+		ids = append(ids, id)
+		snippets[id], matches[id] = splitSnippet(s.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro em rows: %w", err)
+	}
+
+	details, err := db.getBookDetails(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if d, ok := details[id]; ok {
+			results.Results = append(results.Results, &SearchResult{BookDetail: d, Snippet: snippets[id], Matches: matches[id]})
+		}
+	}
+	return results, nil
+}
+
+// getBookDetails carrega os livros informados, indexados pelo id
+func (db *Database) getBookDetails(ids []int) (map[int]*BookDetail, error) {
+	result := make(map[int]*BookDetail, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := db.conn.Query(bookDetailSelect+` WHERE b.id IN (`+strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar livros com detalhes: %w", err)
+	}
+	defer rows.Close()
+
+	books, err := scanBookDetails(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := db.getAuthorsForBooks(ids)
+	if err != nil {
+		return nil, err
+	}
+	fillAuthors(books, authors)
+
+	for _, d := range books {
+		result[d.ID] = d
+	}
+	return result, nil
+}
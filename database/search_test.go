@@ -0,0 +1,168 @@
+package database
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Clean code", "clean* code*"},
+		{"  machado   ass ", "machado* ass*"},
+		{`"clean code"`, "clean* code*"},
+		{"clean* -code", "clean* code*"},
+		{"clean OR code", "clean* or* code*"},
+		{"NEAR(clean code)", "near* clean* code*"},
+		{"título:código", "título* código*"},
+		{`"*-`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := matchExpression(tt.query); got != tt.want {
+			t.Errorf("matchExpression(%q) = %q, esperado %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSnippet(t *testing.T) {
+	tests := []struct {
+		raw     string
+		text    string
+		matches []SnippetMatch
+	}{
+		{"sem termos", "sem termos", nil},
+		{"…o \x02clean\x03 code…", "…o clean code…", []SnippetMatch{{5, 10}}},
+		{"\x02Clean\x03 \x02Code\x03", "Clean Code", []SnippetMatch{{0, 5}, {6, 10}}},
+		{"ação \x02código\x03", "ação código", []SnippetMatch{{7, 14}}},
+		{"vazio \x02\x03 e aberto \x02fim", "vazio  e aberto fim", nil},
+	}
+
+	for _, tt := range tests {
+		text, matches := splitSnippet(tt.raw)
+		if text != tt.text || !reflect.DeepEqual(matches, tt.matches) {
+			t.Errorf("splitSnippet(%q) = %q, %v; esperado %q, %v", tt.raw, text, matches, tt.text, tt.matches)
+		}
+	}
+}
+
+// newSearchDatabase cria um banco com alguns livros indexados
+func newSearchDatabase(t *testing.T) *Database {
+	t.Helper()
+	db := newTestDatabase(t, LatestVersion())
+
+	books := []struct {
+		isbn, title, author, publisher, description string
+	}{
+		{"9780132350884", "Clean Code", "Robert C. Martin", "Prentice Hall", "A handbook of agile software craftsmanship."},
+		{"9781732102200", "A Philosophy of Software Design", "John Ousterhout", "Yaknyam Press", "Keeping code <b>clean</b> & simple."},
+		{"9780201633610", "Design Patterns", "Erich Gamma", "Addison-Wesley", "Elements of reusable object-oriented software."},
+	}
+	for _, b := range books {
+		_, err := db.SaveBookWithRelations(&Book{ISBN: b.isbn, Title: b.title, Description: b.description},
+			[]BookAuthor{{Name: b.author}}, b.publisher)
+		if err != nil {
+			t.Fatalf("SaveBookWithRelations(%s): %v", b.isbn, err)
+		}
+	}
+	return db
+}
+
+// searchISBNs retorna os ISBNs encontrados, na ordem dos resultados
+func searchISBNs(t *testing.T, db *Database, query string) []string {
+	t.Helper()
+	results, err := db.SearchBooks(query, BookFilters{}, Page{})
+	if err != nil {
+		t.Fatalf("SearchBooks(%q): %v", query, err)
+	}
+	isbns := []string{}
+	for _, r := range results.Results {
+		isbns = append(isbns, r.ISBN)
+	}
+	if results.Total != len(isbns) {
+		t.Errorf("SearchBooks(%q): total %d, %d resultados", query, results.Total, len(isbns))
+	}
+	return isbns
+}
+
+func TestSearchBooks(t *testing.T) {
+	db := newSearchDatabase(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"prefixo", "craft", []string{"9780132350884"}},
+		{"todas as palavras", "design gamma", []string{"9780201633610"}},
+		{"editora", "prentice", []string{"9780132350884"}},
+		{"autor", "ousterhout", []string{"9781732102200"}},
+		{"aspas, asterisco e hífen", `"clean" -code*`, []string{"9780132350884", "9781732102200"}},
+		{"OR é uma palavra, não um operador", "clean OR gamma", []string{}},
+		{"parênteses e dois-pontos", "design:(patterns)", []string{"9780201633610"}},
+		{"só pontuação", `"*-`, []string{}},
+		{"sem resultados", "inexistente", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := searchISBNs(t, db, tt.query)
+			if len(tt.want) > 1 {
+				// A ordem depende do módulo; ver TestSearchBooksRanking
+				sort.Strings(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resultados = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchBooksRanking(t *testing.T) {
+	db := newSearchDatabase(t)
+	module, err := db.SearchModule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "clean" está no título de Clean Code e na descrição do outro livro
+	want := []string{"9780132350884", "9781732102200"} // FTS5: o título pesa mais
+	if module == "fts4" {
+		want = []string{"9781732102200", "9780132350884"} // FTS4: ordem de título
+	}
+	if got := searchISBNs(t, db, "clean"); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: resultados = %v, esperado %v", module, got, want)
+	}
+}
+
+func TestSearchBooksSnippet(t *testing.T) {
+	db := newSearchDatabase(t)
+
+	results, err := db.SearchBooks("clean", BookFilters{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results.Results {
+		if strings.ContainsAny(r.Snippet, "\x02\x03") {
+			t.Errorf("%s: snippet com marcadores: %q", r.ISBN, r.Snippet)
+		}
+		if len(r.Matches) == 0 {
+			t.Errorf("%s: snippet %q sem termos encontrados", r.ISBN, r.Snippet)
+		}
+		for _, m := range r.Matches {
+			if term := r.Snippet[m.Start:m.End]; !strings.EqualFold(term, "clean") {
+				t.Errorf("%s: termo %q em %q", r.ISBN, term, r.Snippet)
+			}
+		}
+
+		// O texto vem como está no livro; escapar cabe a quem o exibe
+		if r.ISBN == "9781732102200" && !strings.Contains(r.Snippet, "<b>clean</b> & simple") {
+			t.Errorf("snippet = %q, esperado o texto original", r.Snippet)
+		}
+	}
+}
@@ -8,38 +8,57 @@ import (
 
 // BookDetail representa um livro com nomes de autor e editora
 type BookDetail struct {
-	ID           int
-	ISBN         string
-	Title        string
-	AuthorID     *int
-	AuthorName   string
-	PublisherID  *int
-	PublisherName string
-	PublishDate  string
-	Pages        int
-	Description  string
-	CoverURL     string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID            int               `json:"id"`
+	ISBN          string            `json:"isbn"`
+	Title         string            `json:"title"`
+	AuthorID      *int              `json:"author_id"`   // primeiro autor
+	AuthorName    string            `json:"author_name"` // primeiro autor
+	Authors       []BookAuthor      `json:"authors"`     // todos os autores, em ordem
+	PublisherID   *int              `json:"publisher_id"`
+	PublisherName string            `json:"publisher_name"`
+	PublishDate   string            `json:"publish_date"`
+	Pages         int               `json:"pages"`
+	Description   string            `json:"description"`
+	CoverURL      string            `json:"cover_url"`
+	Sources       map[string]string `json:"sources,omitempty"` // campo -> provedor de metadados
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
-// GetBooksWithDetails retorna livros junto com nome do autor e editora
-func (db *Database) GetBooksWithDetails() ([]*BookDetail, error) {
-	query := `
+// bookDetailSelect é a consulta base de BookDetail; quem a usa acrescenta
+// WHERE/ORDER BY e lê as linhas com scanBookDetails
+const bookDetailSelect = `
 	SELECT b.id, b.isbn, b.title, b.author_id, a.name as author_name, b.publisher_id, p.name as publisher_name,
-	       b.publish_date, b.pages, b.description, b.cover_url, b.created_at, b.updated_at
+	       b.publish_date, b.pages, b.description, b.cover_url, b.metadata_sources, b.created_at, b.updated_at
 	FROM books b
 	LEFT JOIN authors a ON b.author_id = a.id
 	LEFT JOIN publishers p ON b.publisher_id = p.id
-	ORDER BY b.created_at DESC
-	`
+`
 
-	rows, err := db.conn.Query(query)
+// GetBooksWithDetails retorna livros junto com nome do autor e editora
+func (db *Database) GetBooksWithDetails() ([]*BookDetail, error) {
+	rows, err := db.conn.Query(bookDetailSelect + ` ORDER BY b.created_at DESC`)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao consultar livros com detalhes: %w", err)
 	}
 	defer rows.Close()
 
+	results, err := scanBookDetails(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := db.getAuthorsForBooks(nil)
+	if err != nil {
+		return nil, err
+	}
+	fillAuthors(results, authors)
+
+	return results, nil
+}
+
+// scanBookDetails lê as linhas de uma consulta feita com bookDetailSelect
+func scanBookDetails(rows *sql.Rows) ([]*BookDetail, error) {
 	var results []*BookDetail
 	for rows.Next() {
 		var d BookDetail
@@ -47,9 +66,10 @@ func (db *Database) GetBooksWithDetails() ([]*BookDetail, error) {
 		var publisherName sql.NullString
 		var authorID sql.NullInt64
 		var publisherID sql.NullInt64
+		var sources sql.NullString
 
 		err := rows.Scan(&d.ID, &d.ISBN, &d.Title, &authorID, &authorName, &publisherID, &publisherName,
-			&d.PublishDate, &d.Pages, &d.Description, &d.CoverURL, &d.CreatedAt, &d.UpdatedAt)
+			&d.PublishDate, &d.Pages, &d.Description, &d.CoverURL, &sources, &d.CreatedAt, &d.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao ler linha de resultado: %w", err)
 		}
@@ -68,6 +88,9 @@ func (db *Database) GetBooksWithDetails() ([]*BookDetail, error) {
 		if publisherName.Valid {
 			d.PublisherName = publisherName.String
 		}
+		if d.Sources, err = decodeSources(sources); err != nil {
+			return nil, err
+		}
 
 		results = append(results, &d)
 	}
@@ -75,6 +98,15 @@ func (db *Database) GetBooksWithDetails() ([]*BookDetail, error) {
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("erro em rows: %w", err)
 	}
-
 	return results, nil
 }
+
+// fillAuthors preenche a lista de autores de cada livro (vazia, nunca nil)
+func fillAuthors(books []*BookDetail, authors map[int][]BookAuthor) {
+	for _, d := range books {
+		d.Authors = authors[d.ID]
+		if d.Authors == nil {
+			d.Authors = []BookAuthor{}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package ean
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNotFound indica que nenhum código EAN-13 válido foi encontrado
+var ErrNotFound = errors.New("nenhum código EAN-13 encontrado")
+
+// Result contém o código decodificado e o add-on EAN-5 (se presente)
+type Result struct {
+	Code  string // 13 dígitos, com dígito verificador válido
+	AddOn string // 5 dígitos do add-on (preço em livros), vazio se ausente
+}
+
+// Larguras (em módulos) dos dígitos no conjunto L, na ordem espaço/barra/espaço/barra.
+// O conjunto R usa as mesmas larguras começando por barra e o conjunto G as
+// mesmas larguras em ordem inversa.
+var lPatterns = [10][4]int{
+	{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+	{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+}
+
+var gPatterns = func() [10][4]int {
+	var g [10][4]int
+	for d, p := range lPatterns {
+		g[d] = [4]int{p[3], p[2], p[1], p[0]}
+	}
+	return g
+}()
+
+// firstDigitParity mapeia a paridade (bit 1 = G) dos 6 dígitos da esquerda para o primeiro dígito
+var firstDigitParity = [10]int{0x00, 0x0B, 0x0D, 0x0E, 0x13, 0x19, 0x1C, 0x15, 0x16, 0x1A}
+
+// addOnParity mapeia a paridade dos 5 dígitos do add-on EAN-5 para seu checksum
+var addOnParity = [10]int{0x18, 0x14, 0x12, 0x11, 0x0C, 0x06, 0x03, 0x0A, 0x09, 0x05}
+
+var (
+	startGuard  = []int{1, 1, 1}
+	middleGuard = []int{1, 1, 1, 1, 1}
+	addOnGuard  = []int{1, 1, 2}
+	addOnSep    = []int{1, 1}
+)
+
+const (
+	// Número de runs (barras e espaços) de um EAN-13: guardas + 12 dígitos de 4 runs
+	ean13Runs = 3 + 6*4 + 5 + 6*4 + 3
+	// Número de runs de um add-on EAN-5: guarda + 5 dígitos + 4 separadores
+	addOnRuns = 3 + 5*4 + 4*2
+
+	maxAvgVariance        = 0.48
+	maxIndividualVariance = 0.7
+)
+
+// decodeRuns procura um EAN-13 em uma sequência de larguras alternadas.
+// runs[0] é sempre um espaço (claro); índices ímpares são barras.
+func decodeRuns(runs []int) (*Result, bool) {
+	for i := 1; i+ean13Runs <= len(runs); i += 2 {
+		guardWidth := runs[i] + runs[i+1] + runs[i+2]
+		if runs[i-1] < guardWidth {
+			continue // zona de silêncio insuficiente
+		}
+		if variance(runs[i:i+3], startGuard) > maxAvgVariance {
+			continue
+		}
+
+		result, ok := decodeAt(runs, i)
+		if !ok {
+			continue
+		}
+
+		if add, ok := decodeAddOn(runs, i+ean13Runs, moduleWidth(runs[i:i+ean13Runs])); ok {
+			result.AddOn = add
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// decodeAt decodifica um EAN-13 cuja guarda inicial começa em runs[start]
+func decodeAt(runs []int, start int) (*Result, bool) {
+	digits := make([]byte, 13)
+	pos := start + 3
+
+	parity := 0
+	for d := 0; d < 6; d++ {
+		digit, isG, ok := decodeDigit(runs[pos:pos+4], true)
+		if !ok {
+			return nil, false
+		}
+		digits[d+1] = byte('0' + digit)
+		parity <<= 1
+		if isG {
+			parity |= 1
+		}
+		pos += 4
+	}
+
+	if variance(runs[pos:pos+5], middleGuard) > maxAvgVariance {
+		return nil, false
+	}
+	pos += 5
+
+	for d := 0; d < 6; d++ {
+		digit, _, ok := decodeDigit(runs[pos:pos+4], false)
+		if !ok {
+			return nil, false
+		}
+		digits[d+7] = byte('0' + digit)
+		pos += 4
+	}
+
+	if variance(runs[pos:pos+3], startGuard) > maxAvgVariance {
+		return nil, false
+	}
+
+	first := -1
+	for d, p := range firstDigitParity {
+		if p == parity {
+			first = d
+			break
+		}
+	}
+	if first < 0 {
+		return nil, false
+	}
+	digits[0] = byte('0' + first)
+
+	code := string(digits)
+	if !validChecksum(code) {
+		return nil, false
+	}
+	return &Result{Code: code}, true
+}
+
+// decodeAddOn tenta decodificar um add-on EAN-5 a partir de runs[start] (espaço após a guarda final)
+func decodeAddOn(runs []int, start int, module float64) (string, bool) {
+	if start+1+addOnRuns > len(runs) {
+		return "", false
+	}
+
+	// O add-on fica separado do código principal por 7 a 12 módulos
+	gap := float64(runs[start]) / module
+	if gap < 5 || gap > 15 {
+		return "", false
+	}
+
+	pos := start + 1
+	if variance(runs[pos:pos+3], addOnGuard) > maxAvgVariance {
+		return "", false
+	}
+	pos += 3
+
+	digits := make([]byte, 5)
+	parity := 0
+	for d := 0; d < 5; d++ {
+		if d > 0 {
+			if variance(runs[pos:pos+2], addOnSep) > maxAvgVariance {
+				return "", false
+			}
+			pos += 2
+		}
+
+		digit, isG, ok := decodeDigit(runs[pos:pos+4], true)
+		if !ok {
+			return "", false
+		}
+		digits[d] = byte('0' + digit)
+		parity <<= 1
+		if isG {
+			parity |= 1
+		}
+		pos += 4
+	}
+
+	sum := 0
+	for i, c := range digits {
+		if i%2 == 0 {
+			sum += 3 * int(c-'0')
+		} else {
+			sum += 9 * int(c-'0')
+		}
+	}
+	if addOnParity[sum%10] != parity {
+		return "", false
+	}
+	return string(digits), true
+}
+
+// decodeDigit compara 4 runs com os padrões L/G (lado esquerdo) ou R (lado direito)
+func decodeDigit(counters []int, left bool) (digit int, isG bool, ok bool) {
+	best := maxAvgVariance
+	digit = -1
+
+	for d := 0; d < 10; d++ {
+		if v := variance(counters, lPatterns[d][:]); v < best {
+			best, digit, isG = v, d, false
+		}
+		if left {
+			if v := variance(counters, gPatterns[d][:]); v < best {
+				best, digit, isG = v, d, true
+			}
+		}
+	}
+	return digit, isG, digit >= 0
+}
+
+// variance mede o quanto as larguras observadas se afastam do padrão esperado,
+// normalizando pela largura total (mesma heurística usada pelo ZXing)
+func variance(counters []int, pattern []int) float64 {
+	total, patternLength := 0, 0
+	for i := range counters {
+		total += counters[i]
+		patternLength += pattern[i]
+	}
+	if total < patternLength {
+		// menos de um pixel por módulo
+		return math.Inf(1)
+	}
+
+	unit := float64(total) / float64(patternLength)
+	maxIndividual := maxIndividualVariance * unit
+
+	sum := 0.0
+	for i := range counters {
+		v := math.Abs(float64(counters[i]) - float64(pattern[i])*unit)
+		if v > maxIndividual {
+			return math.Inf(1)
+		}
+		sum += v
+	}
+	return sum / float64(total)
+}
+
+// moduleWidth estima a largura de um módulo a partir das runs de um EAN-13 completo
+func moduleWidth(runs []int) float64 {
+	total := 0
+	for _, r := range runs {
+		total += r
+	}
+	return float64(total) / 95
+}
+
+// validChecksum verifica o dígito verificador módulo 10 de um EAN-13
+func validChecksum(code string) bool {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(code[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return int(code[12]-'0') == (10-sum%10)%10
+}
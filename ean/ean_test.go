@@ -0,0 +1,124 @@
+package ean
+
+import "testing"
+
+// encode gera os módulos (true = barra) de um EAN-13 com zonas de silêncio e,
+// se addOn não for vazio, o add-on EAN-5 separado por 9 módulos
+func encode(code, addOn string) []bool {
+	var modules []bool
+	put := func(widths []int, bar bool) {
+		for _, w := range widths {
+			for i := 0; i < w; i++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	digit := func(c byte, g bool, bar bool) {
+		p := lPatterns[c-'0']
+		if g {
+			p = gPatterns[c-'0']
+		}
+		put(p[:], bar)
+	}
+
+	put([]int{11}, false)
+	put(startGuard, true)
+	parity := firstDigitParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		digit(code[i], parity&(1<<(6-i)) != 0, false)
+	}
+	put(middleGuard, false)
+	for i := 7; i <= 12; i++ {
+		digit(code[i], false, true)
+	}
+	put(startGuard, true)
+
+	if addOn != "" {
+		sum := 0
+		for i := 0; i < 5; i++ {
+			if i%2 == 0 {
+				sum += 3 * int(addOn[i]-'0')
+			} else {
+				sum += 9 * int(addOn[i]-'0')
+			}
+		}
+		parity := addOnParity[sum%10]
+
+		put([]int{9}, false)
+		put(addOnGuard, true)
+		for i := 0; i < 5; i++ {
+			if i > 0 {
+				put(addOnSep, false)
+			}
+			digit(addOn[i], parity&(1<<(4-i)) != 0, false)
+		}
+	}
+	put([]int{11}, false)
+	return modules
+}
+
+// runsOf converte módulos em larguras alternadas com a escala informada
+func runsOf(modules []bool, scale int) []int {
+	dark := make([]bool, 0, len(modules)*scale)
+	for _, m := range modules {
+		for i := 0; i < scale; i++ {
+			dark = append(dark, m)
+		}
+	}
+	return toRuns(dark, false)
+}
+
+func TestDecodeRuns(t *testing.T) {
+	tests := []struct {
+		code, addOn string
+	}{
+		{"9780132350884", ""},
+		{"9780596007126", "51995"},
+		{"9788535902778", "00000"},
+		{"4006381333931", ""},
+		{"0012345678905", "90000"},
+	}
+
+	for _, tt := range tests {
+		for _, scale := range []int{1, 2, 3} {
+			result, ok := decodeRuns(runsOf(encode(tt.code, tt.addOn), scale))
+			if !ok {
+				t.Errorf("%s escala %d: não decodificado", tt.code, scale)
+				continue
+			}
+			if result.Code != tt.code || result.AddOn != tt.addOn {
+				t.Errorf("%s escala %d: resultado = %+v, esperado %s+%s", tt.code, scale, result, tt.code, tt.addOn)
+			}
+		}
+	}
+}
+
+func TestDecodeRunsRejects(t *testing.T) {
+	if _, ok := decodeRuns(toRuns(encode("9780132350880", ""), false)); ok {
+		t.Error("código com dígito verificador inválido foi aceito")
+	}
+
+	valid := encode("9780132350884", "")
+	if _, ok := decodeRuns(toRuns(valid[:60], false)); ok {
+		t.Error("código truncado foi aceito")
+	}
+
+	// Sem zona de silêncio antes da guarda inicial
+	if _, ok := decodeRuns(toRuns(valid[11:], false)); ok {
+		t.Error("código sem zona de silêncio foi aceito")
+	}
+}
+
+func TestValidChecksum(t *testing.T) {
+	for code, want := range map[string]bool{
+		"9780132350884": true,
+		"9780596007126": true,
+		"9780596007127": false,
+		"0000000000000": true,
+	} {
+		if got := validChecksum(code); got != want {
+			t.Errorf("validChecksum(%s) = %v, esperado %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package ean
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+
+	// Registrar decodificadores de PNG e JPEG
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// scanLines é o número aproximado de linhas (e colunas) examinadas por imagem
+const scanLines = 60
+
+// DecodeFile abre um arquivo PNG/JPEG e decodifica o EAN-13 contido nele
+func DecodeFile(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir imagem %s: %w", path, err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar imagem %s: %w", path, err)
+	}
+
+	return Decode(img)
+}
+
+// Decode procura um EAN-13 na imagem varrendo linhas horizontais e verticais
+// nos dois sentidos; o código lido mais vezes é retornado
+func Decode(img image.Image) (*Result, error) {
+	gray := toGray(img)
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	votes := make(map[string]int)
+	addOns := make(map[string]map[string]int)
+
+	try := func(line []uint8) {
+		for _, bin := range []func([]uint8) []bool{binarizeMidpoint, binarizeLocalMean} {
+			dark := bin(line)
+			for _, reversed := range []bool{false, true} {
+				result, ok := decodeRuns(toRuns(dark, reversed))
+				if !ok {
+					continue
+				}
+				votes[result.Code]++
+				if result.AddOn != "" {
+					if addOns[result.Code] == nil {
+						addOns[result.Code] = make(map[string]int)
+					}
+					addOns[result.Code][result.AddOn]++
+				}
+			}
+		}
+	}
+
+	line := make([]uint8, width)
+	for y := 0; y < height; y += step(height) {
+		off := y * gray.Stride
+		copy(line, gray.Pix[off:off+width])
+		try(line)
+	}
+
+	column := make([]uint8, height)
+	for x := 0; x < width; x += step(width) {
+		for y := 0; y < height; y++ {
+			column[y] = gray.Pix[y*gray.Stride+x]
+		}
+		try(column)
+	}
+
+	code := mostVoted(votes)
+	if code == "" {
+		return nil, ErrNotFound
+	}
+	return &Result{Code: code, AddOn: mostVoted(addOns[code])}, nil
+}
+
+// step retorna o espaçamento entre linhas varridas
+func step(size int) int {
+	if s := size / scanLines; s > 1 {
+		return s
+	}
+	return 1
+}
+
+// toGray converte a imagem para tons de cinza com origem em (0, 0). Imagens em
+// tons de cinza e JPEGs (YCbCr, cuja luminância já é o cinza) são copiadas
+// linha a linha; os demais formatos passam pela conversão de cor pixel a pixel.
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	gray := image.NewGray(image.Rect(0, 0, width, bounds.Dy()))
+
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			off := src.PixOffset(bounds.Min.X, y)
+			copy(gray.Pix[(y-bounds.Min.Y)*gray.Stride:], src.Pix[off:off+width])
+		}
+	case *image.YCbCr:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			off := src.YOffset(bounds.Min.X, y)
+			copy(gray.Pix[(y-bounds.Min.Y)*gray.Stride:], src.Y[off:off+width])
+		}
+	default:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				gray.Pix[(y-bounds.Min.Y)*gray.Stride+(x-bounds.Min.X)] = c.Y
+			}
+		}
+	}
+	return gray
+}
+
+// binarizeMidpoint usa como limiar o ponto médio entre o pixel mais claro e o mais escuro
+func binarizeMidpoint(line []uint8) []bool {
+	lo, hi := uint8(255), uint8(0)
+	for _, v := range line {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	threshold := (int(lo) + int(hi)) / 2
+	dark := make([]bool, len(line))
+	for i, v := range line {
+		dark[i] = int(v) < threshold
+	}
+	return dark
+}
+
+// binarizeLocalMean compara cada pixel com a média da vizinhança, tolerando
+// iluminação irregular em fotografias
+func binarizeLocalMean(line []uint8) []bool {
+	n := len(line)
+	window := n / 16
+	if window < 8 {
+		window = 8
+	}
+
+	prefix := make([]int, n+1)
+	for i, v := range line {
+		prefix[i+1] = prefix[i] + int(v)
+	}
+
+	dark := make([]bool, n)
+	for i, v := range line {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > n {
+			hi = n
+		}
+		mean := (prefix[hi] - prefix[lo]) / (hi - lo)
+		// Margem evita que ruído em áreas uniformes vire barras
+		dark[i] = int(v) < mean-8
+	}
+	return dark
+}
+
+// toRuns converte pixels binarizados em larguras alternadas começando por um espaço
+func toRuns(dark []bool, reversed bool) []int {
+	at := func(i int) bool {
+		if reversed {
+			return dark[len(dark)-1-i]
+		}
+		return dark[i]
+	}
+
+	runs := []int{0}
+	current := false
+	for i := range dark {
+		if at(i) != current {
+			runs = append(runs, 0)
+			current = !current
+		}
+		runs[len(runs)-1]++
+	}
+	return runs
+}
+
+// mostVoted retorna a chave com mais votos (vazio se não houver votos)
+func mostVoted(votes map[string]int) string {
+	best, count := "", 0
+	for k, v := range votes {
+		if v > count || (v == count && k < best) {
+			best, count = k, v
+		}
+	}
+	return best
+}
@@ -0,0 +1,163 @@
+package ean
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// barcodeImage desenha os módulos com a escala e as cores informadas, com
+// margem clara em volta, girado em graus (sentido horário) em torno do centro
+func barcodeImage(modules []bool, scale int, bar, space uint8, degrees float64) *image.Gray {
+	const height, margin = 80, 20
+	w, h := len(modules)*scale, height
+
+	// Tela grande o bastante para qualquer rotação
+	size := int(math.Hypot(float64(w), float64(h))) + 2*margin
+	img := image.NewGray(image.Rect(0, 0, size, size))
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	cx, cy := float64(size)/2, float64(size)/2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// Rotação inversa: de onde na etiqueta vem este pixel
+			dx, dy := float64(x)-cx, float64(y)-cy
+			u := dx*cos + dy*sin + float64(w)/2
+			v := -dx*sin + dy*cos + float64(h)/2
+
+			value := space
+			if u >= 0 && v >= 0 && int(u) < w && int(v) < h && modules[int(u)/scale] {
+				value = bar
+			}
+			img.Pix[y*img.Stride+x] = value
+		}
+	}
+	return img
+}
+
+func TestDecodeImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		code, addOn string
+		scale       int
+		bar, space  uint8
+		degrees     float64
+	}{
+		{"EAN-13", "9780132350884", "", 2, 0, 255, 0},
+		{"EAN-13+5", "9780596007126", "51995", 2, 0, 255, 0},
+		{"escala 3", "9788535902778", "00000", 3, 20, 230, 0},
+		{"baixo contraste", "9780132350884", "", 2, 110, 150, 0},
+		{"baixo contraste com add-on", "9780596007126", "51995", 3, 120, 160, 0},
+		{"girado 90°", "9780132350884", "", 2, 0, 255, 90},
+		{"de cabeça para baixo", "9780596007126", "51995", 2, 0, 255, 180},
+		{"girado 270° com add-on", "9780596007126", "51995", 2, 0, 255, 270},
+		{"levemente inclinado", "9780132350884", "", 3, 0, 255, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := barcodeImage(encode(tt.code, tt.addOn), tt.scale, tt.bar, tt.space, tt.degrees)
+			result, err := Decode(img)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if result.Code != tt.code || result.AddOn != tt.addOn {
+				t.Errorf("resultado = %+v, esperado %s+%s", result, tt.code, tt.addOn)
+			}
+		})
+	}
+}
+
+func TestDecodeImageNotFound(t *testing.T) {
+	blank := image.NewGray(image.Rect(0, 0, 200, 100))
+	for i := range blank.Pix {
+		blank.Pix[i] = 255
+	}
+	if _, err := Decode(blank); !errors.Is(err, ErrNotFound) {
+		t.Errorf("imagem em branco: erro = %v, esperado ErrNotFound", err)
+	}
+
+	// Listras regulares têm barras e espaços, mas nenhum padrão EAN
+	stripes := make([]bool, 120)
+	for i := range stripes {
+		stripes[i] = i%4 < 2 && i > 10 && i < 110
+	}
+	if _, err := Decode(barcodeImage(stripes, 2, 0, 255, 0)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("listras: erro = %v, esperado ErrNotFound", err)
+	}
+}
+
+func TestDecodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capa.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, barcodeImage(encode("9780596007126", "51995"), 2, 0, 255, 0)); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	result, err := DecodeFile(path)
+	if err != nil {
+		t.Fatalf("DecodeFile: %v", err)
+	}
+	if result.Code != "9780596007126" || result.AddOn != "51995" {
+		t.Errorf("resultado = %+v", result)
+	}
+
+	if _, err := DecodeFile(filepath.Join(t.TempDir(), "inexistente.png")); err == nil {
+		t.Error("esperado erro para arquivo inexistente")
+	}
+}
+
+func TestToGray(t *testing.T) {
+	src := barcodeImage(encode("9780132350884", ""), 1, 30, 220, 0)
+	b := src.Bounds()
+	rect := image.Rect(10, 5, b.Dx()-7, b.Dy()-3)
+
+	// Mesma imagem em outros formatos, recortada com origem diferente de (0, 0)
+	ycbcr := image.NewYCbCr(b, image.YCbCrSubsampleRatio420)
+	rgba := image.NewRGBA(b)
+	copy(ycbcr.Y, src.Pix)
+	for i := range ycbcr.Cb {
+		ycbcr.Cb[i], ycbcr.Cr[i] = 128, 128
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			rgba.Set(x, y, src.GrayAt(x, y))
+		}
+	}
+
+	for name, img := range map[string]image.Image{
+		"Gray":  src.SubImage(rect),
+		"YCbCr": ycbcr.SubImage(rect),
+		"RGBA":  rgba.SubImage(rect),
+	} {
+		gray := toGray(img)
+		if gray.Bounds() != image.Rect(0, 0, rect.Dx(), rect.Dy()) {
+			t.Errorf("%s: limites = %v", name, gray.Bounds())
+			continue
+		}
+		for y := 0; y < rect.Dy(); y++ {
+			for x := 0; x < rect.Dx(); x++ {
+				want := src.GrayAt(rect.Min.X+x, rect.Min.Y+y)
+				if got := gray.GrayAt(x, y); got != want {
+					t.Fatalf("%s: pixel (%d, %d) = %v, esperado %v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+
+	// Conversão pixel a pixel continua valendo para outros formatos
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{color.Black, color.White})
+	paletted.SetColorIndex(1, 0, 1)
+	if gray := toGray(paletted); gray.Pix[0] != 0 || gray.Pix[1] != 255 {
+		t.Errorf("Paletted: pixels = %v", gray.Pix)
+	}
+}
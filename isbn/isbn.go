@@ -0,0 +1,176 @@
+package isbn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Erros retornados pela validação de ISBN
+var (
+	ErrInvalidLength    = errors.New("tamanho inválido (esperado 10 ou 13 dígitos)")
+	ErrInvalidCharacter = errors.New("caractere inválido")
+	ErrInvalidChecksum  = errors.New("dígito verificador inválido")
+	ErrInvalidPrefix    = errors.New("prefixo EAN inválido (esperado 978 ou 979)")
+	ErrNotConvertible   = errors.New("ISBN-13 com prefixo 979 não possui ISBN-10 equivalente")
+)
+
+// ValidationError descreve por que um valor não é um ISBN válido
+type ValidationError struct {
+	Input string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("ISBN inválido %q: %v", e.Input, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Clean remove hífens e espaços e converte o 'x' final para maiúsculo
+func Clean(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range strings.TrimSpace(s) {
+		switch r {
+		case '-', ' ', '\t':
+			continue
+		case 'x':
+			b.WriteRune('X')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Normalize valida o valor informado e retorna o ISBN-13 canônico (apenas dígitos)
+func Normalize(s string) (string, error) {
+	clean := Clean(s)
+
+	switch len(clean) {
+	case 10:
+		if err := checkISBN10(clean); err != nil {
+			return "", &ValidationError{Input: s, Err: err}
+		}
+		return convert10To13(clean), nil
+	case 13:
+		if err := checkISBN13(clean); err != nil {
+			return "", &ValidationError{Input: s, Err: err}
+		}
+		return clean, nil
+	default:
+		return "", &ValidationError{Input: s, Err: ErrInvalidLength}
+	}
+}
+
+// IsValid indica se o valor é um ISBN-10 ou ISBN-13 válido
+func IsValid(s string) bool {
+	_, err := Normalize(s)
+	return err == nil
+}
+
+// IsValidISBN10 indica se o valor é um ISBN-10 válido
+func IsValidISBN10(s string) bool {
+	clean := Clean(s)
+	return len(clean) == 10 && checkISBN10(clean) == nil
+}
+
+// IsValidISBN13 indica se o valor é um ISBN-13 válido
+func IsValidISBN13(s string) bool {
+	clean := Clean(s)
+	return len(clean) == 13 && checkISBN13(clean) == nil
+}
+
+// ToISBN13 converte um ISBN (10 ou 13) para ISBN-13
+func ToISBN13(s string) (string, error) {
+	return Normalize(s)
+}
+
+// ToISBN10 converte um ISBN (10 ou 13) para ISBN-10 quando possível
+func ToISBN10(s string) (string, error) {
+	isbn13, err := Normalize(s)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(isbn13, "978") {
+		return "", &ValidationError{Input: s, Err: ErrNotConvertible}
+	}
+
+	body := isbn13[3:12]
+	return body + string(checkDigit10(body)), nil
+}
+
+// checkISBN10 verifica caracteres e o dígito verificador módulo 11
+func checkISBN10(s string) error {
+	for i := 0; i < 9; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return ErrInvalidCharacter
+		}
+	}
+
+	last := s[9]
+	if (last < '0' || last > '9') && last != 'X' {
+		return ErrInvalidCharacter
+	}
+
+	if checkDigit10(s[:9]) != last {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// checkISBN13 verifica caracteres, prefixo Bookland e o dígito verificador módulo 10
+func checkISBN13(s string) error {
+	for i := 0; i < 13; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return ErrInvalidCharacter
+		}
+	}
+
+	if !strings.HasPrefix(s, "978") && !strings.HasPrefix(s, "979") {
+		return ErrInvalidPrefix
+	}
+
+	if checkDigit13(s[:12]) != s[12] {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// checkDigit10 calcula o dígito verificador de um ISBN-10 a partir dos 9 primeiros dígitos
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// checkDigit13 calcula o dígito verificador de um ISBN-13 a partir dos 12 primeiros dígitos
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+// convert10To13 converte um ISBN-10 já validado para ISBN-13
+func convert10To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	return body + string(checkDigit13(body))
+}
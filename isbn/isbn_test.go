@@ -0,0 +1,114 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"978-0-306-40615-7", "9780306406157"},
+		{" 0 306 40615 2 ", "0306406152"},
+		{"080442957x", "080442957X"},
+		{"0-8044-2957-X\t", "080442957X"},
+	}
+	for _, tt := range tests {
+		if got := Clean(tt.in); got != tt.want {
+			t.Errorf("Clean(%q) = %q, esperado %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{"9780306406157", "9780306406157", nil},
+		{"978-0-306-40615-7", "9780306406157", nil},
+		{"0306406152", "9780306406157", nil},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"080442957x", "9780804429573", nil},
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"9780306406158", "", ErrInvalidChecksum},
+		{"0306406153", "", ErrInvalidChecksum},
+		{"12345", "", ErrInvalidLength},
+		{"", "", ErrInvalidLength},
+		{"97803064061X7", "", ErrInvalidCharacter},
+		{"03064X6152", "", ErrInvalidCharacter},
+		{"9770306406155", "", ErrInvalidPrefix},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Normalize(%q): erro %v, esperado %v", tt.in, err, tt.wantErr)
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Input != tt.in {
+				t.Errorf("Normalize(%q): esperado *ValidationError com a entrada, obtido %#v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; esperado %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestToISBN10(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{"9780306406157", "0306406152", nil},
+		{"978-0-8044-2957-3", "080442957X", nil},
+		{"0306406152", "0306406152", nil},
+		{"9791090636071", "", ErrNotConvertible},
+		{"9780306406158", "", ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		got, err := ToISBN10(tt.in)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToISBN10(%q): erro %v, esperado %v", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ToISBN10(%q) = %q, %v; esperado %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, isbn10 := range []string{"0306406152", "080442957X", "0596007124", "0132350882"} {
+		isbn13, err := ToISBN13(isbn10)
+		if err != nil {
+			t.Fatalf("ToISBN13(%q): %v", isbn10, err)
+		}
+		if !IsValidISBN13(isbn13) {
+			t.Errorf("ToISBN13(%q) = %q, que não é um ISBN-13 válido", isbn10, isbn13)
+		}
+		back, err := ToISBN10(isbn13)
+		if err != nil || back != isbn10 {
+			t.Errorf("ToISBN10(%q) = %q, %v; esperado %q", isbn13, back, err, isbn10)
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	if !IsValidISBN10("0-306-40615-2") || IsValidISBN10("9780306406157") {
+		t.Error("IsValidISBN10 deve aceitar apenas ISBN-10")
+	}
+	if !IsValidISBN13("978 0 306 40615 7") || IsValidISBN13("0306406152") {
+		t.Error("IsValidISBN13 deve aceitar apenas ISBN-13")
+	}
+	if IsValid("abc") || !IsValid("0306406152") {
+		t.Error("IsValid deve aceitar ISBN-10 e ISBN-13 válidos")
+	}
+}
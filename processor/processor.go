@@ -4,83 +4,99 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	"leitor-usbn/api"
 	"leitor-usbn/database"
+	"leitor-usbn/isbn"
 	"leitor-usbn/reader"
-	"sync"
-	"time"
 )
 
 // ProcessorConfig contém configurações para o processador
+// O ritmo das consultas é controlado pelo limitador do provedor de metadados
+// (api.ProviderConfig.RequestsPerSecond), compartilhado por todos os workers.
 type ProcessorConfig struct {
-	MaxWorkers           int           // Número de workers para processar ISBNs em paralelo
-	DelayBetweenRequests time.Duration // Delay entre requisições à API
-	MaxRetries           int           // Máximo de tentativas por ISBN
-	Verbose              bool          // Modo verbose
+	MaxWorkers int  // Número de workers para processar ISBNs em paralelo
+	MaxRetries int  // Máximo de tentativas por ISBN
+	MaxResults int  // Resultados guardados para GetResults/Summary (0 = todos); os mais antigos são descartados
+	Verbose    bool // Modo verbose
 }
 
+// Classes de erro do processador; falhas de consulta usam as classes do
+// pacote api (ex.: "not_found", "network")
+const (
+	ErrorClassInvalidISBN = "invalid_isbn"
+	ErrorClassCanceled    = "canceled"
+	ErrorClassDatabase    = "database"
+)
+
 // ProcessResult contém o resultado do processamento de um ISBN
 type ProcessResult struct {
-	ISBN      string
-	Success   bool
-	Error     string
-	Book      *database.Book
-	Timestamp time.Time
+	ISBN       string
+	Event      reader.ScanEvent // leitura que originou o processamento
+	Success    bool
+	Error      string
+	ErrorClass string // classe do erro, para relatórios (vazia em caso de sucesso)
+	Attempts   int    // consultas feitas ao provedor
+	CopyLost   bool   // a leitura descrevia um exemplar que não foi gravado (ver ScanEvent.Copy)
+	Book       *database.Book
+	Timestamp  time.Time
 }
 
 // Processor orquestra a leitura, consulta e armazenamento de livros
 type Processor struct {
-	db        *database.Database
-	apiClient *api.BookAPIClient
-	reader    reader.ISBNReader
-	config    ProcessorConfig
-	results   []*ProcessResult
-	mu        sync.Mutex
+	db       database.BookRepository
+	provider api.MetadataProvider
+	reader   reader.ISBNReader
+	config   ProcessorConfig
+	results  []*ProcessResult
+	onResult func(*ProcessResult)
+	mu       sync.Mutex
 }
 
 // NewProcessor cria uma nova instância do processador
 func NewProcessor(
-	db *database.Database,
-	apiClient *api.BookAPIClient,
+	db database.BookRepository,
+	provider api.MetadataProvider,
 	isbnReader reader.ISBNReader,
 	config ProcessorConfig,
 ) *Processor {
 	if config.MaxWorkers <= 0 {
 		config.MaxWorkers = 1
 	}
-	if config.DelayBetweenRequests == 0 {
-		config.DelayBetweenRequests = 500 * time.Millisecond
-	}
 	if config.MaxRetries <= 0 {
 		config.MaxRetries = 3
 	}
 
 	return &Processor{
-		db:        db,
-		apiClient: apiClient,
-		reader:    isbnReader,
-		config:    config,
-		results:   make([]*ProcessResult, 0),
+		db:       db,
+		provider: provider,
+		reader:   isbnReader,
+		config:   config,
+		results:  make([]*ProcessResult, 0),
 	}
 }
 
-// Process inicia o processamento de ISBNs
+// Process processa as leituras até o canal de Read() ser fechado (fim da
+// fonte, mesmo que o leitor já tenha terminado antes da chamada) ou ctx ser
+// cancelado. O leitor deve ter sido iniciado com Start.
 func (p *Processor) Process(ctx context.Context) error {
-	if !p.reader.IsRunning() {
-		return fmt.Errorf("leitor não está ativo")
-	}
-
 	if p.config.Verbose {
 		log.Printf("Iniciando processamento com %d workers", p.config.MaxWorkers)
 	}
 
 	// Criar workers
-	isbnChan := p.reader.Read()
+	eventChan := p.reader.Read()
 	var wg sync.WaitGroup
 
 	for i := 0; i < p.config.MaxWorkers; i++ {
 		wg.Add(1)
-		go p.worker(ctx, &wg, isbnChan, i+1)
+		go p.worker(ctx, &wg, eventChan, i+1)
 	}
 
 	wg.Wait()
@@ -93,7 +109,7 @@ func (p *Processor) Process(ctx context.Context) error {
 }
 
 // worker processa ISBNs do canal
-func (p *Processor) worker(ctx context.Context, wg *sync.WaitGroup, isbnChan <-chan string, workerID int) {
+func (p *Processor) worker(ctx context.Context, wg *sync.WaitGroup, eventChan <-chan reader.ScanEvent, workerID int) {
 	defer wg.Done()
 
 	for {
@@ -104,7 +120,7 @@ func (p *Processor) worker(ctx context.Context, wg *sync.WaitGroup, isbnChan <-c
 			}
 			return
 
-		case isbn, ok := <-isbnChan:
+		case event, ok := <-eventChan:
 			if !ok {
 				if p.config.Verbose {
 					log.Printf("Worker %d: canal fechado", workerID)
@@ -112,7 +128,7 @@ func (p *Processor) worker(ctx context.Context, wg *sync.WaitGroup, isbnChan <-c
 				return
 			}
 
-			result := p.processISBN(ctx, isbn)
+			result := p.processISBN(ctx, event)
 			p.addResult(result)
 
 			if p.config.Verbose {
@@ -120,72 +136,84 @@ func (p *Processor) worker(ctx context.Context, wg *sync.WaitGroup, isbnChan <-c
 				if !result.Success {
 					status = "✗"
 				}
-				log.Printf("Worker %d: %s ISBN %s - %s", workerID, status, isbn, result.Error)
-			}
-
-			// Delay entre requisições
-			select {
-			case <-time.After(p.config.DelayBetweenRequests):
-			case <-ctx.Done():
-				return
+				log.Printf("Worker %d: %s ISBN %s (%s) - %s", workerID, status, result.ISBN, event.Origin(), result.Error)
 			}
 		}
 	}
 }
 
+// ProcessEvent processa uma única leitura fora dos workers (ex.: a nova
+// consulta de um livro pedida pela interface web) e registra o resultado
+func (p *Processor) ProcessEvent(ctx context.Context, event reader.ScanEvent) *ProcessResult {
+	result := p.processISBN(ctx, event)
+	p.addResult(result)
+	return result
+}
+
 // processISBN processa um ISBN individual
-func (p *Processor) processISBN(ctx context.Context, isbn string) *ProcessResult {
+func (p *Processor) processISBN(ctx context.Context, event reader.ScanEvent) *ProcessResult {
 	result := &ProcessResult{
-		ISBN:      isbn,
+		ISBN:      event.ISBN,
+		Event:     event,
 		Timestamp: time.Now(),
 	}
 
-	// Consultar API com retry
-	var apiBook *api.OpenLibraryResponse
-	var err error
+	// O exemplar só é gravado junto com o livro. Se a consulta falhar, o erro
+	// avisa que a linha precisa ser importada de novo; a reimportação atualiza
+	// o exemplar pela linha de origem em vez de duplicá-lo.
+	defer func() {
+		if event.Copy && !result.Success {
+			result.CopyLost = true
+			result.Error += " (exemplar não registrado; importe a linha novamente)"
+		}
+	}()
+
+	// Normalizar para ISBN-13 antes de consultar e salvar
+	normalized, err := isbn.Normalize(event.ISBN)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorClass = ErrorClassInvalidISBN
+		return result
+	}
+	result.ISBN = normalized
+
+	// Consultar API, repetindo apenas erros transitórios (rede, 5xx, 429)
+	var bookData *api.BookData
 
 	for attempt := 1; attempt <= p.config.MaxRetries; attempt++ {
-		apiBook, err = p.apiClient.GetBookByISBN(isbn)
+		result.Attempts = attempt
+		bookData, err = p.provider.GetBookData(ctx, normalized)
 		if err == nil {
 			break
 		}
 
-		if attempt < p.config.MaxRetries {
-			select {
-			case <-time.After(time.Duration(attempt*attempt) * time.Second):
-			case <-ctx.Done():
-				result.Error = fmt.Sprintf("Contexto cancelado na tentativa %d", attempt)
-				return result
-			}
+		if ctx.Err() != nil {
+			result.Error = fmt.Sprintf("Contexto cancelado na tentativa %d", attempt)
+			result.ErrorClass = ErrorClassCanceled
+			return result
 		}
-	}
 
-	if err != nil {
-		result.Error = fmt.Sprintf("Erro ao consultar API (tentou %d vezes): %v", p.config.MaxRetries, err)
-		return result
-	}
-
-	// Converter dados
-	bookData := api.ConvertToBookData(apiBook)
+		if !api.IsTransient(err) || attempt == p.config.MaxRetries {
+			break
+		}
 
-	// Obter ou criar autor
-	var author *database.Author
-	if bookData.Author != "" {
-		author, err = p.db.GetOrCreateAuthor(bookData.Author)
-		if err != nil {
-			result.Error = fmt.Sprintf("Erro ao criar autor: %v", err)
+		select {
+		case <-time.After(api.Backoff(attempt, err)):
+		case <-ctx.Done():
+			result.Error = fmt.Sprintf("Contexto cancelado na tentativa %d", attempt)
+			result.ErrorClass = ErrorClassCanceled
 			return result
 		}
 	}
 
-	// Obter ou criar editora
-	var publisher *database.Publisher
-	if bookData.Publisher != "" {
-		publisher, err = p.db.GetOrCreatePublisher(bookData.Publisher)
-		if err != nil {
-			result.Error = fmt.Sprintf("Erro ao criar editora: %v", err)
-			return result
+	if err != nil {
+		result.ErrorClass = string(api.ClassOf(err))
+		if result.Attempts > 1 {
+			result.Error = fmt.Sprintf("Erro ao consultar API (tentou %d vezes): %v", result.Attempts, err)
+		} else {
+			result.Error = fmt.Sprintf("Erro ao consultar API: %v", err)
 		}
+		return result
 	}
 
 	// Criar estrutura de livro
@@ -196,19 +224,31 @@ func (p *Processor) processISBN(ctx context.Context, isbn string) *ProcessResult
 		Pages:       bookData.Pages,
 		Description: bookData.Description,
 		CoverURL:    bookData.CoverURL,
+		Sources:     bookData.Sources,
 	}
 
-	if author != nil {
-		dbBook.AuthorID = &author.ID
+	// Todos os autores, na ordem de crédito
+	authors := make([]database.BookAuthor, 0, len(bookData.Authors))
+	for _, name := range bookData.Authors {
+		authors = append(authors, database.BookAuthor{Name: name, Role: database.RoleAuthor})
 	}
-	if publisher != nil {
-		dbBook.PublisherID = &publisher.ID
+	if len(authors) == 0 && bookData.Author != "" {
+		authors = append(authors, database.BookAuthor{Name: bookData.Author, Role: database.RoleAuthor})
 	}
 
-	// Salvar no banco
-	savedBook, err := p.db.SaveBook(dbBook)
+	// Livro, editora e autores são gravados juntos em uma transação. Dados do
+	// exemplar (ex.: colunas extras de uma planilha de doações) entram na mesma
+	// transação; outros leitores usam Metadata só para informações da leitura
+	// (ex.: add-on EAN-5).
+	var savedBook *database.Book
+	if event.Copy {
+		savedBook, err = p.db.SaveBookWithCopy(dbBook, authors, bookData.Publisher, newBookCopy(event))
+	} else {
+		savedBook, err = p.db.SaveBookWithRelations(dbBook, authors, bookData.Publisher)
+	}
 	if err != nil {
 		result.Error = fmt.Sprintf("Erro ao salvar no banco: %v", err)
+		result.ErrorClass = ErrorClassDatabase
 		return result
 	}
 
@@ -217,14 +257,75 @@ func (p *Processor) processISBN(ctx context.Context, isbn string) *ProcessResult
 	return result
 }
 
+// newBookCopy monta o exemplar a partir dos metadados da leitura. Campos
+// conhecidos viram colunas próprias; os demais são guardados como JSON.
+func newBookCopy(event reader.ScanEvent) *database.BookCopy {
+	c := &database.BookCopy{
+		Quantity: 1,
+		Source:   event.Origin(),
+		Metadata: make(map[string]string),
+	}
+
+	// Arquivo e linha identificam o exemplar entre importações
+	if event.Source != "" && event.Line > 0 {
+		c.SourceFile, c.SourceLine = event.Source, event.Line
+		if abs, err := filepath.Abs(event.Source); err == nil {
+			c.SourceFile = abs
+		}
+	}
+
+	for key, value := range event.Metadata {
+		switch strings.ToLower(key) {
+		case "copies", "quantity", "quantidade", "exemplares":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				c.Quantity = n
+			} else {
+				c.Metadata[key] = value
+			}
+		case "condition", "condicao", "estado":
+			c.Condition = value
+		case "shelf", "estante", "prateleira":
+			c.Shelf = value
+		case "donor", "doador":
+			c.Donor = value
+		default:
+			c.Metadata[key] = value
+		}
+	}
+
+	return c
+}
+
+// OnResult registra uma função chamada a cada ISBN processado
+func (p *Processor) OnResult(fn func(*ProcessResult)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onResult = fn
+}
+
 // addResult adiciona um resultado de forma thread-safe
 func (p *Processor) addResult(result *ProcessResult) {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	if limit := p.config.MaxResults; limit > 0 && len(p.results) >= limit {
+		// Servidores de longa duração guardam só os últimos resultados
+		p.results = p.results[len(p.results)-limit+1:]
+	}
 	p.results = append(p.results, result)
+	fn := p.onResult
+	p.mu.Unlock()
+
+	// Leituras interrompidas continuam pendentes para o leitor (ex.: o arquivo
+	// da pasta monitorada fica em processing/ e é lido de novo no próximo início)
+	if acker, ok := p.reader.(reader.ResultAcker); ok && result.ErrorClass != ErrorClassCanceled {
+		acker.Ack(result.Event, result.Success)
+	}
+
+	if fn != nil {
+		fn(result)
+	}
 }
 
-// GetResults retorna todos os resultados
+// GetResults retorna os resultados guardados (os últimos MaxResults, se configurado)
 func (p *Processor) GetResults() []*ProcessResult {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -241,46 +342,100 @@ func (p *Processor) GetStats() map[string]interface{} {
 
 	successCount := 0
 	errorCount := 0
+	errorClasses := make(map[string]int)
 	for _, r := range results {
 		if r.Success {
 			successCount++
 		} else {
 			errorCount++
+			errorClasses[r.ErrorClass]++
 		}
 	}
 
 	return map[string]interface{}{
-		"total":   len(results),
-		"success": successCount,
-		"errors":  errorCount,
+		"total":         len(results),
+		"success":       successCount,
+		"errors":        errorCount,
+		"error_classes": errorClasses,
+	}
+}
+
+// ErrorClassCount é o número de erros de uma classe
+type ErrorClassCount struct {
+	Class string
+	Count int
+}
+
+// Summary é o resumo de um processamento, com os mesmos dados de PrintSummary
+type Summary struct {
+	Total        int
+	Success      int
+	Errors       int
+	CopiesLost   int               // exemplares não gravados por falha na consulta
+	ErrorClasses []ErrorClassCount // em ordem alfabética de classe
+	Failed       []*ProcessResult
+	Succeeded    []*ProcessResult
+}
+
+// Summary monta o resumo dos resultados obtidos até agora
+func (p *Processor) Summary() Summary {
+	results := p.GetResults()
+
+	summary := Summary{Total: len(results)}
+	classes := make(map[string]int)
+	for _, r := range results {
+		if !r.Success {
+			summary.Errors++
+			if r.CopyLost {
+				summary.CopiesLost++
+			}
+			classes[r.ErrorClass]++
+			summary.Failed = append(summary.Failed, r)
+			continue
+		}
+		summary.Success++
+		if r.Book != nil {
+			summary.Succeeded = append(summary.Succeeded, r)
+		}
 	}
+
+	for class, count := range classes {
+		summary.ErrorClasses = append(summary.ErrorClasses, ErrorClassCount{Class: class, Count: count})
+	}
+	sort.Slice(summary.ErrorClasses, func(i, j int) bool {
+		return summary.ErrorClasses[i].Class < summary.ErrorClasses[j].Class
+	})
+	return summary
 }
 
 // PrintSummary imprime um resumo dos resultados
 func (p *Processor) PrintSummary() {
-	results := p.GetResults()
-	stats := p.GetStats()
+	summary := p.Summary()
 
 	fmt.Println("\n========== RESUMO DO PROCESSAMENTO ==========")
-	fmt.Printf("Total de ISBNs processados: %d\n", stats["total"])
-	fmt.Printf("Sucesso: %d\n", stats["success"])
-	fmt.Printf("Erros: %d\n", stats["errors"])
+	fmt.Printf("Total de ISBNs processados: %d\n", summary.Total)
+	fmt.Printf("Sucesso: %d\n", summary.Success)
+	fmt.Printf("Erros: %d\n", summary.Errors)
+	if summary.CopiesLost > 0 {
+		fmt.Printf("Exemplares não registrados: %d (importe as linhas com erro novamente)\n", summary.CopiesLost)
+	}
+
+	if summary.Errors > 0 {
+		fmt.Println("\n--- Erros por Tipo ---")
+		for _, c := range summary.ErrorClasses {
+			fmt.Printf("  %s: %d\n", c.Class, c.Count)
+		}
 
-	if stats["errors"].(int) > 0 {
 		fmt.Println("\n--- ISBNs com Erro ---")
-		for _, r := range results {
-			if !r.Success {
-				fmt.Printf("  %s: %s\n", r.ISBN, r.Error)
-			}
+		for _, r := range summary.Failed {
+			fmt.Printf("  %s (%s) [%s]: %s\n", r.ISBN, r.Event.Origin(), r.ErrorClass, r.Error)
 		}
 	}
 
-	if stats["success"].(int) > 0 {
+	if summary.Success > 0 {
 		fmt.Println("\n--- ISBNs com Sucesso ---")
-		for _, r := range results {
-			if r.Success && r.Book != nil {
-				fmt.Printf("  %s: %s\n", r.ISBN, r.Book.Title)
-			}
+		for _, r := range summary.Succeeded {
+			fmt.Printf("  %s: %s\n", r.ISBN, r.Book.Title)
 		}
 	}
 
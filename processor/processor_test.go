@@ -0,0 +1,382 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"leitor-usbn/api"
+	"leitor-usbn/database"
+	"leitor-usbn/reader"
+)
+
+// stubProvider responde cada ISBN com a sequência de erros configurada e,
+// depois dela, com os dados do livro (ou "não encontrado" se não houver)
+type stubProvider struct {
+	books  map[string]*api.BookData
+	errors map[string][]error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newStubProvider() *stubProvider {
+	return &stubProvider{
+		books:  make(map[string]*api.BookData),
+		errors: make(map[string][]error),
+		calls:  make(map[string]int),
+	}
+}
+
+func (s *stubProvider) GetBookData(ctx context.Context, isbn string) (*api.BookData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.calls[isbn]
+	s.calls[isbn]++
+	if errs := s.errors[isbn]; n < len(errs) {
+		return nil, errs[n]
+	}
+	book, ok := s.books[isbn]
+	if !ok {
+		return nil, &api.APIError{Class: api.ErrorNotFound, ISBN: isbn, Err: api.ErrNotFound}
+	}
+	copied := *book
+	return &copied, nil
+}
+
+func (s *stubProvider) Name() string {
+	return "stub"
+}
+
+func (s *stubProvider) callsFor(isbn string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[isbn]
+}
+
+// serverError é um erro transitório (5xx) do provedor
+func serverError(isbn string) error {
+	return &api.APIError{Class: api.ErrorServer, ISBN: isbn, StatusCode: 502}
+}
+
+// sliceReader entrega as leituras informadas e fecha o canal; registra os Acks.
+// Como uma lista curta, já terminou quando Process começa (IsRunning é false).
+type sliceReader struct {
+	events chan reader.ScanEvent
+
+	mu   sync.Mutex
+	acks map[string]bool
+}
+
+func newSliceReader(events ...reader.ScanEvent) *sliceReader {
+	r := &sliceReader{events: make(chan reader.ScanEvent, len(events)), acks: make(map[string]bool)}
+	for _, ev := range events {
+		r.events <- ev
+	}
+	close(r.events)
+	return r
+}
+
+func (r *sliceReader) Start(ctx context.Context) error { return nil }
+func (r *sliceReader) Stop() error                     { return nil }
+func (r *sliceReader) Read() <-chan reader.ScanEvent   { return r.events }
+func (r *sliceReader) GetType() string                 { return "sliceReader" }
+func (r *sliceReader) IsRunning() bool                 { return false }
+
+func (r *sliceReader) Ack(event reader.ScanEvent, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acks[event.ISBN] = success
+}
+
+const (
+	cleanCode      = "9780132350884"
+	designPatterns = "9780201633610"
+	headFirst      = "9780596007126"
+)
+
+func cleanCodeData() *api.BookData {
+	return &api.BookData{
+		ISBN:      cleanCode,
+		Title:     "Clean Code",
+		Author:    "Robert C. Martin",
+		Authors:   []string{"Robert C. Martin"},
+		Publisher: "Prentice Hall",
+		Pages:     431,
+		Sources:   map[string]string{api.FieldTitle: "stub"},
+	}
+}
+
+// run processa as leituras e devolve os resultados indexados pelo ISBN
+func run(t *testing.T, repo database.BookRepository, provider api.MetadataProvider, config ProcessorConfig, events ...reader.ScanEvent) (*Processor, map[string]*ProcessResult) {
+	t.Helper()
+	proc := NewProcessor(repo, provider, newSliceReader(events...), config)
+	if err := proc.Process(context.Background()); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	results := make(map[string]*ProcessResult)
+	for _, r := range proc.GetResults() {
+		results[r.ISBN] = r
+	}
+	if len(results) != len(events) {
+		t.Fatalf("%d resultados para %d leituras", len(results), len(events))
+	}
+	return proc, results
+}
+
+func TestProcessSuccess(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	provider := newStubProvider()
+	provider.books[cleanCode] = cleanCodeData()
+
+	// ISBN-10 é normalizado antes da consulta
+	_, results := run(t, repo, provider, ProcessorConfig{}, reader.ScanEvent{ISBN: "0132350882", Raw: "0-13-235088-2"})
+
+	r := results[cleanCode]
+	if r == nil || !r.Success || r.Attempts != 1 || r.ErrorClass != "" {
+		t.Fatalf("resultado = %+v", r)
+	}
+	if r.Book == nil || r.Book.ID == 0 || r.Book.Title != "Clean Code" {
+		t.Errorf("livro = %+v", r.Book)
+	}
+
+	book, err := repo.GetBookByISBN(cleanCode)
+	if err != nil || book == nil {
+		t.Fatalf("livro não gravado: %v", err)
+	}
+	if book.Pages != 431 || book.Sources[api.FieldTitle] != "stub" {
+		t.Errorf("livro gravado = %+v", book)
+	}
+	authors, _ := repo.GetBookAuthors(book.ID)
+	if len(authors) != 1 || authors[0].Name != "Robert C. Martin" || authors[0].Role != database.RoleAuthor {
+		t.Errorf("autores = %+v", authors)
+	}
+	if copies, _ := repo.GetBookCopies(book.ID); len(copies) != 0 {
+		t.Errorf("exemplares criados sem pedido: %+v", copies)
+	}
+}
+
+func TestProcessNotFound(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	provider := newStubProvider()
+
+	proc, results := run(t, repo, provider, ProcessorConfig{MaxRetries: 3},
+		reader.ScanEvent{ISBN: cleanCode},
+		reader.ScanEvent{ISBN: "123"},
+	)
+
+	r := results[cleanCode]
+	if r.Success || r.ErrorClass != string(api.ErrorNotFound) || r.Attempts != 1 {
+		t.Errorf("resultado = %+v", r)
+	}
+	if n := provider.callsFor(cleanCode); n != 1 {
+		t.Errorf("\"não encontrado\" consultado %d vezes", n)
+	}
+	if r := results["123"]; r.Success || r.ErrorClass != ErrorClassInvalidISBN || r.Attempts != 0 {
+		t.Errorf("ISBN inválido: %+v", r)
+	}
+	if n, _ := repo.CountBooks(); n != 0 {
+		t.Errorf("%d livros gravados", n)
+	}
+
+	summary := proc.Summary()
+	if summary.Total != 2 || summary.Errors != 2 || len(summary.ErrorClasses) != 2 {
+		t.Errorf("resumo = %+v", summary)
+	}
+}
+
+func TestProcessTransientRetry(t *testing.T) {
+	t.Parallel()
+
+	repo := database.NewMemoryRepository()
+	provider := newStubProvider()
+	provider.books[cleanCode] = cleanCodeData()
+	provider.errors[cleanCode] = []error{serverError(cleanCode)}
+	provider.books[designPatterns] = &api.BookData{ISBN: designPatterns, Title: "Design Patterns"}
+	provider.errors[designPatterns] = []error{serverError(designPatterns), serverError(designPatterns)}
+
+	_, results := run(t, repo, provider, ProcessorConfig{MaxRetries: 2, MaxWorkers: 2},
+		reader.ScanEvent{ISBN: cleanCode},
+		reader.ScanEvent{ISBN: designPatterns},
+	)
+
+	if r := results[cleanCode]; !r.Success || r.Attempts != 2 {
+		t.Errorf("erro transitório seguido de sucesso: %+v", r)
+	}
+	if r := results[designPatterns]; r.Success || r.Attempts != 2 || r.ErrorClass != string(api.ErrorServer) {
+		t.Errorf("tentativas esgotadas: %+v", r)
+	}
+	if n, _ := repo.CountBooks(); n != 1 {
+		t.Errorf("%d livros gravados, esperado 1", n)
+	}
+}
+
+func TestProcessCanceledDuringRetry(t *testing.T) {
+	provider := newStubProvider()
+	provider.errors[cleanCode] = []error{serverError(cleanCode)}
+	proc := NewProcessor(database.NewMemoryRepository(), provider, nil, ProcessorConfig{MaxRetries: 3})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	r := proc.ProcessEvent(ctx, reader.ScanEvent{ISBN: cleanCode})
+	if r.Success || r.ErrorClass != ErrorClassCanceled {
+		t.Errorf("resultado = %+v", r)
+	}
+}
+
+func TestProcessCopies(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	provider := newStubProvider()
+	provider.books[cleanCode] = cleanCodeData()
+	provider.books[headFirst] = &api.BookData{ISBN: headFirst, Title: "Head First Design Patterns"}
+
+	donation := reader.ScanEvent{
+		ISBN:   cleanCode,
+		Source: "doacoes.csv",
+		Line:   2,
+		Copy:   true,
+		Metadata: map[string]string{
+			"copies":    "3",
+			"condition": "bom",
+			"shelf":     "B2",
+			"donor":     "Maria",
+			"obs":       "dedicatória na folha de rosto",
+		},
+	}
+	// Metadados da leitura que não descrevem um exemplar (add-on EAN-5 de uma foto)
+	photo := reader.ScanEvent{ISBN: headFirst, Metadata: map[string]string{"ean5_addon": "51995"}}
+
+	_, results := run(t, repo, provider, ProcessorConfig{}, donation, photo)
+
+	copies, err := repo.GetBookCopies(results[cleanCode].Book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 1 {
+		t.Fatalf("%d exemplares, esperado 1", len(copies))
+	}
+	c := copies[0]
+	if c.Quantity != 3 || c.Condition != "bom" || c.Shelf != "B2" || c.Donor != "Maria" {
+		t.Errorf("exemplar = %+v", c)
+	}
+	if c.Metadata["obs"] != "dedicatória na folha de rosto" || len(c.Metadata) != 1 {
+		t.Errorf("metadados do exemplar = %v", c.Metadata)
+	}
+	if c.Source != "linha 2 de doacoes.csv" {
+		t.Errorf("origem do exemplar = %q", c.Source)
+	}
+
+	if abs, _ := filepath.Abs("doacoes.csv"); c.SourceFile != abs || c.SourceLine != 2 {
+		t.Errorf("chave do exemplar = %s:%d", c.SourceFile, c.SourceLine)
+	}
+
+	if copies, _ := repo.GetBookCopies(results[headFirst].Book.ID); len(copies) != 0 {
+		t.Errorf("leitura de imagem criou exemplares: %+v", copies)
+	}
+
+	// Reimportar a mesma planilha atualiza o exemplar em vez de duplicá-lo
+	donation.Metadata["copies"] = "4"
+	run(t, repo, provider, ProcessorConfig{}, donation)
+	copies, _ = repo.GetBookCopies(results[cleanCode].Book.ID)
+	if len(copies) != 1 || copies[0].ID != c.ID || copies[0].Quantity != 4 {
+		t.Errorf("exemplares após reimportação = %+v", copies)
+	}
+}
+
+func TestProcessCopyLost(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	provider := newStubProvider()
+	provider.books[designPatterns] = &api.BookData{ISBN: designPatterns, Title: "Design Patterns"}
+
+	donation := reader.ScanEvent{ISBN: cleanCode, Source: "doacoes.csv", Line: 3, Copy: true, Metadata: map[string]string{"donor": "Ana"}}
+	scan := reader.ScanEvent{ISBN: headFirst}
+	proc, results := run(t, repo, provider, ProcessorConfig{}, donation, scan, reader.ScanEvent{ISBN: designPatterns, Copy: true, Metadata: map[string]string{"donor": "Bia"}})
+
+	r := results[cleanCode]
+	if r.Success || !r.CopyLost || !strings.Contains(r.Error, "exemplar não registrado") {
+		t.Errorf("consulta falha com exemplar: %+v", r)
+	}
+	if r := results[headFirst]; r.CopyLost || strings.Contains(r.Error, "exemplar") {
+		t.Errorf("consulta falha sem exemplar: %+v", r)
+	}
+	if r := results[designPatterns]; !r.Success || r.CopyLost {
+		t.Errorf("consulta com sucesso: %+v", r)
+	}
+	if summary := proc.Summary(); summary.CopiesLost != 1 || summary.Errors != 2 {
+		t.Errorf("resumo = %+v", summary)
+	}
+}
+
+func TestProcessAcksReader(t *testing.T) {
+	provider := newStubProvider()
+	provider.books[cleanCode] = cleanCodeData()
+
+	r := newSliceReader(reader.ScanEvent{ISBN: cleanCode}, reader.ScanEvent{ISBN: designPatterns})
+	proc := NewProcessor(database.NewMemoryRepository(), provider, r, ProcessorConfig{})
+	if err := proc.Process(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if success, ok := r.acks[cleanCode]; !ok || !success {
+		t.Errorf("Ack de %s = %v, %v", cleanCode, success, ok)
+	}
+	if success, ok := r.acks[designPatterns]; !ok || success {
+		t.Errorf("Ack de %s = %v, %v", designPatterns, success, ok)
+	}
+}
+
+func TestMaxResults(t *testing.T) {
+	provider := newStubProvider()
+	proc := NewProcessor(database.NewMemoryRepository(), provider, nil, ProcessorConfig{MaxResults: 2})
+
+	var seen []string
+	proc.OnResult(func(r *ProcessResult) { seen = append(seen, r.ISBN) })
+
+	for _, code := range []string{cleanCode, designPatterns, headFirst} {
+		proc.ProcessEvent(context.Background(), reader.ScanEvent{ISBN: code})
+	}
+
+	results := proc.GetResults()
+	if len(results) != 2 || results[0].ISBN != designPatterns || results[1].ISBN != headFirst {
+		t.Errorf("resultados guardados = %v", results)
+	}
+	if len(seen) != 3 {
+		t.Errorf("OnResult chamado %d vezes", len(seen))
+	}
+}
+
+func TestProcessDatabaseError(t *testing.T) {
+	provider := newStubProvider()
+	provider.books[cleanCode] = &api.BookData{ISBN: cleanCode, Title: "Clean Code", Authors: []string{"Robert C. Martin"}}
+
+	proc := NewProcessor(failingRepository{database.NewMemoryRepository()}, provider, nil, ProcessorConfig{})
+	for _, event := range []reader.ScanEvent{
+		{ISBN: cleanCode},
+		{ISBN: cleanCode, Copy: true, Metadata: map[string]string{"copies": "2"}},
+	} {
+		r := proc.ProcessEvent(context.Background(), event)
+		if r.Success || r.ErrorClass != ErrorClassDatabase {
+			t.Errorf("resultado (exemplar: %v) = %+v", event.Copy, r)
+		}
+	}
+}
+
+// failingRepository falha ao gravar livros
+type failingRepository struct {
+	*database.MemoryRepository
+}
+
+func (failingRepository) SaveBookWithRelations(book *database.Book, authors []database.BookAuthor, publisher string) (*database.Book, error) {
+	return nil, errors.New("disco cheio")
+}
+
+func (failingRepository) SaveBookWithCopy(book *database.Book, authors []database.BookAuthor, publisher string, c *database.BookCopy) (*database.Book, error) {
+	return nil, errors.New("disco cheio")
+}
@@ -2,86 +2,214 @@ package reader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"time"
+
+	"leitor-usbn/isbn"
 )
 
-// BarcodeReaderUSB lê ISBNs de um scanner USB (que funciona como teclado)
+// BarcodeReaderUSB lê ISBNs de um scanner USB através da interface evdev do Linux
+// (/dev/input/eventN). O caminho também pode apontar para um arquivo ou pipe
+// com eventos input_event gravados, o que permite testar sem hardware.
 type BarcodeReaderUSB struct {
-	isbnChan  chan string
-	stopChan  chan struct{}
-	isRunning bool
-	verbose   bool
-	timeout   time.Duration
+	devicePath  string
+	grab        bool
+	eventChan   chan ScanEvent
+	stopChan    chan struct{}
+	isRunning   bool
+	verbose     bool
+	timeout     time.Duration
 	currentISBN string
 }
 
 // NewBarcodeReaderUSB cria uma nova instância do leitor USB
 func NewBarcodeReaderUSB(config ReaderConfig) *BarcodeReaderUSB {
-	timeout := time.Duration(config.Timeout) * time.Second
-	if config.Timeout == 0 {
-		timeout = 10 * time.Second // default
-	}
-
 	return &BarcodeReaderUSB{
-		isbnChan: make(chan string, 100),
-		stopChan: make(chan struct{}),
-		verbose:  config.Verbose,
-		timeout:  timeout,
+		devicePath: config.DevicePath,
+		grab:       config.GrabDevice,
+		eventChan:  make(chan ScanEvent, 100),
+		stopChan:   make(chan struct{}),
+		verbose:    config.Verbose,
+		timeout:    time.Duration(config.Timeout) * time.Second,
 	}
 }
 
 // Start inicia a leitura do scanner USB
-// Nota: Isso é uma implementação de exemplo
-// Em produção, você precisará usar uma biblioteca para capturar input de dispositivo HID
+// Sem DevicePath configurado o leitor aceita apenas SimulateBarcodeScan
 func (b *BarcodeReaderUSB) Start(ctx context.Context) error {
 	if b.isRunning {
 		return fmt.Errorf("leitor USB já está ativo")
 	}
 
+	var device *os.File
+	if b.devicePath != "" {
+		var err error
+		device, err = b.openDevice()
+		if err != nil {
+			return err
+		}
+	}
+
 	b.isRunning = true
 
 	if b.verbose {
 		log.Println("=== Leitor USB de Código de Barras Iniciado ===")
-		log.Println("Aguardando leitura de barcodes do scanner...")
-		log.Println("Scanner funciona como teclado - configure para enviar Enter/Return ao final do código")
+		if device != nil {
+			log.Printf("Lendo eventos de: %s", b.devicePath)
+		} else {
+			log.Println("Nenhum dispositivo configurado - apenas leituras simuladas")
+		}
+		log.Println("Scanner deve enviar Enter/Return ao final do código")
 	}
 
 	go func() {
 		defer func() {
 			b.isRunning = false
-			close(b.isbnChan)
+			close(b.eventChan)
 		}()
 
-		// Para usar em produção, você precisará:
-		// 1. Usar github.com/go-vgo/robotgo para capturar input global
-		// 2. Ou usar github.com/bendahl/uinput para capturar eventos de dispositivo
-		// 3. Ou usar github.com/tmc/keyboardinput para input de teclado
+		if device == nil {
+			b.waitSimulated(ctx)
+			return
+		}
 
-		// Implementação básica usando polling simulado
-		timer := time.NewTimer(b.timeout)
+		b.readDevice(ctx, device)
+	}()
+
+	return nil
+}
+
+// openDevice abre o dispositivo e, se configurado, obtém acesso exclusivo
+func (b *BarcodeReaderUSB) openDevice() (*os.File, error) {
+	device, err := os.Open(b.devicePath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir dispositivo %s: %w", b.devicePath, err)
+	}
 
+	info, err := device.Stat()
+	if err != nil {
+		device.Close()
+		return nil, fmt.Errorf("erro ao inspecionar dispositivo %s: %w", b.devicePath, err)
+	}
+
+	// Arquivos e pipes com eventos gravados não aceitam EVIOCGRAB
+	if b.grab && info.Mode()&os.ModeCharDevice != 0 {
+		if err := grabDevice(device, true); err != nil {
+			device.Close()
+			return nil, err
+		}
+	}
+
+	return device, nil
+}
+
+// readDevice decodifica eventos do dispositivo até EOF, timeout ou parada
+func (b *BarcodeReaderUSB) readDevice(ctx context.Context, device *os.File) {
+	done := make(chan struct{})
+	defer close(done)
+
+	// Fechar o arquivo desbloqueia a leitura em andamento
+	go func() {
 		select {
 		case <-b.stopChan:
 			if b.verbose {
 				log.Println("Leitor USB interrompido pelo usuário")
 			}
-			return
 		case <-ctx.Done():
 			if b.verbose {
 				log.Println("Contexto cancelado")
 			}
+		case <-done:
+		}
+		device.Close()
+	}()
+
+	var decoder keyDecoder
+
+	for {
+		if b.timeout > 0 {
+			// Arquivos regulares não suportam deadline; o erro é ignorado
+			_ = device.SetReadDeadline(time.Now().Add(b.timeout))
+		}
+
+		ev, err := ReadInputEvent(device)
+		if err != nil {
+			switch {
+			case errors.Is(err, os.ErrDeadlineExceeded):
+				if b.verbose {
+					log.Println("Timeout - nenhum código lido no intervalo")
+				}
+			case errors.Is(err, io.EOF), errors.Is(err, os.ErrClosed):
+				if b.verbose {
+					log.Println("Fim da leitura de eventos do dispositivo")
+				}
+			default:
+				log.Printf("Erro ao ler evento do dispositivo: %v", err)
+			}
 			return
-		case <-timer.C:
+		}
+
+		raw, complete := decoder.Feed(ev)
+		if !complete {
+			continue
+		}
+
+		b.currentISBN = raw
+		code, err := isbn.Normalize(raw)
+		if err != nil {
 			if b.verbose {
-				log.Println("Timeout - nenhum código lido no intervalo")
+				log.Printf("Código ignorado: %v", err)
 			}
+			continue
+		}
+
+		if b.verbose {
+			log.Printf("Barcode lido: %s", code)
+		}
+
+		select {
+		case b.eventChan <- ScanEvent{
+			ISBN:      code,
+			Raw:       raw,
+			Source:    b.devicePath,
+			Reader:    b.GetType(),
+			Timestamp: ev.Time,
+		}:
+		case <-b.stopChan:
+			return
+		case <-ctx.Done():
 			return
 		}
-	}()
+	}
+}
 
-	return nil
+// waitSimulated mantém o leitor ativo até parada, cancelamento ou timeout
+func (b *BarcodeReaderUSB) waitSimulated(ctx context.Context) {
+	var timeoutChan <-chan time.Time
+	if b.timeout > 0 {
+		timer := time.NewTimer(b.timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
+	select {
+	case <-b.stopChan:
+		if b.verbose {
+			log.Println("Leitor USB interrompido pelo usuário")
+		}
+	case <-ctx.Done():
+		if b.verbose {
+			log.Println("Contexto cancelado")
+		}
+	case <-timeoutChan:
+		if b.verbose {
+			log.Println("Timeout - nenhum código lido no intervalo")
+		}
+	}
 }
 
 // Stop para a leitura do scanner USB
@@ -94,9 +222,9 @@ func (b *BarcodeReaderUSB) Stop() error {
 	return nil
 }
 
-// Read retorna o canal de ISBNs
-func (b *BarcodeReaderUSB) Read() <-chan string {
-	return b.isbnChan
+// Read retorna o canal de leituras
+func (b *BarcodeReaderUSB) Read() <-chan ScanEvent {
+	return b.eventChan
 }
 
 // GetType retorna o tipo do leitor
@@ -110,25 +238,29 @@ func (b *BarcodeReaderUSB) IsRunning() bool {
 }
 
 // SimulateBarcodeScan simula uma leitura de barcode (para testes)
-func (b *BarcodeReaderUSB) SimulateBarcodeScan(isbn string) error {
+func (b *BarcodeReaderUSB) SimulateBarcodeScan(code string) error {
 	if !b.isRunning {
 		return fmt.Errorf("leitor não está ativo")
 	}
 
+	normalized, err := isbn.Normalize(code)
+	if err != nil {
+		return err
+	}
+
 	select {
-	case b.isbnChan <- isbn:
+	case b.eventChan <- ScanEvent{
+		ISBN:      normalized,
+		Raw:       code,
+		Source:    "simulação",
+		Reader:    b.GetType(),
+		Timestamp: time.Now(),
+	}:
 		if b.verbose {
-			log.Printf("Barcode simulado: %s", isbn)
+			log.Printf("Barcode simulado: %s", normalized)
 		}
 		return nil
 	case <-b.stopChan:
 		return fmt.Errorf("leitor foi parado")
 	}
 }
-
-// NotaImplementacao comenta sobre as bibliotecas necessárias
-// Para implementação real com captura de input do scanner:
-// 1. github.com/go-vgo/robotgo - simula/captura input de teclado global
-// 2. github.com/bendahl/uinput - acesso a eventos de dispositivo Linux
-// 3. github.com/ravernkoh/keyboardinput - input de teclado simples
-// 4. github.com/micmonay/keybd_event - simula eventos de teclado
@@ -0,0 +1,374 @@
+package reader
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// ColumnISBN é o campo lógico obrigatório do mapeamento de colunas
+const ColumnISBN = "isbn"
+
+// CSVISBNReader lê planilhas CSV/TSV (ex.: doações), extraindo a coluna de ISBN e
+// repassando as demais colunas como metadados do exemplar
+type CSVISBNReader struct {
+	filePath  string
+	delimiter string
+	header    string
+	columns   map[string]string
+	eventChan chan ScanEvent
+	stopChan  chan struct{}
+	isRunning bool
+	verbose   bool
+}
+
+// NewCSVISBNReader cria uma nova instância do leitor de planilhas
+func NewCSVISBNReader(config ReaderConfig) *CSVISBNReader {
+	header := strings.ToLower(config.Header)
+	if header == "" {
+		header = "auto"
+	}
+
+	// Campos lógicos são comparados sem diferenciar maiúsculas
+	columns := make(map[string]string, len(config.Columns))
+	for field, ref := range config.Columns {
+		columns[strings.ToLower(field)] = ref
+	}
+
+	return &CSVISBNReader{
+		filePath:  config.FilePath,
+		delimiter: config.Delimiter,
+		header:    header,
+		columns:   columns,
+		eventChan: make(chan ScanEvent, 100),
+		stopChan:  make(chan struct{}),
+		verbose:   config.Verbose,
+	}
+}
+
+// Start abre a planilha e inicia a leitura
+func (c *CSVISBNReader) Start(ctx context.Context) error {
+	if c.isRunning {
+		return fmt.Errorf("leitor de planilha já está ativo")
+	}
+
+	file, err := os.Open(c.filePath)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir planilha: %w", err)
+	}
+
+	comma, err := c.resolveDelimiter(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	c.isRunning = true
+
+	go func() {
+		defer func() {
+			file.Close()
+			c.isRunning = false
+			close(c.eventChan)
+		}()
+
+		if err := c.read(ctx, file, comma); err != nil {
+			log.Printf("Erro ao ler planilha %s: %v", c.filePath, err)
+		}
+	}()
+
+	return nil
+}
+
+// read percorre os registros da planilha e envia um evento por linha válida
+func (c *CSVISBNReader) read(ctx context.Context, file io.Reader, comma rune) error {
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	first, err := r.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	mapping, hasHeader, err := c.buildMapping(first)
+	if err != nil {
+		return err
+	}
+
+	if c.verbose {
+		log.Printf("Planilha %s: coluna de ISBN %d, cabeçalho: %v", filepath.Base(c.filePath), mapping[ColumnISBN]+1, hasHeader)
+	}
+
+	record := first
+	if hasHeader {
+		record = nil
+	}
+
+	count := 0
+	for {
+		if record == nil {
+			record, err = r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					log.Printf("Planilha linha %d: %v", parseErr.Line, parseErr.Err)
+					record = nil
+					continue
+				}
+				return err
+			}
+		}
+
+		line, _ := r.FieldPos(0)
+		event, ok := c.toEvent(record, mapping, line)
+		record = nil
+		if !ok {
+			continue
+		}
+
+		select {
+		case c.eventChan <- event:
+			count++
+		case <-c.stopChan:
+			if c.verbose {
+				log.Println("Leitura de planilha interrompida pelo usuário")
+			}
+			return nil
+		case <-ctx.Done():
+			if c.verbose {
+				log.Println("Contexto cancelado")
+			}
+			return nil
+		}
+	}
+
+	if c.verbose {
+		log.Printf("Total de %d ISBNs lidos da planilha", count)
+	}
+	return nil
+}
+
+// toEvent converte um registro em ScanEvent usando o mapeamento de colunas
+func (c *CSVISBNReader) toEvent(record []string, mapping map[string]int, line int) (ScanEvent, bool) {
+	raw := cell(record, mapping[ColumnISBN])
+	code, err := isbn.Normalize(raw)
+	if err != nil {
+		if c.verbose {
+			log.Printf("Planilha linha %d: %v", line, err)
+		}
+		return ScanEvent{}, false
+	}
+
+	metadata := make(map[string]string)
+	for field, idx := range mapping {
+		if field == ColumnISBN {
+			continue
+		}
+		if value := cell(record, idx); value != "" {
+			metadata[field] = value
+		}
+	}
+
+	event := ScanEvent{
+		ISBN:      code,
+		Raw:       raw,
+		Source:    c.filePath,
+		Line:      line,
+		Reader:    c.GetType(),
+		Timestamp: time.Now(),
+	}
+	if len(metadata) > 0 {
+		event.Metadata = metadata
+		event.Copy = true
+	}
+	return event, true
+}
+
+// buildMapping resolve o mapeamento de colunas (campo lógico -> índice) e
+// decide se a primeira linha é cabeçalho
+func (c *CSVISBNReader) buildMapping(first []string) (map[string]int, bool, error) {
+	hasHeader := c.header == "true" || c.header == "yes"
+
+	if len(c.columns) > 0 {
+		if _, ok := c.columns[ColumnISBN]; !ok {
+			return nil, false, fmt.Errorf("mapeamento de colunas sem o campo %q", ColumnISBN)
+		}
+
+		// Colunas referenciadas por nome exigem cabeçalho
+		if c.header == "auto" {
+			for _, ref := range c.columns {
+				if _, err := strconv.Atoi(ref); err != nil {
+					hasHeader = true
+					break
+				}
+			}
+		}
+
+		mapping := make(map[string]int, len(c.columns))
+		for field, ref := range c.columns {
+			idx, err := resolveColumn(ref, first, hasHeader)
+			if err != nil {
+				return nil, false, fmt.Errorf("campo %q: %w", field, err)
+			}
+			mapping[field] = idx
+		}
+
+		if c.header == "auto" && !hasHeader {
+			hasHeader = !isbn.IsValid(cell(first, mapping[ColumnISBN]))
+		}
+		return mapping, hasHeader, nil
+	}
+
+	// Sem mapeamento: localizar a coluna de ISBN pelo cabeçalho ou pelo conteúdo
+	isbnCol := -1
+	for i, value := range first {
+		if strings.EqualFold(strings.TrimSpace(value), ColumnISBN) {
+			isbnCol = i
+			if c.header == "auto" {
+				hasHeader = true
+			}
+			break
+		}
+	}
+	if isbnCol < 0 {
+		for i, value := range first {
+			if isbn.IsValid(value) {
+				isbnCol = i
+				break
+			}
+		}
+	}
+	if isbnCol < 0 {
+		return nil, false, fmt.Errorf("coluna de ISBN não encontrada; configure o mapeamento de colunas")
+	}
+
+	mapping := map[string]int{ColumnISBN: isbnCol}
+	for i, value := range first {
+		if i == isbnCol {
+			continue
+		}
+		name := fmt.Sprintf("col%d", i+1)
+		if hasHeader && strings.TrimSpace(value) != "" {
+			name = strings.ToLower(strings.TrimSpace(value))
+		}
+		mapping[name] = i
+	}
+	return mapping, hasHeader, nil
+}
+
+// resolveColumn converte uma referência de coluna (nome do cabeçalho ou índice
+// começando em 1) no índice do registro
+func resolveColumn(ref string, header []string, hasHeader bool) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("índice de coluna inválido: %d", n)
+		}
+		return n - 1, nil
+	}
+
+	if !hasHeader {
+		return 0, fmt.Errorf("coluna %q referenciada por nome, mas a planilha não tem cabeçalho", ref)
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(ref)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("coluna %q não encontrada no cabeçalho", ref)
+}
+
+// resolveDelimiter usa o delimitador configurado ou o detecta pela primeira linha
+func (c *CSVISBNReader) resolveDelimiter(file *os.File) (rune, error) {
+	switch strings.ToLower(c.delimiter) {
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	case "":
+	default:
+		runes := []rune(c.delimiter)
+		if len(runes) != 1 {
+			return 0, fmt.Errorf("delimitador inválido: %q", c.delimiter)
+		}
+		return runes[0], nil
+	}
+
+	if strings.EqualFold(filepath.Ext(c.filePath), ".tsv") {
+		return '\t', nil
+	}
+
+	// Detectar pelo caractere mais frequente na primeira linha não comentada
+	scanner := bufio.NewScanner(file)
+	var sample string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			sample = line
+			break
+		}
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("erro ao reposicionar planilha: %w", err)
+	}
+
+	best, count := ',', 0
+	for _, candidate := range []rune{',', ';', '\t'} {
+		if n := strings.Count(sample, string(candidate)); n > count {
+			best, count = candidate, n
+		}
+	}
+	return best, nil
+}
+
+// cell retorna o valor da coluna ou vazio se o registro for mais curto
+func cell(record []string, idx int) string {
+	if idx < len(record) {
+		return strings.TrimSpace(record[idx])
+	}
+	return ""
+}
+
+// Stop para a leitura da planilha
+func (c *CSVISBNReader) Stop() error {
+	if !c.isRunning {
+		return fmt.Errorf("leitor de planilha não está ativo")
+	}
+
+	close(c.stopChan)
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (c *CSVISBNReader) Read() <-chan ScanEvent {
+	return c.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (c *CSVISBNReader) GetType() string {
+	return "CSVISBNReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (c *CSVISBNReader) IsRunning() bool {
+	return c.isRunning
+}
@@ -0,0 +1,324 @@
+package reader
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readCSV grava a planilha em um diretório temporário e devolve todas as leituras
+func readCSV(t *testing.T, name, content string, config ReaderConfig) []ScanEvent {
+	t.Helper()
+	config.FilePath = filepath.Join(t.TempDir(), name)
+	writeFile(t, config.FilePath, content)
+
+	r := NewCSVISBNReader(config)
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var events []ScanEvent
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-r.Read():
+			if !ok {
+				return events
+			}
+			events = append(events, ev)
+		case <-timeout:
+			t.Fatal("leitura da planilha não terminou")
+		}
+	}
+}
+
+// csvRow é o resultado esperado de uma linha: ISBN, linha e metadados
+type csvRow struct {
+	isbn     string
+	line     int
+	metadata map[string]string
+}
+
+func TestCSVReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		config  ReaderConfig
+		want    []csvRow
+	}{
+		{
+			name:    "cabeçalho detectado pela coluna isbn",
+			file:    "doacoes.csv",
+			content: "Título,ISBN,Doador,Exemplares\nClean Code,978-0-13-235088-4,Maria,2\nHead First,0596007124,,\n",
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"título": "Clean Code", "doador": "Maria", "exemplares": "2"}},
+				{"9780596007126", 3, map[string]string{"título": "Head First"}},
+			},
+		},
+		{
+			name:    "sem cabeçalho, ISBN encontrado pelo conteúdo e ponto e vírgula detectado",
+			file:    "lista.csv",
+			content: "Maria;9780132350884\nJoão;0596007124\n",
+			want: []csvRow{
+				{"9780132350884", 1, map[string]string{"col1": "Maria"}},
+				{"9780596007126", 2, map[string]string{"col1": "João"}},
+			},
+		},
+		{
+			name:    "somente ISBN não gera exemplar",
+			file:    "lista.csv",
+			content: "9780132350884\n9780201633610\n",
+			want: []csvRow{
+				{"9780132350884", 1, nil},
+				{"9780201633610", 2, nil},
+			},
+		},
+		{
+			name:    "comentários e linhas em branco em TSV",
+			file:    "doacoes.tsv",
+			content: "# doações de março\nisbn\tdonor\n\n# revisar a próxima\n9780132350884\tAna, a bibliotecária\n",
+			want: []csvRow{
+				{"9780132350884", 5, map[string]string{"donor": "Ana, a bibliotecária"}},
+			},
+		},
+		{
+			name:    "tabulação detectada em arquivo .csv",
+			file:    "exportado.csv",
+			content: "isbn\tshelf\n9780132350884\tB2\n",
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"shelf": "B2"}},
+			},
+		},
+		{
+			name:    "mapeamento por nome com cabeçalho",
+			file:    "planilha.csv",
+			content: "Título,Código,Qtd,Obs\nClean Code,9780132350884,3,capa rasgada\n",
+			config:  ReaderConfig{Columns: map[string]string{"ISBN": "código", "copies": "Qtd"}},
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"copies": "3"}},
+			},
+		},
+		{
+			name:    "mapeamento por índice sem cabeçalho",
+			file:    "planilha.csv",
+			content: "Clean Code,9780132350884,3\nDesign Patterns,9780201633610,1\n",
+			config:  ReaderConfig{Columns: map[string]string{"isbn": "2", "copies": "3"}},
+			want: []csvRow{
+				{"9780132350884", 1, map[string]string{"copies": "3"}},
+				{"9780201633610", 2, map[string]string{"copies": "1"}},
+			},
+		},
+		{
+			name:    "mapeamento por índice detecta cabeçalho pelo conteúdo",
+			file:    "planilha.csv",
+			content: "Título,Código,Qtd\nClean Code,9780132350884,3\n",
+			config:  ReaderConfig{Columns: map[string]string{"isbn": "2", "copies": "3"}},
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"copies": "3"}},
+			},
+		},
+		{
+			name:    "header false força a primeira linha como dado",
+			file:    "planilha.csv",
+			content: "isbn,doador\n9780132350884,Maria\n",
+			config:  ReaderConfig{Header: "false", Columns: map[string]string{"isbn": "1", "donor": "2"}},
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"donor": "Maria"}},
+			},
+		},
+		{
+			name:    "linhas inválidas e registros curtos são ignorados",
+			file:    "planilha.csv",
+			content: "Maria,x\nAna,x,9780132350884,B2\nnão é isbn,x,123\nBia\n,,9780201633610\n",
+			config:  ReaderConfig{Header: "false", Columns: map[string]string{"isbn": "3", "donor": "1", "shelf": "4"}},
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"donor": "Ana", "shelf": "B2"}},
+				{"9780201633610", 5, nil},
+			},
+		},
+		{
+			name:    "delimitador configurado",
+			file:    "planilha.txt",
+			content: "isbn|donor\n9780132350884|Maria\n",
+			config:  ReaderConfig{Delimiter: "|"},
+			want: []csvRow{
+				{"9780132350884", 2, map[string]string{"donor": "Maria"}},
+			},
+		},
+		{
+			name:    "nome de coluna sem cabeçalho não produz leituras",
+			file:    "planilha.csv",
+			content: "9780132350884,Maria\n",
+			config:  ReaderConfig{Header: "false", Columns: map[string]string{"isbn": "ISBN"}},
+		},
+		{
+			name:    "planilha vazia",
+			file:    "vazia.csv",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := readCSV(t, tt.file, tt.content, tt.config)
+
+			if len(events) != len(tt.want) {
+				t.Fatalf("%d leituras, esperado %d: %+v", len(events), len(tt.want), events)
+			}
+			for i, want := range tt.want {
+				ev := events[i]
+				if ev.ISBN != want.isbn || ev.Line != want.line || ev.Reader != "CSVISBNReader" {
+					t.Errorf("leitura %d = %s (linha %d), esperado %s (linha %d)", i, ev.ISBN, ev.Line, want.isbn, want.line)
+				}
+				if !reflect.DeepEqual(ev.Metadata, want.metadata) {
+					t.Errorf("leitura %d: metadados = %v, esperado %v", i, ev.Metadata, want.metadata)
+				}
+				if ev.Copy != (want.metadata != nil) {
+					t.Errorf("leitura %d: Copy = %v", i, ev.Copy)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVBuildMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ReaderConfig
+		first      []string
+		want       map[string]int
+		wantHeader bool
+		wantErr    string
+	}{
+		{
+			name:       "cabeçalho com coluna isbn",
+			first:      []string{"Título", " ISBN ", "Doador"},
+			want:       map[string]int{"isbn": 1, "título": 0, "doador": 2},
+			wantHeader: true,
+		},
+		{
+			name:  "sem cabeçalho",
+			first: []string{"Maria", "9780132350884", ""},
+			want:  map[string]int{"isbn": 1, "col1": 0, "col3": 2},
+		},
+		{
+			name:       "header true sem coluna isbn usa o conteúdo",
+			config:     ReaderConfig{Header: "true"},
+			first:      []string{"Doador", "9780132350884"},
+			want:       map[string]int{"isbn": 1, "doador": 0},
+			wantHeader: true,
+		},
+		{
+			name:    "ISBN não encontrado",
+			first:   []string{"Título", "Doador"},
+			wantErr: "coluna de ISBN não encontrada",
+		},
+		{
+			name:    "mapeamento sem isbn",
+			config:  ReaderConfig{Columns: map[string]string{"donor": "2"}},
+			first:   []string{"9780132350884", "Maria"},
+			wantErr: `sem o campo "isbn"`,
+		},
+		{
+			name:    "nome de coluna sem cabeçalho",
+			config:  ReaderConfig{Header: "false", Columns: map[string]string{"isbn": "ISBN"}},
+			first:   []string{"9780132350884"},
+			wantErr: "a planilha não tem cabeçalho",
+		},
+		{
+			name:    "nome ausente no cabeçalho",
+			config:  ReaderConfig{Columns: map[string]string{"isbn": "ISBN", "donor": "Doador"}},
+			first:   []string{"ISBN", "Nome"},
+			wantErr: `coluna "Doador" não encontrada`,
+		},
+		{
+			name:    "índice zero",
+			config:  ReaderConfig{Columns: map[string]string{"isbn": "0"}},
+			first:   []string{"9780132350884"},
+			wantErr: "índice de coluna inválido",
+		},
+		{
+			name:       "nome força cabeçalho no modo auto",
+			config:     ReaderConfig{Columns: map[string]string{"isbn": "1", "donor": "Doador"}},
+			first:      []string{"ISBN", "Doador"},
+			want:       map[string]int{"isbn": 0, "donor": 1},
+			wantHeader: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, hasHeader, err := NewCSVISBNReader(tt.config).buildMapping(tt.first)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildMapping: %v", err)
+			}
+			if !reflect.DeepEqual(mapping, tt.want) || hasHeader != tt.wantHeader {
+				t.Errorf("mapeamento = %v (cabeçalho %v), esperado %v (cabeçalho %v)", mapping, hasHeader, tt.want, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestCSVResolveDelimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		delimiter string
+		content   string
+		want      rune
+		wantErr   bool
+	}{
+		{name: "tab por nome", file: "a.csv", delimiter: "tab", content: "a,b", want: '\t'},
+		{name: "tab escapado", file: "a.csv", delimiter: `\t`, content: "a,b", want: '\t'},
+		{name: "configurado", file: "a.csv", delimiter: ";", content: "a,b", want: ';'},
+		{name: "inválido", file: "a.csv", delimiter: "::", wantErr: true},
+		{name: "extensão .tsv", file: "a.TSV", content: "a,b,c", want: '\t'},
+		{name: "vírgula", file: "a.csv", content: "a,b;c,d", want: ','},
+		{name: "ponto e vírgula", file: "a.csv", content: "a;b;c,d", want: ';'},
+		{name: "tab detectado", file: "a.txt", content: "a\tb\tc", want: '\t'},
+		{name: "ignora comentários", file: "a.csv", content: "# a,b,c,d\n\nx;y\n", want: ';'},
+		{name: "sem delimitador", file: "a.csv", content: "9780132350884\n", want: ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			r := NewCSVISBNReader(ReaderConfig{FilePath: path, Delimiter: tt.delimiter})
+			got, err := r.resolveDelimiter(file)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("esperado erro")
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("delimitador = %q, %v; esperado %q", got, err, tt.want)
+			}
+
+			// A detecção não pode consumir o início do arquivo
+			rest, _ := io.ReadAll(file)
+			if string(rest) != tt.content {
+				t.Errorf("arquivo não reposicionado: %q", rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package reader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos e códigos de evento do subsistema input do Linux (linux/input-event-codes.h)
+const (
+	evKey = 0x01
+
+	keyReleased = 0
+	keyPressed  = 1
+	keyRepeated = 2
+
+	keyEnter      = 28
+	keyKPEnter    = 96
+	keyLeftShift  = 42
+	keyRightShift = 54
+	keyCapsLock   = 58
+)
+
+// InputEvent representa um struct input_event lido de /dev/input/eventN
+type InputEvent struct {
+	Time  time.Time
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// InputEventSize é o tamanho em bytes de um input_event na arquitetura atual
+// (timeval de 16 bytes em 64 bits, 8 bytes em 32 bits, mais type/code/value)
+var InputEventSize = 2*strconv.IntSize/8 + 8
+
+// ReadInputEvent lê e decodifica um único input_event do reader
+func ReadInputEvent(r io.Reader) (InputEvent, error) {
+	buf := make([]byte, InputEventSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return InputEvent{}, err
+	}
+	return decodeInputEvent(buf)
+}
+
+// decodeInputEvent converte os bytes brutos de um input_event
+func decodeInputEvent(buf []byte) (InputEvent, error) {
+	var sec, usec int64
+	var off int
+
+	switch len(buf) {
+	case 24:
+		sec = int64(binary.LittleEndian.Uint64(buf[0:8]))
+		usec = int64(binary.LittleEndian.Uint64(buf[8:16]))
+		off = 16
+	case 16:
+		sec = int64(int32(binary.LittleEndian.Uint32(buf[0:4])))
+		usec = int64(int32(binary.LittleEndian.Uint32(buf[4:8])))
+		off = 8
+	default:
+		return InputEvent{}, fmt.Errorf("tamanho de input_event inválido: %d bytes", len(buf))
+	}
+
+	return InputEvent{
+		Time:  time.Unix(sec, usec*1000),
+		Type:  binary.LittleEndian.Uint16(buf[off : off+2]),
+		Code:  binary.LittleEndian.Uint16(buf[off+2 : off+4]),
+		Value: int32(binary.LittleEndian.Uint32(buf[off+4 : off+8])),
+	}, nil
+}
+
+// keyMap mapeia códigos de tecla para caracteres (sem shift, com shift)
+var keyMap = map[uint16][2]rune{
+	2: {'1', '!'}, 3: {'2', '@'}, 4: {'3', '#'}, 5: {'4', '$'}, 6: {'5', '%'},
+	7: {'6', '^'}, 8: {'7', '&'}, 9: {'8', '*'}, 10: {'9', '('}, 11: {'0', ')'},
+	12: {'-', '_'}, 13: {'=', '+'},
+	16: {'q', 'Q'}, 17: {'w', 'W'}, 18: {'e', 'E'}, 19: {'r', 'R'}, 20: {'t', 'T'},
+	21: {'y', 'Y'}, 22: {'u', 'U'}, 23: {'i', 'I'}, 24: {'o', 'O'}, 25: {'p', 'P'},
+	26: {'[', '{'}, 27: {']', '}'},
+	30: {'a', 'A'}, 31: {'s', 'S'}, 32: {'d', 'D'}, 33: {'f', 'F'}, 34: {'g', 'G'},
+	35: {'h', 'H'}, 36: {'j', 'J'}, 37: {'k', 'K'}, 38: {'l', 'L'},
+	39: {';', ':'}, 40: {'\'', '"'}, 41: {'`', '~'}, 43: {'\\', '|'},
+	44: {'z', 'Z'}, 45: {'x', 'X'}, 46: {'c', 'C'}, 47: {'v', 'V'}, 48: {'b', 'B'},
+	49: {'n', 'N'}, 50: {'m', 'M'},
+	51: {',', '<'}, 52: {'.', '>'}, 53: {'/', '?'}, 57: {' ', ' '},
+	// Teclado numérico
+	71: {'7', '7'}, 72: {'8', '8'}, 73: {'9', '9'}, 74: {'-', '-'},
+	75: {'4', '4'}, 76: {'5', '5'}, 77: {'6', '6'}, 78: {'+', '+'},
+	79: {'1', '1'}, 80: {'2', '2'}, 81: {'3', '3'}, 82: {'0', '0'}, 83: {'.', '.'},
+}
+
+// keyDecoder monta os caracteres digitados pelo scanner até receber Enter
+type keyDecoder struct {
+	shift    bool
+	capsLock bool
+	buf      strings.Builder
+}
+
+// Feed processa um evento e retorna o código completo quando Enter é pressionado
+func (d *keyDecoder) Feed(ev InputEvent) (string, bool) {
+	if ev.Type != evKey {
+		return "", false
+	}
+
+	switch ev.Code {
+	case keyLeftShift, keyRightShift:
+		d.shift = ev.Value != keyReleased
+		return "", false
+	case keyCapsLock:
+		if ev.Value == keyPressed {
+			d.capsLock = !d.capsLock
+		}
+		return "", false
+	}
+
+	if ev.Value != keyPressed && ev.Value != keyRepeated {
+		return "", false
+	}
+
+	if ev.Code == keyEnter || ev.Code == keyKPEnter {
+		code := d.buf.String()
+		d.buf.Reset()
+		return code, true
+	}
+
+	chars, ok := keyMap[ev.Code]
+	if !ok {
+		return "", false
+	}
+
+	upper := d.shift
+	if chars[0] >= 'a' && chars[0] <= 'z' && d.capsLock {
+		upper = !upper
+	}
+
+	if upper {
+		d.buf.WriteRune(chars[1])
+	} else {
+		d.buf.WriteRune(chars[0])
+	}
+	return "", false
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package reader
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// eviocgrab é o ioctl EVIOCGRAB (_IOW('E', 0x90, int))
+const eviocgrab = 0x40044590
+
+// grabDevice solicita acesso exclusivo ao dispositivo, impedindo que os
+// códigos lidos também sejam digitados em outras aplicações
+func grabDevice(f *os.File, grab bool) error {
+	var arg uintptr
+	if grab {
+		arg = 1
+	}
+
+	// SyscallConn preserva o modo não bloqueante (f.Fd() o desativaria)
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("erro ao acessar descritor de %s: %w", f.Name(), err)
+	}
+
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, eviocgrab, arg)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao acessar descritor de %s: %w", f.Name(), err)
+	}
+	if errno != 0 {
+		return fmt.Errorf("erro ao executar EVIOCGRAB em %s: %w", f.Name(), errno)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package reader
+
+import (
+	"fmt"
+	"os"
+)
+
+// grabDevice não é suportado fora do Linux
+func grabDevice(f *os.File, grab bool) error {
+	return fmt.Errorf("EVIOCGRAB não suportado nesta plataforma")
+}
@@ -0,0 +1,162 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// encodeInputEvent monta os bytes de um input_event no formato da arquitetura atual
+func encodeInputEvent(typ, code uint16, value int32) []byte {
+	buf := make([]byte, InputEventSize)
+	off := InputEventSize - 8
+	if off == 16 {
+		binary.LittleEndian.PutUint64(buf[0:8], 1700000000)
+		binary.LittleEndian.PutUint64(buf[8:16], 250000)
+	} else {
+		binary.LittleEndian.PutUint32(buf[0:4], 1700000000)
+		binary.LittleEndian.PutUint32(buf[4:8], 250000)
+	}
+	binary.LittleEndian.PutUint16(buf[off:off+2], typ)
+	binary.LittleEndian.PutUint16(buf[off+2:off+4], code)
+	binary.LittleEndian.PutUint32(buf[off+4:off+8], uint32(value))
+	return buf
+}
+
+// key é um atalho para um evento de tecla
+type key struct {
+	code  uint16
+	value int32
+}
+
+// press gera pressionar e soltar de cada tecla
+func press(codes ...uint16) []key {
+	var keys []key
+	for _, c := range codes {
+		keys = append(keys, key{c, keyPressed}, key{c, keyReleased})
+	}
+	return keys
+}
+
+func TestDecodeInputEvent(t *testing.T) {
+	ev, err := ReadInputEvent(bytes.NewReader(encodeInputEvent(evKey, keyEnter, keyPressed)))
+	if err != nil {
+		t.Fatalf("ReadInputEvent: %v", err)
+	}
+	if ev.Type != evKey || ev.Code != keyEnter || ev.Value != keyPressed {
+		t.Errorf("evento = %+v", ev)
+	}
+	if want := time.Unix(1700000000, 250000*1000); !ev.Time.Equal(want) {
+		t.Errorf("Time = %v, esperado %v", ev.Time, want)
+	}
+
+	if _, err := decodeInputEvent(make([]byte, 20)); err == nil {
+		t.Error("esperado erro para tamanho inválido")
+	}
+
+	// Formato de 32 bits: timeval de 8 bytes
+	buf := make([]byte, 16)
+	binary.LittleEndian.PutUint16(buf[8:10], evKey)
+	binary.LittleEndian.PutUint16(buf[10:12], 30)
+	binary.LittleEndian.PutUint32(buf[12:16], keyRepeated)
+	ev, err = decodeInputEvent(buf)
+	if err != nil {
+		t.Fatalf("decodeInputEvent(16 bytes): %v", err)
+	}
+	if ev.Code != 30 || ev.Value != keyRepeated {
+		t.Errorf("evento de 32 bits = %+v", ev)
+	}
+}
+
+func TestKeyDecoder(t *testing.T) {
+	// 9780132350884 nas teclas numéricas da linha superior
+	digits := []uint16{10, 8, 9, 11, 2, 4, 3, 4, 6, 11, 9, 9, 5}
+
+	cat := func(parts ...[]key) []key {
+		var all []key
+		for _, p := range parts {
+			all = append(all, p...)
+		}
+		return all
+	}
+
+	tests := []struct {
+		name  string
+		keys  []key
+		codes []string
+	}{
+		{
+			name:  "dígitos e Enter",
+			keys:  cat(press(digits...), press(keyEnter)),
+			codes: []string{"9780132350884"},
+		},
+		{
+			name:  "Enter do teclado numérico",
+			keys:  cat(press(79, 80, 81), press(keyKPEnter)),
+			codes: []string{"123"},
+		},
+		{
+			name: "shift pressionado e solto",
+			keys: cat(
+				[]key{{keyLeftShift, keyPressed}}, press(45), []key{{keyLeftShift, keyReleased}},
+				press(45, 3),
+				[]key{{keyRightShift, keyPressed}}, press(3), []key{{keyRightShift, keyReleased}},
+				press(keyEnter),
+			),
+			codes: []string{"Xx2@"},
+		},
+		{
+			name:  "caps lock inverte só letras",
+			keys:  cat(press(keyCapsLock, 45, 2), []key{{keyLeftShift, keyPressed}}, press(45), []key{{keyLeftShift, keyReleased}}, press(keyEnter)),
+			codes: []string{"X1x"},
+		},
+		{
+			name:  "repetição de tecla gera caractere; soltar não",
+			keys:  []key{{2, keyPressed}, {2, keyRepeated}, {2, keyRepeated}, {2, keyReleased}, {keyEnter, keyPressed}},
+			codes: []string{"111"},
+		},
+		{
+			name:  "Enter repetido entrega código vazio",
+			keys:  []key{{3, keyPressed}, {3, keyReleased}, {keyEnter, keyPressed}, {keyEnter, keyRepeated}, {keyEnter, keyReleased}},
+			codes: []string{"2", ""},
+		},
+		{
+			name:  "vários códigos e teclas desconhecidas",
+			keys:  cat(press(2, 1, 3), press(keyEnter), press(4), press(keyEnter)),
+			codes: []string{"12", "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input bytes.Buffer
+			for _, k := range tt.keys {
+				// Eventos EV_SYN entre as teclas, como o kernel envia
+				input.Write(encodeInputEvent(evKey, k.code, k.value))
+				input.Write(encodeInputEvent(0, 0, 0))
+			}
+
+			var decoder keyDecoder
+			var codes []string
+			for input.Len() > 0 {
+				ev, err := ReadInputEvent(&input)
+				if err != nil {
+					t.Fatalf("ReadInputEvent: %v", err)
+				}
+				if code, ok := decoder.Feed(ev); ok {
+					codes = append(codes, code)
+				}
+			}
+
+			if len(codes) != len(tt.codes) {
+				t.Fatalf("códigos = %q, esperado %q", codes, tt.codes)
+			}
+			for i := range codes {
+				if codes[i] != tt.codes[i] {
+					t.Errorf("código %d = %q, esperado %q", i, codes[i], tt.codes[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// ScanEvent representa uma leitura de ISBN junto com sua origem
+type ScanEvent struct {
+	ID        string            // identificador atribuído pelo leitor (ex.: leitura HTTP)
+	ISBN      string            // ISBN-13 normalizado
+	Raw       string            // valor exatamente como foi lido
+	Source    string            // arquivo, dispositivo ou endereço de origem
+	Line      int               // número da linha (leitores de arquivo), 0 se não se aplica
+	Reader    string            // tipo do leitor (GetType)
+	Timestamp time.Time         // instante da leitura
+	Metadata  map[string]string // dados adicionais da leitura (ex.: add-on EAN-5)
+	Copy      bool              // a leitura registra um exemplar com os dados de Metadata (ex.: planilha de doações)
+
+	route []int // leitores filhos de MultiReader por onde a leitura passou, para Ack
+}
+
+// Origin descreve de onde veio a leitura, para relatórios de erro
+// (ex.: "linha 7 de isbn_list.txt", "scanner /dev/input/event3 às 14:02:11")
+func (e ScanEvent) Origin() string {
+	if e.Line > 0 && e.Source != "" {
+		return fmt.Sprintf("linha %d de %s", e.Line, filepath.Base(e.Source))
+	}
+
+	at := e.Timestamp.Format("15:04:05")
+	kind := readerKinds[e.Reader]
+
+	switch {
+	case kind != "" && e.Source != "":
+		return fmt.Sprintf("%s %s às %s", kind, e.Source, at)
+	case e.Source != "":
+		return fmt.Sprintf("%s às %s", e.Source, at)
+	case kind != "":
+		return fmt.Sprintf("%s às %s", kind, at)
+	case e.Reader != "":
+		return fmt.Sprintf("%s às %s", e.Reader, at)
+	default:
+		return at
+	}
+}
+
+// readerKinds dá nomes amigáveis aos tipos de leitor usados em Origin
+var readerKinds = map[string]string{
+	"BarcodeReaderUSB": "scanner",
+	"SerialISBNReader": "scanner",
+	"StdinISBNReader":  "terminal",
+	"ImageISBNReader":  "imagem",
+	"HotFolderReader":  "arquivo",
+	"HTTPISBNReader":   "leitura HTTP",
+}
+
+// StringISBNReader é a forma anterior da interface, em que Read entrega apenas
+// os ISBNs. Leitores que a implementam podem ser usados via AdaptStringReader.
+type StringISBNReader interface {
+	Start(ctx context.Context) error
+	Stop() error
+	Read() <-chan string
+	GetType() string
+	IsRunning() bool
+}
+
+// stringReaderAdapter converte os ISBNs de um StringISBNReader em ScanEvents
+type stringReaderAdapter struct {
+	inner  StringISBNReader
+	events chan ScanEvent
+}
+
+// AdaptStringReader permite usar um StringISBNReader onde se espera um ISBNReader
+func AdaptStringReader(r StringISBNReader) ISBNReader {
+	return &stringReaderAdapter{
+		inner:  r,
+		events: make(chan ScanEvent, 100),
+	}
+}
+
+// Start inicia o leitor adaptado e a conversão dos ISBNs em eventos
+func (a *stringReaderAdapter) Start(ctx context.Context) error {
+	if err := a.inner.Start(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(a.events)
+
+		codes := a.inner.Read()
+		for {
+			var raw string
+			select {
+			case code, ok := <-codes:
+				if !ok {
+					return
+				}
+				raw = code
+			case <-ctx.Done():
+				return
+			}
+
+			event := ScanEvent{
+				ISBN:      raw,
+				Raw:       raw,
+				Reader:    a.inner.GetType(),
+				Timestamp: time.Now(),
+			}
+			if code, err := isbn.Normalize(raw); err == nil {
+				event.ISBN = code
+			}
+
+			select {
+			case a.events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop para o leitor adaptado
+func (a *stringReaderAdapter) Stop() error {
+	return a.inner.Stop()
+}
+
+// Read retorna o canal de eventos
+func (a *stringReaderAdapter) Read() <-chan ScanEvent {
+	return a.events
+}
+
+// GetType retorna o tipo do leitor adaptado
+func (a *stringReaderAdapter) GetType() string {
+	return a.inner.GetType()
+}
+
+// IsRunning indica se o leitor adaptado está ativo
+func (a *stringReaderAdapter) IsRunning() bool {
+	return a.inner.IsRunning()
+}
@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// stringReader é um leitor no formato anterior da interface, que entrega
+// apenas os ISBNs como strings
+type stringReader struct {
+	codes   chan string
+	running bool
+	stopped bool
+}
+
+func newStringReader(codes ...string) *stringReader {
+	r := &stringReader{codes: make(chan string, len(codes))}
+	for _, c := range codes {
+		r.codes <- c
+	}
+	close(r.codes)
+	return r
+}
+
+func (r *stringReader) Start(ctx context.Context) error { r.running = true; return nil }
+func (r *stringReader) Stop() error                     { r.stopped = true; return nil }
+func (r *stringReader) Read() <-chan string             { return r.codes }
+func (r *stringReader) GetType() string                 { return "LegacyReader" }
+func (r *stringReader) IsRunning() bool                 { return r.running }
+
+func TestAdaptStringReader(t *testing.T) {
+	inner := newStringReader("0-13-235088-2", "9780201633610", "não é isbn")
+	adapted := AdaptStringReader(inner)
+
+	before := time.Now()
+	if err := adapted.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !adapted.IsRunning() || adapted.GetType() != "LegacyReader" {
+		t.Errorf("IsRunning = %v, GetType = %q", adapted.IsRunning(), adapted.GetType())
+	}
+
+	var events []ScanEvent
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case ev, ok := <-adapted.Read():
+			if !ok {
+				done = true
+				break
+			}
+			events = append(events, ev)
+		case <-timeout:
+			t.Fatal("canal de eventos não foi fechado após o fim do leitor adaptado")
+		}
+	}
+
+	want := []struct{ isbn, raw string }{
+		{"9780132350884", "0-13-235088-2"},
+		{"9780201633610", "9780201633610"},
+		// Valores inválidos seguem como lidos; o processador os rejeita
+		{"não é isbn", "não é isbn"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("%d eventos, esperado %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		ev := events[i]
+		if ev.ISBN != w.isbn || ev.Raw != w.raw || ev.Reader != "LegacyReader" || ev.Timestamp.Before(before) {
+			t.Errorf("evento %d = %+v, esperado ISBN %q e Raw %q", i, ev, w.isbn, w.raw)
+		}
+	}
+
+	if err := adapted.Stop(); err != nil || !inner.stopped {
+		t.Errorf("Stop não repassado ao leitor adaptado: %v", err)
+	}
+}
+
+func TestAdaptStringReaderCanceled(t *testing.T) {
+	codes := make(chan string)
+	inner := &stringReader{codes: codes}
+	adapted := AdaptStringReader(inner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := adapted.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	// Leitor adaptado que nunca termina: o cancelamento encerra a conversão
+	select {
+	case _, ok := <-adapted.Read():
+		if ok {
+			t.Error("evento inesperado")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("canal não foi fechado após o cancelamento")
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"time"
+
+	"leitor-usbn/isbn"
 )
 
 // FileISBNReader lê ISBNs de um arquivo de texto
 type FileISBNReader struct {
 	filePath  string
-	isbnChan  chan string
+	input     io.Reader // conteúdo já aberto; nil lê filePath
+	eventChan chan ScanEvent
 	stopChan  chan struct{}
 	isRunning bool
 	verbose   bool
@@ -21,13 +26,22 @@ type FileISBNReader struct {
 // NewFileISBNReader cria uma nova instância do leitor de arquivo
 func NewFileISBNReader(config ReaderConfig) *FileISBNReader {
 	return &FileISBNReader{
-		filePath: config.FilePath,
-		isbnChan: make(chan string, 100), // buffer para evitar bloqueios
-		stopChan: make(chan struct{}),
-		verbose:  config.Verbose,
+		filePath:  config.FilePath,
+		eventChan: make(chan ScanEvent, 100), // buffer para evitar bloqueios
+		stopChan:  make(chan struct{}),
+		verbose:   config.Verbose,
 	}
 }
 
+// NewFileISBNReaderWithInput cria um leitor que consome o io.Reader informado
+// (ex.: lista enviada pela interface web); config.FilePath nomeia a origem
+// das leituras
+func NewFileISBNReaderWithInput(input io.Reader, config ReaderConfig) *FileISBNReader {
+	f := NewFileISBNReader(config)
+	f.input = input
+	return f
+}
+
 // Start inicia a leitura do arquivo
 func (f *FileISBNReader) Start(ctx context.Context) error {
 	if f.isRunning {
@@ -39,17 +53,21 @@ func (f *FileISBNReader) Start(ctx context.Context) error {
 	go func() {
 		defer func() {
 			f.isRunning = false
-			close(f.isbnChan)
+			close(f.eventChan)
 		}()
 
-		file, err := os.Open(f.filePath)
-		if err != nil {
-			log.Printf("Erro ao abrir arquivo: %v", err)
-			return
+		input := f.input
+		if input == nil {
+			file, err := os.Open(f.filePath)
+			if err != nil {
+				log.Printf("Erro ao abrir arquivo: %v", err)
+				return
+			}
+			defer file.Close()
+			input = file
 		}
-		defer file.Close()
 
-		scanner := bufio.NewScanner(file)
+		scanner := bufio.NewScanner(input)
 		lineNumber := 0
 
 		for scanner.Scan() {
@@ -75,21 +93,29 @@ func (f *FileISBNReader) Start(ctx context.Context) error {
 				continue
 			}
 
-			// Validação básica de ISBN
-			if len(line) < 10 {
+			// Validar e normalizar para ISBN-13
+			code, err := isbn.Normalize(line)
+			if err != nil {
 				if f.verbose {
-					log.Printf("Linha %d: ISBN inválido (muito curto): %s", lineNumber, line)
+					log.Printf("Linha %d: %v", lineNumber, err)
 				}
 				continue
 			}
 
 			if f.verbose {
-				log.Printf("Linha %d: ISBN lido: %s", lineNumber, line)
+				log.Printf("Linha %d: ISBN lido: %s", lineNumber, code)
 			}
 
 			// Enviar ISBN pelo canal
 			select {
-			case f.isbnChan <- line:
+			case f.eventChan <- ScanEvent{
+				ISBN:      code,
+				Raw:       line,
+				Source:    f.filePath,
+				Line:      lineNumber,
+				Reader:    f.GetType(),
+				Timestamp: time.Now(),
+			}:
 			case <-f.stopChan:
 				return
 			case <-ctx.Done():
@@ -120,9 +146,9 @@ func (f *FileISBNReader) Stop() error {
 	return nil
 }
 
-// Read retorna o canal de ISBNs
-func (f *FileISBNReader) Read() <-chan string {
-	return f.isbnChan
+// Read retorna o canal de leituras
+func (f *FileISBNReader) Read() <-chan ScanEvent {
+	return f.eventChan
 }
 
 // GetType retorna o tipo do leitor
@@ -0,0 +1,404 @@
+package reader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// Subdiretórios da pasta monitorada: o arquivo lido fica em processing/ até
+// que todos os seus ISBNs tenham resultado e então vai para processed/ ou failed/
+const (
+	processingDir = "processing"
+	processedDir  = "processed"
+	failedDir     = "failed"
+)
+
+// fileState guarda tamanho e data de modificação para detectar arquivos ainda em escrita
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// batch acompanha os ISBNs de um arquivo que ainda aguardam resultado
+type batch struct {
+	path      string // arquivo em processing/ (vazio se não pôde ser movido)
+	total     int
+	remaining int
+	failed    int
+}
+
+// HotFolderReader monitora um diretório e lê cada arquivo .txt/.csv depositado nele.
+// O arquivo vai para processing/ ao ser lido e, quando o processador confirma
+// (Ack) todos os seus ISBNs, para processed/; se nenhum ISBN foi consultado com
+// sucesso ou o arquivo não tem ISBNs válidos, para failed/.
+type HotFolderReader struct {
+	dirPath      string
+	pollInterval time.Duration
+	eventChan    chan ScanEvent
+	stopChan     chan struct{}
+	isRunning    bool
+	verbose      bool
+	pending      map[string]fileState
+	mu           sync.Mutex
+	batches      map[string]*batch // por caminho original do arquivo
+	stuck        map[string]bool   // arquivos que não puderam ser movidos
+}
+
+// NewHotFolderReader cria uma nova instância do leitor de pasta monitorada
+func NewHotFolderReader(config ReaderConfig) *HotFolderReader {
+	interval := time.Duration(config.PollInterval) * time.Second
+	if config.PollInterval == 0 {
+		interval = 2 * time.Second
+	}
+
+	return &HotFolderReader{
+		dirPath:      config.WatchDir,
+		pollInterval: interval,
+		eventChan:    make(chan ScanEvent, 100),
+		stopChan:     make(chan struct{}),
+		verbose:      config.Verbose,
+		pending:      make(map[string]fileState),
+		batches:      make(map[string]*batch),
+		stuck:        make(map[string]bool),
+	}
+}
+
+// Start cria os subdiretórios e inicia o monitoramento
+func (h *HotFolderReader) Start(ctx context.Context) error {
+	if h.isRunning {
+		return fmt.Errorf("leitor de pasta monitorada já está ativo")
+	}
+
+	if h.dirPath == "" {
+		return fmt.Errorf("diretório monitorado não configurado")
+	}
+
+	for _, sub := range []string{processingDir, processedDir, failedDir} {
+		if err := os.MkdirAll(filepath.Join(h.dirPath, sub), 0o755); err != nil {
+			return fmt.Errorf("erro ao criar diretório %s: %w", sub, err)
+		}
+	}
+	h.requeue()
+
+	h.isRunning = true
+
+	if h.verbose {
+		log.Printf("=== Monitorando diretório %s (a cada %v) ===", h.dirPath, h.pollInterval)
+	}
+
+	go func() {
+		defer func() {
+			h.isRunning = false
+			close(h.eventChan)
+		}()
+
+		ticker := time.NewTicker(h.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if !h.poll(ctx) {
+				return
+			}
+
+			select {
+			case <-h.stopChan:
+				if h.verbose {
+					log.Println("Monitoramento interrompido pelo usuário")
+				}
+				return
+			case <-ctx.Done():
+				if h.verbose {
+					log.Println("Contexto cancelado")
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// poll lê os arquivos que não mudaram desde a última varredura.
+// Retorna false se o leitor foi parado.
+func (h *HotFolderReader) poll(ctx context.Context) bool {
+	entries, err := os.ReadDir(h.dirPath)
+	if err != nil {
+		log.Printf("Erro ao listar diretório %s: %v", h.dirPath, err)
+		return true
+	}
+
+	var ready []string
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isListFile(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(h.dirPath, entry.Name())
+		h.mu.Lock()
+		skip := h.stuck[path] || h.batches[path] != nil
+		h.mu.Unlock()
+		if skip {
+			continue
+		}
+		seen[path] = true
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+
+		// Só ler quando tamanho e data não mudarem entre duas varreduras
+		if previous, ok := h.pending[path]; ok && previous == state {
+			ready = append(ready, path)
+			delete(h.pending, path)
+			continue
+		}
+		h.pending[path] = state
+	}
+
+	// Esquecer arquivos removidos por terceiros
+	for path := range h.pending {
+		if !seen[path] {
+			delete(h.pending, path)
+		}
+	}
+
+	sort.Strings(ready)
+	for _, path := range ready {
+		if !h.ingest(ctx, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// ingest move o arquivo para processing/ e envia seus ISBNs; arquivos sem
+// ISBNs válidos vão direto para failed/.
+// Retorna false se o leitor foi parado durante o envio.
+func (h *HotFolderReader) ingest(ctx context.Context, path string) bool {
+	events, err := readListFile(path)
+	if err == nil && len(events) == 0 {
+		err = fmt.Errorf("nenhum ISBN válido encontrado")
+	}
+
+	h.mu.Lock()
+	if err != nil {
+		h.mu.Unlock()
+		log.Printf("Arquivo %s rejeitado: %v", path, err)
+		h.move(path, failedDir)
+		return true
+	}
+	b := &batch{total: len(events), remaining: len(events)}
+	h.batches[path] = b
+	h.mu.Unlock()
+
+	b.path = h.move(path, processingDir)
+
+	if h.verbose {
+		log.Printf("Arquivo %s: %d ISBN(s)", path, len(events))
+	}
+
+	for _, event := range events {
+		select {
+		case h.eventChan <- event:
+		case <-h.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// Ack registra o resultado de um ISBN lido pela pasta monitorada e, quando
+// todos os ISBNs do arquivo têm resultado, move-o para processed/ ou failed/
+func (h *HotFolderReader) Ack(event ScanEvent, success bool) {
+	if event.Reader != h.GetType() {
+		return
+	}
+
+	h.mu.Lock()
+	b := h.batches[event.Source]
+	if b == nil {
+		h.mu.Unlock()
+		return
+	}
+	b.remaining--
+	if !success {
+		b.failed++
+	}
+	if b.remaining > 0 {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.batches, event.Source)
+	h.mu.Unlock()
+
+	if b.failed > 0 {
+		log.Printf("Arquivo %s: %d de %d ISBN(s) com erro", event.Source, b.failed, b.total)
+	}
+	if b.path == "" {
+		return
+	}
+	if b.failed == b.total {
+		h.move(b.path, failedDir)
+	} else {
+		h.move(b.path, processedDir)
+	}
+}
+
+// requeue devolve à pasta monitorada os arquivos deixados em processing/ por
+// uma execução interrompida, para que sejam lidos de novo
+func (h *HotFolderReader) requeue() {
+	entries, err := os.ReadDir(filepath.Join(h.dirPath, processingDir))
+	if err != nil {
+		log.Printf("Erro ao listar %s: %v", processingDir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		from := filepath.Join(h.dirPath, processingDir, entry.Name())
+		to := filepath.Join(h.dirPath, entry.Name())
+		if _, err := os.Stat(to); err == nil {
+			log.Printf("Arquivo %s não retomado: já existe %s", from, to)
+			continue
+		}
+		if err := os.Rename(from, to); err != nil {
+			log.Printf("Erro ao retomar %s: %v", from, err)
+			continue
+		}
+		if h.verbose {
+			log.Printf("Arquivo %s retomado de uma execução interrompida", to)
+		}
+	}
+}
+
+// move transfere o arquivo para o subdiretório, evitando sobrescrever arquivos
+// existentes. Retorna o novo caminho (vazio se o arquivo não pôde ser movido).
+func (h *HotFolderReader) move(path, sub string) string {
+	name := filepath.Base(path)
+	dest := filepath.Join(h.dirPath, sub, name)
+
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(name)
+		stamp := time.Now().Format("20060102-150405")
+		dest = filepath.Join(h.dirPath, sub, fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), stamp, ext))
+	}
+
+	if err := os.Rename(path, dest); err != nil {
+		log.Printf("Erro ao mover %s para %s: %v", path, sub, err)
+		h.mu.Lock()
+		h.stuck[path] = true
+		h.mu.Unlock()
+		return ""
+	}
+
+	if h.verbose {
+		log.Printf("Arquivo movido para %s", dest)
+	}
+	return dest
+}
+
+// readListFile lê os ISBNs válidos de um arquivo .txt (um por linha) ou .csv
+// (primeiro campo que for um ISBN válido)
+func readListFile(path string) ([]ScanEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	defer file.Close()
+
+	var events []ScanEvent
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ';' || r == '\t'
+		})
+
+		valid := false
+		for _, field := range fields {
+			code, err := isbn.Normalize(strings.Trim(field, `" `))
+			if err == nil {
+				events = append(events, ScanEvent{
+					ISBN:      code,
+					Raw:       line,
+					Source:    path,
+					Line:      lineNumber,
+					Reader:    "HotFolderReader",
+					Timestamp: time.Now(),
+				})
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			log.Printf("%s linha %d: nenhum ISBN válido: %s", filepath.Base(path), lineNumber, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	return events, nil
+}
+
+// isListFile indica se o arquivo tem extensão .txt ou .csv
+func isListFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".txt", ".csv":
+		return true
+	}
+	return false
+}
+
+// Stop para o monitoramento
+func (h *HotFolderReader) Stop() error {
+	if !h.isRunning {
+		return fmt.Errorf("leitor de pasta monitorada não está ativo")
+	}
+
+	close(h.stopChan)
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (h *HotFolderReader) Read() <-chan ScanEvent {
+	return h.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (h *HotFolderReader) GetType() string {
+	return "HotFolderReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (h *HotFolderReader) IsRunning() bool {
+	return h.isRunning
+}
@@ -0,0 +1,129 @@
+package reader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestHotFolder cria um leitor sobre um diretório temporário com varredura rápida
+func newTestHotFolder(t *testing.T) (*HotFolderReader, string) {
+	t.Helper()
+	dir := t.TempDir()
+	h := NewHotFolderReader(ReaderConfig{WatchDir: dir})
+	h.pollInterval = 10 * time.Millisecond
+	return h, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFile espera o arquivo aparecer no caminho informado
+func waitFile(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("arquivo %s não encontrado", path)
+}
+
+// receive lê n eventos do leitor
+func receive(t *testing.T, r ISBNReader, n int) []ScanEvent {
+	t.Helper()
+	events := make([]ScanEvent, 0, n)
+	for len(events) < n {
+		select {
+		case ev := <-r.Read():
+			events = append(events, ev)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("recebidos %d de %d eventos", len(events), n)
+		}
+	}
+	return events
+}
+
+func TestHotFolderMovesAfterAck(t *testing.T) {
+	h, dir := newTestHotFolder(t)
+	writeFile(t, filepath.Join(dir, "lista.txt"), "9780132350884\nnão é isbn\n0596007124\n")
+	writeFile(t, filepath.Join(dir, "vazia.csv"), "titulo,autor\nsem isbn,ninguém\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := h.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	events := receive(t, h, 2)
+	if events[0].ISBN != "9780132350884" || events[1].ISBN != "9780596007126" {
+		t.Fatalf("eventos = %+v", events)
+	}
+	if events[0].Source != filepath.Join(dir, "lista.txt") {
+		t.Errorf("Source = %q", events[0].Source)
+	}
+
+	waitFile(t, filepath.Join(dir, failedDir, "vazia.csv"))
+
+	// Lido mas ainda aguardando resultados
+	processing := filepath.Join(dir, processingDir, "lista.txt")
+	waitFile(t, processing)
+
+	h.Ack(events[0], true)
+	time.Sleep(30 * time.Millisecond)
+	if _, err := os.Stat(processing); err != nil {
+		t.Fatalf("arquivo saiu de processing/ antes de todos os resultados: %v", err)
+	}
+
+	// Resultados de outros leitores são ignorados
+	h.Ack(ScanEvent{ISBN: "9780596007126", Source: events[1].Source, Reader: "FileISBNReader"}, true)
+
+	h.Ack(events[1], false)
+	waitFile(t, filepath.Join(dir, processedDir, "lista.txt"))
+}
+
+func TestHotFolderAllFailed(t *testing.T) {
+	h, dir := newTestHotFolder(t)
+	writeFile(t, filepath.Join(dir, "lista.txt"), "9780132350884\n")
+
+	if err := h.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	events := receive(t, h, 1)
+	waitFile(t, filepath.Join(dir, processingDir, "lista.txt"))
+	h.Ack(events[0], false)
+	waitFile(t, filepath.Join(dir, failedDir, "lista.txt"))
+}
+
+func TestHotFolderRequeue(t *testing.T) {
+	h, dir := newTestHotFolder(t)
+
+	// Arquivo deixado em processing/ por uma execução interrompida
+	if err := os.MkdirAll(filepath.Join(dir, processingDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, processingDir, "interrompida.txt"), "0132350882\n")
+
+	if err := h.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	events := receive(t, h, 1)
+	if events[0].ISBN != "9780132350884" || events[0].Source != filepath.Join(dir, "interrompida.txt") {
+		t.Errorf("evento = %+v", events[0])
+	}
+	h.Ack(events[0], true)
+	waitFile(t, filepath.Join(dir, processedDir, "interrompida.txt"))
+}
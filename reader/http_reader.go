@@ -0,0 +1,258 @@
+package reader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// Estados de uma leitura recebida via HTTP
+const (
+	ScanPending = "pending"
+	ScanDone    = "done"
+	ScanFailed  = "failed"
+)
+
+const (
+	// scanRetention é por quanto tempo leituras concluídas ficam disponíveis para
+	// consulta; leituras pendentes há mais tempo que isso não terão resultado
+	scanRetention = time.Hour
+	// maxScans limita as leituras guardadas; acima disso as mais antigas são descartadas
+	maxScans = 10000
+	// pruneInterval espaça as varreduras de leituras expiradas
+	pruneInterval = time.Minute
+)
+
+// ErrQueueFull indica que a fila de ISBNs do leitor HTTP está cheia
+var ErrQueueFull = errors.New("fila de leituras cheia")
+
+// Scan representa um ISBN recebido via HTTP e o resultado de sua consulta
+type Scan struct {
+	ID          string     `json:"id"`
+	ISBN        string     `json:"isbn"`
+	Status      string     `json:"status"`
+	Title       string     `json:"title,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// HTTPISBNReader recebe ISBNs enviados por clientes HTTP (ex.: app de leitura móvel)
+// e acompanha o resultado de cada leitura pelo ID devolvido ao cliente
+type HTTPISBNReader struct {
+	eventChan chan ScanEvent
+	stopChan  chan struct{}
+	isRunning bool
+	verbose   bool
+
+	mu        sync.Mutex
+	scans     map[string]*Scan
+	lastPrune time.Time
+}
+
+// NewHTTPISBNReader cria uma nova instância do leitor HTTP
+func NewHTTPISBNReader(config ReaderConfig) *HTTPISBNReader {
+	return &HTTPISBNReader{
+		eventChan: make(chan ScanEvent, 100),
+		stopChan:  make(chan struct{}),
+		verbose:   config.Verbose,
+		scans:     make(map[string]*Scan),
+	}
+}
+
+// Start ativa o leitor; os ISBNs chegam por Submit
+func (h *HTTPISBNReader) Start(ctx context.Context) error {
+	if h.isRunning {
+		return fmt.Errorf("leitor HTTP já está ativo")
+	}
+
+	h.isRunning = true
+
+	go func() {
+		select {
+		case <-h.stopChan:
+			if h.verbose {
+				log.Println("Leitor HTTP interrompido pelo usuário")
+			}
+		case <-ctx.Done():
+			if h.verbose {
+				log.Println("Contexto cancelado")
+			}
+		}
+
+		h.mu.Lock()
+		h.isRunning = false
+		close(h.eventChan)
+		h.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// Submit valida e enfileira um ISBN, retornando a leitura criada
+func (h *HTTPISBNReader) Submit(code string) (*Scan, error) {
+	normalized, err := isbn.Normalize(code)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newScanID()
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.isRunning {
+		return nil, fmt.Errorf("leitor HTTP não está ativo")
+	}
+
+	now := time.Now()
+	select {
+	case h.eventChan <- ScanEvent{
+		ID:        id,
+		ISBN:      normalized,
+		Raw:       code,
+		Reader:    h.GetType(),
+		Timestamp: now,
+	}:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	h.prune()
+
+	scan := &Scan{
+		ID:          id,
+		ISBN:        normalized,
+		Status:      ScanPending,
+		SubmittedAt: now,
+	}
+	h.scans[id] = scan
+
+	if h.verbose {
+		log.Printf("Leitura %s recebida via HTTP: %s", id, normalized)
+	}
+
+	copied := *scan
+	return &copied, nil
+}
+
+// Complete registra o resultado da consulta da leitura com o ID informado
+func (h *HTTPISBNReader) Complete(id string, success bool, title, errMsg string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	scan, ok := h.scans[id]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	scan.CompletedAt = &now
+	scan.Title = title
+	scan.Error = errMsg
+	scan.Status = ScanDone
+	if !success {
+		scan.Status = ScanFailed
+	}
+}
+
+// GetScan retorna uma cópia da leitura com o ID informado
+func (h *HTTPISBNReader) GetScan(id string) (*Scan, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	scan, ok := h.scans[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *scan
+	return &copied, true
+}
+
+// prune descarta leituras concluídas (ou pendentes) há mais de scanRetention e,
+// se ainda houver maxScans leituras, as mais antigas (chamar com mu travado)
+func (h *HTTPISBNReader) prune() {
+	now := time.Now()
+	if len(h.scans) < maxScans && now.Sub(h.lastPrune) < pruneInterval {
+		return
+	}
+	h.lastPrune = now
+
+	limit := now.Add(-scanRetention)
+	for id, scan := range h.scans {
+		if scan.lastChange().Before(limit) {
+			delete(h.scans, id)
+		}
+	}
+
+	if len(h.scans) < maxScans {
+		return
+	}
+
+	// Descarta um décimo a mais para não ordenar a cada nova leitura
+	ids := make([]string, 0, len(h.scans))
+	for id := range h.scans {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return h.scans[ids[i]].lastChange().Before(h.scans[ids[j]].lastChange())
+	})
+	for _, id := range ids[:len(ids)-maxScans*9/10] {
+		delete(h.scans, id)
+	}
+}
+
+// lastChange é o instante da conclusão ou, se pendente, do envio da leitura
+func (s *Scan) lastChange() time.Time {
+	if s.CompletedAt != nil {
+		return *s.CompletedAt
+	}
+	return s.SubmittedAt
+}
+
+// newScanID gera um identificador aleatório para a leitura
+func newScanID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erro ao gerar ID da leitura: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Stop para o leitor HTTP
+func (h *HTTPISBNReader) Stop() error {
+	if !h.IsRunning() {
+		return fmt.Errorf("leitor HTTP não está ativo")
+	}
+
+	close(h.stopChan)
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (h *HTTPISBNReader) Read() <-chan ScanEvent {
+	return h.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (h *HTTPISBNReader) GetType() string {
+	return "HTTPISBNReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (h *HTTPISBNReader) IsRunning() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.isRunning
+}
@@ -0,0 +1,89 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHTTPReaderPrune(t *testing.T) {
+	h := NewHTTPISBNReader(ReaderConfig{})
+	now := time.Now()
+	old := now.Add(-2 * scanRetention)
+	recent := now.Add(-time.Minute)
+
+	h.scans["concluida-antiga"] = &Scan{SubmittedAt: old, CompletedAt: &old}
+	h.scans["pendente-antiga"] = &Scan{SubmittedAt: old}
+	h.scans["concluida-recente"] = &Scan{SubmittedAt: old, CompletedAt: &recent}
+	h.scans["pendente-recente"] = &Scan{SubmittedAt: recent}
+
+	h.prune()
+	for _, id := range []string{"concluida-antiga", "pendente-antiga"} {
+		if _, ok := h.scans[id]; ok {
+			t.Errorf("leitura %s não foi descartada", id)
+		}
+	}
+	for _, id := range []string{"concluida-recente", "pendente-recente"} {
+		if _, ok := h.scans[id]; !ok {
+			t.Errorf("leitura %s foi descartada", id)
+		}
+	}
+
+	// Dentro do intervalo entre varreduras nada é descartado
+	h.scans["outra-antiga"] = &Scan{SubmittedAt: old}
+	h.prune()
+	if _, ok := h.scans["outra-antiga"]; !ok {
+		t.Error("varredura repetida antes de pruneInterval")
+	}
+}
+
+func TestHTTPReaderPruneLimit(t *testing.T) {
+	h := NewHTTPISBNReader(ReaderConfig{})
+	h.lastPrune = time.Now()
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < maxScans; i++ {
+		h.scans[fmt.Sprintf("%05d", i)] = &Scan{SubmittedAt: start.Add(time.Duration(i) * time.Millisecond)}
+	}
+
+	h.prune()
+	if len(h.scans) != maxScans*9/10 {
+		t.Fatalf("leituras guardadas = %d, esperado %d", len(h.scans), maxScans*9/10)
+	}
+	if _, ok := h.scans["00000"]; ok {
+		t.Error("a leitura mais antiga deveria ter sido descartada")
+	}
+	if _, ok := h.scans[fmt.Sprintf("%05d", maxScans-1)]; !ok {
+		t.Error("a leitura mais recente foi descartada")
+	}
+}
+
+func TestHTTPReaderSubmitComplete(t *testing.T) {
+	h := NewHTTPISBNReader(ReaderConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := h.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := h.Submit("0-13-235088-2")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if scan.ISBN != "9780132350884" || scan.Status != ScanPending {
+		t.Errorf("leitura = %+v", scan)
+	}
+	if ev := <-h.Read(); ev.ID != scan.ID {
+		t.Errorf("evento com ID %q, esperado %q", ev.ID, scan.ID)
+	}
+
+	h.Complete(scan.ID, true, "Clean Code", "")
+	got, ok := h.GetScan(scan.ID)
+	if !ok || got.Status != ScanDone || got.Title != "Clean Code" || got.CompletedAt == nil {
+		t.Errorf("leitura concluída = %+v", got)
+	}
+
+	if _, err := h.Submit("123"); err == nil {
+		t.Error("esperado erro para ISBN inválido")
+	}
+}
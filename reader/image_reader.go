@@ -0,0 +1,211 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"leitor-usbn/ean"
+	"leitor-usbn/isbn"
+)
+
+// ImageScanResult registra o resultado da decodificação de uma imagem
+type ImageScanResult struct {
+	File  string // caminho da imagem
+	ISBN  string // ISBN-13 normalizado (vazio em caso de falha)
+	AddOn string // add-on EAN-5, se presente
+	Error string // motivo da falha, se houver
+}
+
+// ImageISBNReader decodifica códigos de barras EAN-13 de fotos PNG/JPEG em um diretório
+type ImageISBNReader struct {
+	dirPath   string
+	eventChan chan ScanEvent
+	stopChan  chan struct{}
+	isRunning bool
+	verbose   bool
+	results   []ImageScanResult
+	mu        sync.Mutex
+}
+
+// NewImageISBNReader cria uma nova instância do leitor de imagens
+func NewImageISBNReader(config ReaderConfig) *ImageISBNReader {
+	return &ImageISBNReader{
+		dirPath:   config.ImageDir,
+		eventChan: make(chan ScanEvent, 100),
+		stopChan:  make(chan struct{}),
+		verbose:   config.Verbose,
+	}
+}
+
+// Start inicia a varredura do diretório de imagens
+func (r *ImageISBNReader) Start(ctx context.Context) error {
+	if r.isRunning {
+		return fmt.Errorf("leitor de imagens já está ativo")
+	}
+
+	if r.dirPath == "" {
+		return fmt.Errorf("diretório de imagens não configurado")
+	}
+
+	r.isRunning = true
+
+	go func() {
+		defer func() {
+			r.isRunning = false
+			close(r.eventChan)
+		}()
+
+		var files []string
+		err := filepath.WalkDir(r.dirPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isImageFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Erro ao listar imagens em %s: %v", r.dirPath, err)
+			return
+		}
+
+		if r.verbose {
+			log.Printf("%d imagem(ns) encontrada(s) em %s", len(files), r.dirPath)
+		}
+
+		for _, file := range files {
+			select {
+			case <-r.stopChan:
+				if r.verbose {
+					log.Println("Leitura de imagens interrompida pelo usuário")
+				}
+				return
+			case <-ctx.Done():
+				if r.verbose {
+					log.Println("Contexto cancelado")
+				}
+				return
+			default:
+			}
+
+			result := r.decode(file)
+			r.addResult(result)
+
+			if result.Error != "" {
+				if r.verbose {
+					log.Printf("%s: %s", file, result.Error)
+				}
+				continue
+			}
+
+			if r.verbose {
+				log.Printf("%s: ISBN lido: %s", file, result.ISBN)
+			}
+
+			select {
+			case r.eventChan <- r.event(result):
+			case <-r.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// decode decodifica o código de barras de uma imagem e valida o ISBN
+func (r *ImageISBNReader) decode(file string) ImageScanResult {
+	result := ImageScanResult{File: file}
+
+	decoded, err := ean.DecodeFile(file)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.AddOn = decoded.AddOn
+
+	// Apenas códigos Bookland (978/979) são ISBNs
+	code, err := isbn.Normalize(decoded.Code)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.ISBN = code
+	return result
+}
+
+// event cria o ScanEvent de uma imagem decodificada com sucesso
+func (r *ImageISBNReader) event(result ImageScanResult) ScanEvent {
+	event := ScanEvent{
+		ISBN:      result.ISBN,
+		Raw:       result.ISBN,
+		Source:    result.File,
+		Reader:    r.GetType(),
+		Timestamp: time.Now(),
+	}
+	if result.AddOn != "" {
+		event.Metadata = map[string]string{"ean5_addon": result.AddOn}
+	}
+	return event
+}
+
+// isImageFile indica se o arquivo tem extensão PNG ou JPEG
+func isImageFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+// addResult adiciona um resultado de forma thread-safe
+func (r *ImageISBNReader) addResult(result ImageScanResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+}
+
+// Results retorna o resultado de cada imagem processada, incluindo falhas
+func (r *ImageISBNReader) Results() []ImageScanResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]ImageScanResult, len(r.results))
+	copy(results, r.results)
+	return results
+}
+
+// Stop para a leitura das imagens
+func (r *ImageISBNReader) Stop() error {
+	if !r.isRunning {
+		return fmt.Errorf("leitor de imagens não está ativo")
+	}
+
+	close(r.stopChan)
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (r *ImageISBNReader) Read() <-chan ScanEvent {
+	return r.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (r *ImageISBNReader) GetType() string {
+	return "ImageISBNReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (r *ImageISBNReader) IsRunning() bool {
+	return r.isRunning
+}
@@ -0,0 +1,187 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// SourceHealth descreve o estado de um leitor filho do MultiReader
+type SourceHealth struct {
+	Type    string
+	Running bool
+	Error   string // erro ao iniciar, se houver
+}
+
+// MultiReader combina vários leitores (ex.: dois scanners e uma importação de
+// arquivo) em um único canal. A falha de um leitor não interrompe os demais.
+type MultiReader struct {
+	readers   []ISBNReader
+	startErrs []error
+	eventChan chan ScanEvent
+	stopChan  chan struct{}
+	isRunning bool
+	verbose   bool
+	mu        sync.Mutex
+}
+
+// NewMultiReader cria um leitor que agrega os leitores informados
+func NewMultiReader(readers []ISBNReader, config ReaderConfig) *MultiReader {
+	return &MultiReader{
+		readers:   readers,
+		startErrs: make([]error, len(readers)),
+		eventChan: make(chan ScanEvent, 100),
+		stopChan:  make(chan struct{}),
+		verbose:   config.Verbose,
+	}
+}
+
+// Start inicia todos os leitores filhos; retorna erro apenas se nenhum iniciar
+func (m *MultiReader) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isRunning {
+		return fmt.Errorf("leitor múltiplo já está ativo")
+	}
+
+	if len(m.readers) == 0 {
+		return fmt.Errorf("nenhum leitor configurado")
+	}
+
+	var wg sync.WaitGroup
+	started := 0
+
+	for i, r := range m.readers {
+		if err := r.Start(ctx); err != nil {
+			m.startErrs[i] = err
+			log.Printf("Leitor %s não iniciado: %v", r.GetType(), err)
+			continue
+		}
+		started++
+
+		wg.Add(1)
+		go m.forward(ctx, &wg, i, r)
+	}
+
+	if started == 0 {
+		return fmt.Errorf("nenhum dos %d leitores pôde ser iniciado", len(m.readers))
+	}
+
+	m.isRunning = true
+
+	if m.verbose {
+		log.Printf("=== Leitor múltiplo iniciado com %d de %d leitores ===", started, len(m.readers))
+	}
+
+	go func() {
+		wg.Wait()
+
+		m.mu.Lock()
+		m.isRunning = false
+		m.mu.Unlock()
+		close(m.eventChan)
+	}()
+
+	return nil
+}
+
+// forward repassa as leituras de um leitor filho até seu canal fechar
+func (m *MultiReader) forward(ctx context.Context, wg *sync.WaitGroup, index int, r ISBNReader) {
+	defer wg.Done()
+
+	for event := range r.Read() {
+		// Registra o filho de origem sem alterar o slice de quem enviou
+		event.route = append(event.route[:len(event.route):len(event.route)], index)
+
+		select {
+		case m.eventChan <- event:
+		case <-m.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	if m.verbose {
+		log.Printf("Leitor %s finalizado", r.GetType())
+	}
+}
+
+// Ack repassa o resultado ao leitor filho que entregou a leitura, se ele
+// acompanha suas leituras
+func (m *MultiReader) Ack(event ScanEvent, success bool) {
+	n := len(event.route)
+	if n == 0 || event.route[n-1] >= len(m.readers) {
+		return
+	}
+	r := m.readers[event.route[n-1]]
+	event.route = event.route[:n-1]
+
+	if acker, ok := r.(ResultAcker); ok {
+		acker.Ack(event, success)
+	}
+}
+
+// Health retorna o estado de cada leitor filho
+func (m *MultiReader) Health() []SourceHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health := make([]SourceHealth, len(m.readers))
+	for i, r := range m.readers {
+		health[i] = SourceHealth{
+			Type:    r.GetType(),
+			Running: r.IsRunning(),
+		}
+		if m.startErrs[i] != nil {
+			health[i].Error = m.startErrs[i].Error()
+		}
+	}
+	return health
+}
+
+// Stop para todos os leitores filhos ativos
+func (m *MultiReader) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isRunning {
+		return fmt.Errorf("leitor múltiplo não está ativo")
+	}
+
+	select {
+	case <-m.stopChan:
+		return fmt.Errorf("leitor múltiplo já foi parado")
+	default:
+	}
+
+	for _, r := range m.readers {
+		if r.IsRunning() {
+			if err := r.Stop(); err != nil {
+				log.Printf("Erro ao parar leitor %s: %v", r.GetType(), err)
+			}
+		}
+	}
+
+	close(m.stopChan)
+	return nil
+}
+
+// Read retorna o canal combinado de leituras
+func (m *MultiReader) Read() <-chan ScanEvent {
+	return m.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (m *MultiReader) GetType() string {
+	return "MultiReader"
+}
+
+// IsRunning indica se ao menos um leitor filho ainda está entregando leituras
+func (m *MultiReader) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isRunning
+}
@@ -0,0 +1,214 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReader é um leitor filho controlado pelo teste
+type fakeReader struct {
+	name     string
+	startErr error
+	events   chan ScanEvent
+
+	mu      sync.Mutex
+	running bool
+	stopped bool
+}
+
+func newFakeReader(name string) *fakeReader {
+	return &fakeReader{name: name, events: make(chan ScanEvent, 10)}
+}
+
+func (f *fakeReader) Start(ctx context.Context) error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = true
+	return nil
+}
+
+func (f *fakeReader) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = false
+	f.stopped = true
+	return nil
+}
+
+func (f *fakeReader) Read() <-chan ScanEvent { return f.events }
+func (f *fakeReader) GetType() string        { return f.name }
+
+func (f *fakeReader) IsRunning() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running
+}
+
+// finish fecha o canal do leitor, como ao fim de um arquivo
+func (f *fakeReader) finish() {
+	f.mu.Lock()
+	f.running = false
+	f.mu.Unlock()
+	close(f.events)
+}
+
+// ackReader é um leitor filho que registra os resultados recebidos por Ack
+type ackReader struct {
+	*fakeReader
+	acks []ScanEvent
+}
+
+func newAckReader(name string) *ackReader {
+	return &ackReader{fakeReader: newFakeReader(name)}
+}
+
+func (a *ackReader) Ack(event ScanEvent, success bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks = append(a.acks, event)
+}
+
+func (a *ackReader) acked() []ScanEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]ScanEvent(nil), a.acks...)
+}
+
+// expectClosed espera o canal combinado ser fechado
+func expectClosed(t *testing.T, m *MultiReader) {
+	t.Helper()
+	select {
+	case ev, ok := <-m.Read():
+		if ok {
+			t.Fatalf("evento inesperado: %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("canal combinado não foi fechado")
+	}
+}
+
+func TestMultiReaderFanIn(t *testing.T) {
+	scanner, file := newFakeReader("scanner"), newFakeReader("arquivo")
+	m := NewMultiReader([]ISBNReader{scanner, file}, ReaderConfig{})
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	scanner.events <- ScanEvent{ISBN: "9780132350884", Source: "scanner"}
+	file.events <- ScanEvent{ISBN: "9780201633610", Source: "lista.txt", Line: 1}
+	file.events <- ScanEvent{ISBN: "9780596007126", Source: "lista.txt", Line: 2}
+
+	got := make(map[string]string)
+	for _, ev := range receive(t, m, 3) {
+		got[ev.ISBN] = ev.Source
+	}
+	if got["9780132350884"] != "scanner" || got["9780201633610"] != "lista.txt" || got["9780596007126"] != "lista.txt" {
+		t.Errorf("leituras combinadas = %v", got)
+	}
+
+	// O canal combinado só fecha depois que todos os filhos terminam
+	file.finish()
+	scanner.events <- ScanEvent{ISBN: "9781491950357", Source: "scanner"}
+	if ev := receive(t, m, 1)[0]; ev.ISBN != "9781491950357" {
+		t.Errorf("leitura após o fim do arquivo = %+v", ev)
+	}
+	if !m.IsRunning() {
+		t.Error("IsRunning = false com um leitor ainda ativo")
+	}
+
+	scanner.finish()
+	expectClosed(t, m)
+	if m.IsRunning() {
+		t.Error("IsRunning = true depois que todos os leitores terminaram")
+	}
+}
+
+func TestMultiReaderStartFailure(t *testing.T) {
+	broken := newFakeReader("serial")
+	broken.startErr = errors.New("porta /dev/ttyACM0 não encontrada")
+	working := newFakeReader("scanner")
+
+	m := NewMultiReader([]ISBNReader{broken, working}, ReaderConfig{})
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start com um leitor funcionando: %v", err)
+	}
+
+	working.events <- ScanEvent{ISBN: "9780132350884"}
+	receive(t, m, 1)
+
+	health := m.Health()
+	if len(health) != 2 {
+		t.Fatalf("Health = %+v", health)
+	}
+	if health[0].Type != "serial" || health[0].Running || health[0].Error != "porta /dev/ttyACM0 não encontrada" {
+		t.Errorf("estado do leitor com falha = %+v", health[0])
+	}
+	if health[1].Type != "scanner" || !health[1].Running || health[1].Error != "" {
+		t.Errorf("estado do leitor ativo = %+v", health[1])
+	}
+
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if !working.stopped || broken.stopped {
+		t.Errorf("Stop: ativo parado = %v, com falha parado = %v", working.stopped, broken.stopped)
+	}
+	if err := m.Stop(); err == nil {
+		t.Error("esperado erro ao parar duas vezes")
+	}
+
+	// Todos falhando: Start retorna erro
+	broken2 := newFakeReader("serial")
+	broken2.startErr = errors.New("sem permissão")
+	if err := NewMultiReader([]ISBNReader{broken2}, ReaderConfig{}).Start(context.Background()); err == nil {
+		t.Error("esperado erro quando nenhum leitor inicia")
+	}
+	if err := NewMultiReader(nil, ReaderConfig{}).Start(context.Background()); err == nil {
+		t.Error("esperado erro sem leitores")
+	}
+}
+
+func TestMultiReaderAck(t *testing.T) {
+	// Só as pastas acompanham resultados; o scanner não implementa ResultAcker
+	first, second := newAckReader("pasta A"), newAckReader("pasta B")
+	plain := newFakeReader("scanner")
+	m := NewMultiReader([]ISBNReader{plain, first, second}, ReaderConfig{})
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	first.events <- ScanEvent{ISBN: "9780132350884", Source: "a.txt"}
+	second.events <- ScanEvent{ISBN: "9780201633610", Source: "b.txt"}
+	plain.events <- ScanEvent{ISBN: "9780596007126", Source: "scanner"}
+
+	for _, ev := range receive(t, m, 3) {
+		m.Ack(ev, true)
+	}
+	// Eventos que não vieram do MultiReader são ignorados
+	m.Ack(ScanEvent{ISBN: "9781491950357"}, true)
+
+	if acks := first.acked(); len(acks) != 1 || acks[0].Source != "a.txt" {
+		t.Errorf("Acks de pasta A = %+v", acks)
+	}
+	if acks := second.acked(); len(acks) != 1 || acks[0].Source != "b.txt" {
+		t.Errorf("Acks de pasta B = %+v", acks)
+	}
+
+	// MultiReader dentro de MultiReader: o Ack chega ao filho do filho
+	inner := newAckReader("pasta C")
+	nested := NewMultiReader([]ISBNReader{newFakeReader("scanner"), NewMultiReader([]ISBNReader{inner}, ReaderConfig{})}, ReaderConfig{})
+	if err := nested.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	inner.events <- ScanEvent{ISBN: "9780132350884", Source: "c.txt"}
+	nested.Ack(receive(t, nested, 1)[0], false)
+	if acks := inner.acked(); len(acks) != 1 || acks[0].Source != "c.txt" {
+		t.Errorf("Acks de pasta C = %+v", acks)
+	}
+}
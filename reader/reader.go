@@ -12,8 +12,8 @@ type ISBNReader interface {
 	// Stop para o leitor
 	Stop() error
 
-	// Read retorna um canal com os ISBNs lidos
-	Read() <-chan string
+	// Read retorna um canal com as leituras (ISBN normalizado e origem)
+	Read() <-chan ScanEvent
 
 	// GetType retorna o tipo do leitor
 	GetType() string
@@ -22,14 +22,46 @@ type ISBNReader interface {
 	IsRunning() bool
 }
 
+// ResultAcker é implementado por leitores que precisam saber quando uma leitura
+// terminou de ser processada (ex.: a pasta monitorada só move o arquivo depois
+// que todos os ISBNs dele foram consultados). O processador chama Ack para cada
+// resultado, exceto quando o processamento foi interrompido.
+type ResultAcker interface {
+	Ack(event ScanEvent, success bool)
+}
+
 // ReaderConfig contém configurações para os leitores
 type ReaderConfig struct {
-	// Para FileISBNReader
+	// Para FileISBNReader e CSVISBNReader
 	FilePath string
 
-	// Para BarcodeReaderUSB
-	DevicePath string
-	Timeout    int // em segundos
+	// Para CSVISBNReader
+	Delimiter string            // ",", ";", "tab" ou vazio para detectar
+	Header    string            // "auto" (padrão), "true" ou "false"
+	Columns   map[string]string // campo lógico -> nome da coluna ou índice (1 = primeira)
+
+	// Para BarcodeReaderUSB e SerialISBNReader
+	DevicePath string // /dev/input/eventN, /dev/ttyACM0 ou arquivo/pipe com eventos gravados
+	Timeout    int    // tempo máximo sem leituras, em segundos (0 = sem limite)
+	GrabDevice bool   // acesso exclusivo ao dispositivo (EVIOCGRAB)
+
+	// Para SerialISBNReader
+	BaudRate       int    // velocidade da porta (padrão: 9600)
+	Parity         string // "none", "even" ou "odd"
+	LineTerminator string // "CR", "LF", "CRLF" ou sufixo literal (vazio = CR ou LF)
+	StripPrefix    string // prefixo removido de cada leitura
+	StripSuffix    string // sufixo removido de cada leitura
+
+	// Para ImageISBNReader
+	ImageDir string // diretório com fotos PNG/JPEG de códigos de barras
+
+	// Para HotFolderReader
+	WatchDir     string // diretório monitorado
+	PollInterval int    // intervalo entre varreduras, em segundos (padrão: 2)
+
+	// Para StdinISBNReader
+	KeystrokeInterval int  // intervalo médio máximo entre teclas de um scanner, em ms
+	RejectManualInput bool // descarta linhas digitadas manualmente
 
 	// Geral
 	Verbose bool
@@ -0,0 +1,86 @@
+//go:build linux
+
+package reader
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// baudRates mapeia velocidades suportadas para as constantes termios
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+	230400: syscall.B230400,
+}
+
+// openSerialPort abre a porta serial e a configura em modo raw (8 bits de dados,
+// 1 stop bit) com a velocidade e paridade informadas
+func openSerialPort(path string, baudRate int, parity string) (*os.File, error) {
+	speed, ok := baudRates[baudRate]
+	if !ok {
+		return nil, fmt.Errorf("baud rate não suportado: %d", baudRate)
+	}
+
+	var parityFlags uint32
+	switch parity {
+	case "", "none":
+	case "even":
+		parityFlags = syscall.PARENB
+	case "odd":
+		parityFlags = syscall.PARENB | syscall.PARODD
+	default:
+		return nil, fmt.Errorf("paridade inválida: %s (use none, even ou odd)", parity)
+	}
+
+	port, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir porta serial %s: %w", path, err)
+	}
+
+	rc, err := port.SyscallConn()
+	if err != nil {
+		port.Close()
+		return nil, fmt.Errorf("erro ao acessar descritor de %s: %w", path, err)
+	}
+
+	var ioctlErr error
+	err = rc.Control(func(fd uintptr) {
+		var t syscall.Termios
+		if ioctlErr = ioctlTermios(fd, syscall.TCGETS, &t); ioctlErr != nil {
+			return
+		}
+
+		// Equivalente a cfmakeraw
+		t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+			syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.INPCK
+		t.Oflag &^= syscall.OPOST
+		t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+		t.Cflag &^= syscall.CSIZE | syscall.CSTOPB | syscall.PARENB | syscall.PARODD |
+			syscall.B38400 | syscall.B4000000 // máscara CBAUD
+		t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL | speed | parityFlags
+		if parityFlags != 0 {
+			t.Iflag |= syscall.INPCK
+		}
+		t.Cc[syscall.VMIN] = 1
+		t.Cc[syscall.VTIME] = 0
+
+		ioctlErr = ioctlTermios(fd, syscall.TCSETS, &t)
+	})
+	if err == nil {
+		err = ioctlErr
+	}
+	if err != nil {
+		port.Close()
+		return nil, fmt.Errorf("erro ao configurar porta serial %s: %w", path, err)
+	}
+
+	return port, nil
+}
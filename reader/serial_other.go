@@ -0,0 +1,13 @@
+//go:build !linux
+
+package reader
+
+import (
+	"fmt"
+	"os"
+)
+
+// openSerialPort não é suportado fora do Linux
+func openSerialPort(path string, baudRate int, parity string) (*os.File, error) {
+	return nil, fmt.Errorf("leitor serial não suportado nesta plataforma")
+}
@@ -0,0 +1,219 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// SerialISBNReader lê ISBNs de scanners conectados por porta serial
+// (RS-232 ou CDC-ACM, ex.: /dev/ttyACM0)
+type SerialISBNReader struct {
+	portPath    string
+	baudRate    int
+	parity      string
+	terminator  []byte
+	stripPrefix string
+	stripSuffix string
+	eventChan   chan ScanEvent
+	stopChan    chan struct{}
+	isRunning   bool
+	verbose     bool
+}
+
+// NewSerialISBNReader cria uma nova instância do leitor serial
+func NewSerialISBNReader(config ReaderConfig) *SerialISBNReader {
+	baudRate := config.BaudRate
+	if baudRate == 0 {
+		baudRate = 9600
+	}
+
+	return &SerialISBNReader{
+		portPath:    config.DevicePath,
+		baudRate:    baudRate,
+		parity:      strings.ToLower(config.Parity),
+		terminator:  parseTerminator(config.LineTerminator),
+		stripPrefix: config.StripPrefix,
+		stripSuffix: config.StripSuffix,
+		eventChan:   make(chan ScanEvent, 100),
+		stopChan:    make(chan struct{}),
+		verbose:     config.Verbose,
+	}
+}
+
+// parseTerminator converte o nome do terminador ("CR", "LF", "CRLF") ou um
+// valor literal em bytes; vazio aceita tanto CR quanto LF
+func parseTerminator(name string) []byte {
+	switch strings.ToUpper(name) {
+	case "":
+		return nil
+	case "CR":
+		return []byte("\r")
+	case "LF":
+		return []byte("\n")
+	case "CRLF":
+		return []byte("\r\n")
+	default:
+		return []byte(name)
+	}
+}
+
+// Start abre e configura a porta serial e inicia a leitura
+func (s *SerialISBNReader) Start(ctx context.Context) error {
+	if s.isRunning {
+		return fmt.Errorf("leitor serial já está ativo")
+	}
+
+	if s.portPath == "" {
+		return fmt.Errorf("porta serial não configurada")
+	}
+
+	port, err := openSerialPort(s.portPath, s.baudRate, s.parity)
+	if err != nil {
+		return err
+	}
+
+	s.isRunning = true
+
+	if s.verbose {
+		log.Println("=== Leitor Serial Iniciado ===")
+		log.Printf("Porta: %s (%d baud, paridade %s)", s.portPath, s.baudRate, s.parityName())
+	}
+
+	go func() {
+		defer func() {
+			s.isRunning = false
+			close(s.eventChan)
+		}()
+
+		done := make(chan struct{})
+		defer close(done)
+
+		// Fechar a porta desbloqueia a leitura em andamento
+		go func() {
+			select {
+			case <-s.stopChan:
+				if s.verbose {
+					log.Println("Leitor serial interrompido pelo usuário")
+				}
+			case <-ctx.Done():
+				if s.verbose {
+					log.Println("Contexto cancelado")
+				}
+			case <-done:
+			}
+			port.Close()
+		}()
+
+		scanner := bufio.NewScanner(port)
+		scanner.Split(s.splitLines)
+
+		for scanner.Scan() {
+			raw := s.strip(scanner.Text())
+			if raw == "" {
+				continue
+			}
+
+			code, err := isbn.Normalize(raw)
+			if err != nil {
+				if s.verbose {
+					log.Printf("Código ignorado: %v", err)
+				}
+				continue
+			}
+
+			if s.verbose {
+				log.Printf("ISBN lido da porta serial: %s", code)
+			}
+
+			select {
+			case s.eventChan <- ScanEvent{
+				ISBN:      code,
+				Raw:       raw,
+				Source:    s.portPath,
+				Reader:    s.GetType(),
+				Timestamp: time.Now(),
+			}:
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) && !errors.Is(err, io.EOF) {
+			log.Printf("Erro ao ler porta serial: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// splitLines separa as leituras pelo terminador configurado
+func (s *SerialISBNReader) splitLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if s.terminator == nil {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+	} else if i := bytes.Index(data, s.terminator); i >= 0 {
+		return i + len(s.terminator), data[:i], nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// strip remove prefixo e sufixo configurados (ex.: identificador de simbologia)
+func (s *SerialISBNReader) strip(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, s.stripPrefix)
+	line = strings.TrimSuffix(line, s.stripSuffix)
+	return strings.TrimSpace(line)
+}
+
+func (s *SerialISBNReader) parityName() string {
+	if s.parity == "" {
+		return "none"
+	}
+	return s.parity
+}
+
+// Stop para a leitura da porta serial
+func (s *SerialISBNReader) Stop() error {
+	if !s.isRunning {
+		return fmt.Errorf("leitor serial não está ativo")
+	}
+
+	close(s.stopChan)
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (s *SerialISBNReader) Read() <-chan ScanEvent {
+	return s.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (s *SerialISBNReader) GetType() string {
+	return "SerialISBNReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (s *SerialISBNReader) IsRunning() bool {
+	return s.isRunning
+}
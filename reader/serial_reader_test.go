@@ -0,0 +1,128 @@
+//go:build linux
+
+// Os testes usam um par de pseudoterminais (/dev/ptmx), disponível só no Linux
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// openPty abre um par de pseudoterminais; o escravo faz o papel da porta serial
+func openPty(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudoterminal indisponível: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := master.Fd()
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Fatalf("unlockpt: %v", errno)
+	}
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Fatalf("ptsname: %v", errno)
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestSerialReaderPty(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ReaderConfig
+		input  string
+		want   []ScanEvent
+	}{
+		{
+			name:   "CR com prefixo de simbologia e paridade par",
+			config: ReaderConfig{LineTerminator: "CR", StripPrefix: "]E0", Parity: "even"},
+			input:  "]E09780132350884\r]E00596007124\r",
+			want:   []ScanEvent{{ISBN: "9780132350884", Raw: "9780132350884"}, {ISBN: "9780596007126", Raw: "0596007124"}},
+		},
+		{
+			name:   "CRLF com sufixo e paridade ímpar",
+			config: ReaderConfig{LineTerminator: "CRLF", StripSuffix: "#", Parity: "odd", BaudRate: 115200},
+			input:  "978-0-201-63361-0#\r\ninválido#\r\n9780132350884#\r\n",
+			want:   []ScanEvent{{ISBN: "9780201633610", Raw: "978-0-201-63361-0"}, {ISBN: "9780132350884", Raw: "9780132350884"}},
+		},
+		{
+			name:   "terminador literal",
+			config: ReaderConfig{LineTerminator: ";"},
+			input:  "9780132350884;0132350882;",
+			want:   []ScanEvent{{ISBN: "9780132350884", Raw: "9780132350884"}, {ISBN: "9780132350884", Raw: "0132350882"}},
+		},
+		{
+			name:   "sem terminador configurado aceita CR e LF",
+			config: ReaderConfig{},
+			input:  "9780132350884\n\r0596007124\r",
+			want:   []ScanEvent{{ISBN: "9780132350884", Raw: "9780132350884"}, {ISBN: "9780596007126", Raw: "0596007124"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPty(t)
+
+			config := tt.config
+			config.DevicePath = slave
+			s := NewSerialISBNReader(config)
+			if err := s.Start(context.Background()); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+
+			if _, err := master.WriteString(tt.input); err != nil {
+				t.Fatalf("escrita no pty: %v", err)
+			}
+
+			for i, want := range tt.want {
+				select {
+				case ev := <-s.Read():
+					if ev.ISBN != want.ISBN || ev.Raw != want.Raw {
+						t.Errorf("leitura %d = %s (%q), esperado %s (%q)", i, ev.ISBN, ev.Raw, want.ISBN, want.Raw)
+					}
+					if ev.Source != slave || ev.Reader != "SerialISBNReader" {
+						t.Errorf("origem = %q/%q", ev.Source, ev.Reader)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatalf("leitura %d não recebida", i)
+				}
+			}
+
+			if err := s.Stop(); err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+			select {
+			case ev, ok := <-s.Read():
+				if ok {
+					t.Errorf("leitura inesperada: %+v", ev)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("canal não foi fechado após Stop")
+			}
+		})
+	}
+}
+
+func TestSerialReaderInvalidConfig(t *testing.T) {
+	_, slave := openPty(t)
+
+	if err := NewSerialISBNReader(ReaderConfig{DevicePath: slave, BaudRate: 1234}).Start(context.Background()); err == nil {
+		t.Error("esperado erro para baud rate não suportado")
+	}
+	if err := NewSerialISBNReader(ReaderConfig{DevicePath: slave, Parity: "mark"}).Start(context.Background()); err == nil {
+		t.Error("esperado erro para paridade inválida")
+	}
+	if err := NewSerialISBNReader(ReaderConfig{}).Start(context.Background()); err == nil {
+		t.Error("esperado erro sem porta configurada")
+	}
+}
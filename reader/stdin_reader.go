@@ -0,0 +1,283 @@
+package reader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"leitor-usbn/isbn"
+)
+
+// defaultKeystrokeInterval é o intervalo médio máximo entre teclas para que
+// uma linha seja considerada leitura de scanner (humanos digitam bem mais devagar)
+const defaultKeystrokeInterval = 50 * time.Millisecond
+
+// errInputStopped indica que a leitura do terminal foi interrompida por Stop
+var errInputStopped = errors.New("leitura do terminal interrompida")
+
+// terminalRestores guarda as funções que devolvem os terminais ao modo original,
+// para que RestoreTerminal funcione em saídas que não passam por Stop
+var (
+	terminalMu       sync.Mutex
+	terminalRestores []func()
+)
+
+// RestoreTerminal devolve ao modo canônico os terminais alterados por leitores
+// de terminal. Deve ser chamada antes de os.Exit ou log.Fatal, que não executam
+// os defers das goroutines dos leitores.
+func RestoreTerminal() {
+	terminalMu.Lock()
+	restores := terminalRestores
+	terminalRestores = nil
+	terminalMu.Unlock()
+
+	for _, restore := range restores {
+		restore()
+	}
+}
+
+// keystroke é um caractere recebido junto com o instante de chegada
+type keystroke struct {
+	r  rune
+	at time.Time
+}
+
+// StdinISBNReader lê ISBNs digitados no terminal por scanners em modo
+// keyboard-wedge, distinguindo rajadas do scanner de digitação manual
+type StdinISBNReader struct {
+	input             io.Reader
+	restore           func()
+	eventChan         chan ScanEvent
+	stopChan          chan struct{}
+	isRunning         bool
+	verbose           bool
+	keystrokeInterval time.Duration
+	rejectManual      bool
+}
+
+// NewStdinISBNReader cria um leitor que consome os.Stdin
+func NewStdinISBNReader(config ReaderConfig) *StdinISBNReader {
+	return NewStdinISBNReaderWithInput(os.Stdin, config)
+}
+
+// NewStdinISBNReaderWithInput cria um leitor que consome o io.Reader informado
+func NewStdinISBNReaderWithInput(input io.Reader, config ReaderConfig) *StdinISBNReader {
+	interval := time.Duration(config.KeystrokeInterval) * time.Millisecond
+	if config.KeystrokeInterval == 0 {
+		interval = defaultKeystrokeInterval
+	}
+
+	return &StdinISBNReader{
+		input:             input,
+		eventChan:         make(chan ScanEvent, 100),
+		stopChan:          make(chan struct{}),
+		verbose:           config.Verbose,
+		keystrokeInterval: interval,
+		rejectManual:      config.RejectManualInput,
+	}
+}
+
+// Start inicia a leitura do terminal
+func (s *StdinISBNReader) Start(ctx context.Context) error {
+	if s.isRunning {
+		return fmt.Errorf("leitor de terminal já está ativo")
+	}
+
+	// Em terminais no modo canônico a linha chega inteira, sem tempo por tecla
+	restore, err := enableCharMode(s.input)
+	if err != nil && s.verbose {
+		log.Printf("Aviso: não foi possível desativar o modo canônico do terminal: %v", err)
+	}
+	if restore != nil {
+		s.restore = sync.OnceFunc(restore)
+		terminalMu.Lock()
+		terminalRestores = append(terminalRestores, s.restore)
+		terminalMu.Unlock()
+	}
+
+	s.isRunning = true
+
+	if s.verbose {
+		log.Println("=== Leitor de Terminal Iniciado ===")
+		log.Println("Aguardando leituras do scanner (keyboard-wedge)...")
+	}
+
+	keys := make(chan keystroke, 256)
+	go s.readKeystrokes(keys)
+
+	go func() {
+		defer func() {
+			if s.restore != nil {
+				s.restore()
+			}
+			s.isRunning = false
+			close(s.eventChan)
+		}()
+
+		var line []keystroke
+
+		for {
+			select {
+			case <-s.stopChan:
+				if s.verbose {
+					log.Println("Leitura do terminal interrompida pelo usuário")
+				}
+				return
+			case <-ctx.Done():
+				if s.verbose {
+					log.Println("Contexto cancelado")
+				}
+				return
+			case k, ok := <-keys:
+				if !ok {
+					if len(line) > 0 && !s.emit(ctx, line) {
+						return
+					}
+					if s.verbose {
+						log.Println("Fim da entrada do terminal")
+					}
+					return
+				}
+
+				switch k.r {
+				case '\r', '\n':
+					if len(line) > 0 && !s.emit(ctx, line) {
+						return
+					}
+					line = line[:0]
+				case '\b', 0x7f:
+					if len(line) > 0 {
+						line = line[:len(line)-1]
+					}
+				default:
+					line = append(line, k)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// readKeystrokes lê a entrada caractere a caractere registrando o instante de cada um
+func (s *StdinISBNReader) readKeystrokes(keys chan<- keystroke) {
+	defer close(keys)
+
+	br := bufio.NewReader(newStoppableInput(s.input, s.stopChan))
+	for {
+		r, _, err := br.ReadRune()
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, errInputStopped) {
+				log.Printf("Erro ao ler terminal: %v", err)
+			}
+			return
+		}
+
+		select {
+		case keys <- keystroke{r: r, at: time.Now()}:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// emit classifica a linha, normaliza o ISBN e o envia pelo canal.
+// Retorna false se o leitor foi parado durante o envio.
+func (s *StdinISBNReader) emit(ctx context.Context, line []keystroke) bool {
+	var b strings.Builder
+	for _, k := range line {
+		b.WriteRune(k.r)
+	}
+	raw := strings.TrimSpace(b.String())
+	if raw == "" {
+		return true
+	}
+
+	scanned := s.isBurst(line)
+	source, mode := "scanner", "scanner"
+	if !scanned {
+		source, mode = "digitação manual", "manual"
+	}
+
+	if !scanned && s.rejectManual {
+		if s.verbose {
+			log.Printf("Entrada ignorada (%s): %s", source, raw)
+		}
+		return true
+	}
+
+	code, err := isbn.Normalize(raw)
+	if err != nil {
+		if s.verbose {
+			log.Printf("Entrada ignorada (%s): %v", source, err)
+		}
+		return true
+	}
+
+	if s.verbose {
+		log.Printf("ISBN lido (%s): %s", source, code)
+	}
+
+	select {
+	case s.eventChan <- ScanEvent{
+		ISBN:      code,
+		Raw:       raw,
+		Source:    "stdin",
+		Reader:    s.GetType(),
+		Timestamp: line[0].at,
+		Metadata:  map[string]string{"input_mode": mode},
+	}:
+		return true
+	case <-s.stopChan:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isBurst indica se o intervalo médio entre teclas é compatível com um scanner
+func (s *StdinISBNReader) isBurst(line []keystroke) bool {
+	if len(line) < 2 {
+		return true
+	}
+
+	elapsed := line[len(line)-1].at.Sub(line[0].at)
+	avg := elapsed / time.Duration(len(line)-1)
+	return avg <= s.keystrokeInterval
+}
+
+// Stop para a leitura do terminal
+func (s *StdinISBNReader) Stop() error {
+	if !s.isRunning {
+		return fmt.Errorf("leitor de terminal não está ativo")
+	}
+
+	close(s.stopChan)
+	if s.restore != nil {
+		// Restaura já, sem esperar a goroutine, caso o programa saia em seguida
+		s.restore()
+	}
+	return nil
+}
+
+// Read retorna o canal de leituras
+func (s *StdinISBNReader) Read() <-chan ScanEvent {
+	return s.eventChan
+}
+
+// GetType retorna o tipo do leitor
+func (s *StdinISBNReader) GetType() string {
+	return "StdinISBNReader"
+}
+
+// IsRunning indica se o leitor está ativo
+func (s *StdinISBNReader) IsRunning() bool {
+	return s.isRunning
+}
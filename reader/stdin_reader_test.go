@@ -0,0 +1,138 @@
+package reader
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// keystrokes monta uma linha com o intervalo informado entre as teclas
+func keystrokes(text string, gap time.Duration) []keystroke {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	line := make([]keystroke, 0, len(text))
+	for i, r := range text {
+		line = append(line, keystroke{r: r, at: start.Add(time.Duration(i) * gap)})
+	}
+	return line
+}
+
+func TestIsBurst(t *testing.T) {
+	s := NewStdinISBNReaderWithInput(nil, ReaderConfig{})
+
+	tests := []struct {
+		name string
+		line []keystroke
+		want bool
+	}{
+		{"tecla única", keystrokes("9", 0), true},
+		{"scanner", keystrokes("9780132350884", 5*time.Millisecond), true},
+		{"no limite", keystrokes("9780132350884", defaultKeystrokeInterval), true},
+		{"digitação manual", keystrokes("9780132350884", 150*time.Millisecond), false},
+	}
+	for _, tt := range tests {
+		if got := s.isBurst(tt.line); got != tt.want {
+			t.Errorf("%s: isBurst = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Uma pausa longa pesa na média mesmo com o restante em rajada
+	line := keystrokes("9780132350884", time.Millisecond)
+	line[len(line)-1].at = line[0].at.Add(time.Second)
+	if s.isBurst(line) {
+		t.Error("linha com pausa de 1s classificada como rajada")
+	}
+
+	custom := NewStdinISBNReaderWithInput(nil, ReaderConfig{KeystrokeInterval: 200})
+	if !custom.isBurst(keystrokes("9780132350884", 150*time.Millisecond)) {
+		t.Error("keystrokeInterval configurado não foi respeitado")
+	}
+}
+
+// startStdin inicia um leitor sobre um pipe e devolve o lado de escrita
+func startStdin(t *testing.T, config ReaderConfig) (*StdinISBNReader, *io.PipeWriter) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	s := NewStdinISBNReaderWithInput(pr, config)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { pw.Close() })
+	return s, pw
+}
+
+// typeSlowly escreve o texto uma tecla por vez, como um humano
+func typeSlowly(t *testing.T, w io.Writer, text string, gap time.Duration) {
+	t.Helper()
+	for _, r := range text {
+		if _, err := io.WriteString(w, string(r)); err != nil {
+			t.Fatalf("escrita: %v", err)
+		}
+		time.Sleep(gap)
+	}
+}
+
+// nextEvent espera o próximo evento ou o fechamento do canal
+func nextEvent(t *testing.T, s *StdinISBNReader) (ScanEvent, bool) {
+	t.Helper()
+	select {
+	case ev, ok := <-s.Read():
+		return ev, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("nenhum evento recebido")
+		return ScanEvent{}, false
+	}
+}
+
+func TestStdinReaderBurst(t *testing.T) {
+	s, w := startStdin(t, ReaderConfig{KeystrokeInterval: 5})
+
+	io.WriteString(w, "978-0-13-235088-4\n")
+	ev, ok := nextEvent(t, s)
+	if !ok {
+		t.Fatal("canal fechado antes da leitura")
+	}
+	if ev.ISBN != "9780132350884" || ev.Raw != "978-0-13-235088-4" || ev.Source != "stdin" {
+		t.Errorf("evento = %+v", ev)
+	}
+
+	// Backspace corrige a linha antes do Enter
+	io.WriteString(w, "97801323508845\b\n")
+	if ev, _ := nextEvent(t, s); ev.ISBN != "9780132350884" {
+		t.Errorf("após backspace ISBN = %q", ev.ISBN)
+	}
+
+	// Entradas inválidas são descartadas sem encerrar o leitor
+	io.WriteString(w, "não é isbn\n0132350882\r")
+	if ev, _ := nextEvent(t, s); ev.ISBN != "9780132350884" {
+		t.Errorf("ISBN-10 não normalizado: %q", ev.ISBN)
+	}
+
+	w.Close()
+	if _, ok := nextEvent(t, s); ok {
+		t.Error("canal deveria fechar no fim da entrada")
+	}
+}
+
+func TestStdinReaderRejectManual(t *testing.T) {
+	s, w := startStdin(t, ReaderConfig{KeystrokeInterval: 5, RejectManualInput: true})
+
+	typeSlowly(t, w, "9780132350884\n", 30*time.Millisecond)
+	io.WriteString(w, "0596007124\n")
+
+	ev, ok := nextEvent(t, s)
+	if !ok || ev.ISBN != "9780596007126" {
+		t.Errorf("esperada só a leitura em rajada, recebido %+v", ev)
+	}
+}
+
+func TestStdinReaderStop(t *testing.T) {
+	s, _ := startStdin(t, ReaderConfig{})
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, ok := nextEvent(t, s); ok {
+		t.Error("canal deveria fechar após Stop")
+	}
+}
@@ -0,0 +1,100 @@
+//go:build linux
+
+package reader
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// pollInterval é a frequência com que a leitura do terminal verifica a parada
+const pollInterval = 100 * time.Millisecond
+
+// enableCharMode desativa o modo canônico quando a entrada é um terminal, para
+// que cada tecla chegue imediatamente e o intervalo entre teclas possa ser medido.
+// Retorna uma função que restaura o estado original (nil se nada foi alterado).
+func enableCharMode(input io.Reader) (func(), error) {
+	f, ok := input.(*os.File)
+	if !ok {
+		return nil, nil
+	}
+
+	fd := f.Fd()
+	var original syscall.Termios
+	if err := ioctlTermios(fd, syscall.TCGETS, &original); err != nil {
+		// Não é um terminal (pipe ou arquivo redirecionado)
+		return nil, nil
+	}
+
+	raw := original
+	raw.Lflag &^= syscall.ICANON
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctlTermios(fd, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = ioctlTermios(fd, syscall.TCSETS, &original)
+	}, nil
+}
+
+// stoppableInput espera dados com select(2) em intervalos curtos antes de cada
+// Read, para que a goroutine de leitura termine quando stop é fechado em vez de
+// ficar bloqueada no read até a próxima tecla
+type stoppableInput struct {
+	f    *os.File
+	fd   int
+	stop <-chan struct{}
+}
+
+// newStoppableInput envolve a entrada quando ela é um descritor de arquivo
+func newStoppableInput(input io.Reader, stop <-chan struct{}) io.Reader {
+	f, ok := input.(*os.File)
+	if !ok {
+		return input
+	}
+
+	fd := int(f.Fd())
+	var set syscall.FdSet
+	if fd >= 8*int(unsafe.Sizeof(set.Bits)) {
+		return input
+	}
+	return &stoppableInput{f: f, fd: fd, stop: stop}
+}
+
+func (s *stoppableInput) Read(p []byte) (int, error) {
+	bits := 8 * int(unsafe.Sizeof(syscall.FdSet{}.Bits[0]))
+
+	for {
+		select {
+		case <-s.stop:
+			return 0, errInputStopped
+		default:
+		}
+
+		var set syscall.FdSet
+		set.Bits[s.fd/bits] |= 1 << (uint(s.fd) % uint(bits))
+		tv := syscall.NsecToTimeval(int64(pollInterval))
+
+		n, err := syscall.Select(s.fd+1, &set, nil, nil, &tv)
+		switch {
+		case err == syscall.EINTR:
+			continue
+		case err != nil || n > 0:
+			return s.f.Read(p)
+		}
+	}
+}
+
+func ioctlTermios(fd uintptr, req uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
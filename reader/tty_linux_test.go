@@ -0,0 +1,53 @@
+//go:build linux
+
+package reader
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStoppableInput(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+
+	stop := make(chan struct{})
+	input := newStoppableInput(pr, stop)
+
+	pw.WriteString("978")
+	buf := make([]byte, 8)
+	n, err := input.Read(buf)
+	if err != nil || string(buf[:n]) != "978" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	// Sem dados, o Read deve retornar logo após a parada
+	done := make(chan error, 1)
+	go func() {
+		_, err := input.Read(buf)
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errInputStopped) {
+			t.Errorf("erro = %v, esperado errInputStopped", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Read continuou bloqueado após a parada")
+	}
+
+	// Leitores que não são arquivos não são envolvidos
+	if r, _ := io.Pipe(); newStoppableInput(r, stop) != io.Reader(r) {
+		t.Error("io.Pipe não deveria ser envolvido")
+	}
+}
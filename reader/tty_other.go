@@ -0,0 +1,17 @@
+//go:build !linux
+
+package reader
+
+import "io"
+
+// enableCharMode não altera o terminal fora do Linux; a linha chega inteira
+// e toda entrada é tratada como leitura de scanner
+func enableCharMode(input io.Reader) (func(), error) {
+	return nil, nil
+}
+
+// newStoppableInput devolve a entrada sem alteração: fora do Linux a goroutine
+// de leitura só termina quando a próxima tecla ou o fim da entrada chegam
+func newStoppableInput(input io.Reader, stop <-chan struct{}) io.Reader {
+	return input
+}
@@ -20,6 +20,8 @@ import (
 func main() {
 	// Flags
 	configPath := flag.String("config", "./config/config.json", "Caminho para arquivo de configuração")
+	noCache := flag.Bool("no-cache", false, "Consulta a API sem usar nem gravar o cache de metadados")
+	refresh := flag.Bool("refresh", false, "Ignora o cache de metadados, mas grava as novas respostas")
 	flag.Parse()
 
 	fmt.Println("=== LEITOR USBN - Sistema de Leitura e Consulta de Livros ===\n")
@@ -48,30 +50,50 @@ func main() {
 
 	// Inicializar cliente API
 	fmt.Println("[3] Inicializando cliente API...")
-	apiClient := api.NewBookAPIClient(cfg.API.BaseURL, cfg.API.Timeout)
-	fmt.Printf("✓ Cliente API criado: %s\n\n", cfg.API.Provider)
+	providerConfig := toProviderConfig(cfg.API)
+	if !*noCache {
+		cache, err := api.NewCache(api.CacheConfig{
+			Dir:         cfg.API.CacheDir,
+			TTL:         time.Duration(cfg.API.CacheTTL) * time.Hour,
+			NegativeTTL: time.Duration(cfg.API.NegativeCacheTTL) * time.Hour,
+			Refresh:     *refresh,
+		})
+		if err != nil {
+			log.Fatalf("Erro ao criar cache de metadados: %v", err)
+		}
+		providerConfig.Cache = cache
+	}
+
+	provider, err := api.NewProvider(providerConfig)
+	if err != nil {
+		log.Fatalf("Erro ao criar cliente API: %v", err)
+	}
+	fmt.Printf("✓ Cliente API criado: %s\n", provider.Name())
+	if *noCache {
+		fmt.Print("✓ Cache de metadados desativado\n\n")
+	} else {
+		fmt.Printf("✓ Cache de metadados: %s\n\n", cfg.API.CacheDir)
+	}
 
 	// Criar leitor de ISBNs
 	fmt.Println("[4] Configurando leitor de ISBNs...")
-	readerConfig := reader.ReaderConfig{
-		FilePath: cfg.Reader.InputFile,
-		Verbose:  cfg.Reader.Verbose,
+	isbnReader, err := newISBNReader(cfg.Reader)
+	if err != nil {
+		log.Fatalf("Erro ao configurar leitor: %v", err)
 	}
 
-	var isbnReader reader.ISBNReader
+	fmt.Printf("✓ Leitor de ISBNs configurado: %s\n\n", isbnReader.GetType())
+
+	// Leitores de lote têm tempo limite; leitores contínuos (pasta monitorada,
+	// scanners) rodam como serviço até receber um sinal
+	var ctx context.Context
+	var cancel context.CancelFunc
 	switch cfg.Reader.Type {
-	case "file":
-		isbnReader = reader.NewFileISBNReader(readerConfig)
-	case "barcode":
-		isbnReader = reader.NewBarcodeReaderUSB(readerConfig)
+	case "file", "image":
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Minute)
 	default:
-		log.Fatalf("Tipo de leitor desconhecido: %s", cfg.Reader.Type)
+		ctx, cancel = context.WithCancel(context.Background())
 	}
-
-	fmt.Printf("✓ Leitor de ISBNs configurado: %s\n\n", isbnReader.GetType())
-
-	// Criar contexto com timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	// Capturar sinais para graceful shutdown
@@ -90,18 +112,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("Erro ao iniciar leitor: %v", err)
 	}
+	// O leitor de terminal desativa o modo canônico; log.Fatal e o fim do main
+	// não esperam a goroutine do leitor restaurá-lo
+	defer reader.RestoreTerminal()
 	fmt.Println("✓ Leitor iniciado\n")
 
 	// Criar processador
 	fmt.Println("[6] Configurando processador...")
 	processorConfig := processor.ProcessorConfig{
-		MaxWorkers:           cfg.Processor.MaxWorkers,
-		DelayBetweenRequests: time.Duration(cfg.Processor.DelayBetweenRequests) * time.Millisecond,
-		MaxRetries:           cfg.Processor.MaxRetries,
-		Verbose:              cfg.Processor.Verbose,
+		MaxWorkers: cfg.Processor.MaxWorkers,
+		MaxRetries: cfg.Processor.MaxRetries,
+		Verbose:    cfg.Processor.Verbose,
 	}
 
-	proc := processor.NewProcessor(db, apiClient, isbnReader, processorConfig)
+	proc := processor.NewProcessor(db, provider, isbnReader, processorConfig)
 	fmt.Printf("✓ Processador criado com %d worker(s)\n\n", processorConfig.MaxWorkers)
 
 	// Processar ISBNs
@@ -111,6 +135,7 @@ func main() {
 	startTime := time.Now()
 	err = proc.Process(ctx)
 	if err != nil {
+		reader.RestoreTerminal()
 		log.Fatalf("Erro ao processar: %v", err)
 	}
 
@@ -119,6 +144,32 @@ func main() {
 	// Imprimir resumo
 	proc.PrintSummary()
 
+	if multiReader, ok := isbnReader.(*reader.MultiReader); ok {
+		fmt.Println("\n--- Leitores ---")
+		for _, h := range multiReader.Health() {
+			if h.Error != "" {
+				fmt.Printf("  %s: falhou ao iniciar: %s\n", h.Type, h.Error)
+			} else {
+				fmt.Printf("  %s: ativo=%v\n", h.Type, h.Running)
+			}
+		}
+	}
+
+	// Imagens sem código legível não chegam ao processador
+	if imageReader, ok := isbnReader.(*reader.ImageISBNReader); ok {
+		header := false
+		for _, r := range imageReader.Results() {
+			if r.Error == "" {
+				continue
+			}
+			if !header {
+				fmt.Println("\n--- Imagens sem ISBN legível ---")
+				header = true
+			}
+			fmt.Printf("  %s: %s\n", r.File, r.Error)
+		}
+	}
+
 	// Total de livros no banco
 	totalBooks, err := db.CountBooks()
 	if err == nil {
@@ -129,3 +180,85 @@ func main() {
 	fmt.Println("\n✓ Aplicação finalizada com sucesso!")
 }
 
+// newISBNReader cria o leitor correspondente ao tipo configurado; o tipo "multi"
+// combina os leitores listados em "sources"
+func newISBNReader(rc config.ReaderConfig) (reader.ISBNReader, error) {
+	readerConfig := toReaderConfig(rc)
+
+	switch rc.Type {
+	case "file":
+		return reader.NewFileISBNReader(readerConfig), nil
+	case "barcode":
+		return reader.NewBarcodeReaderUSB(readerConfig), nil
+	case "csv":
+		return reader.NewCSVISBNReader(readerConfig), nil
+	case "serial":
+		return reader.NewSerialISBNReader(readerConfig), nil
+	case "image":
+		return reader.NewImageISBNReader(readerConfig), nil
+	case "watch":
+		return reader.NewHotFolderReader(readerConfig), nil
+	case "stdin":
+		return reader.NewStdinISBNReader(readerConfig), nil
+	case "multi":
+		children := make([]reader.ISBNReader, 0, len(rc.Sources))
+		for i, source := range rc.Sources {
+			source.Verbose = source.Verbose || rc.Verbose
+			child, err := newISBNReader(source)
+			if err != nil {
+				return nil, fmt.Errorf("leitor %d de \"sources\": %w", i+1, err)
+			}
+			children = append(children, child)
+		}
+		return reader.NewMultiReader(children, readerConfig), nil
+	default:
+		return nil, fmt.Errorf("tipo de leitor desconhecido: %s", rc.Type)
+	}
+}
+
+// toReaderConfig converte a configuração do arquivo JSON para o pacote reader
+func toReaderConfig(rc config.ReaderConfig) reader.ReaderConfig {
+	return reader.ReaderConfig{
+		FilePath:   rc.InputFile,
+		Delimiter:  rc.Delimiter,
+		Header:     rc.Header,
+		Columns:    rc.Columns,
+		DevicePath: rc.DevicePath,
+		Timeout:    rc.Timeout,
+		GrabDevice: rc.GrabDevice,
+		Verbose:    rc.Verbose,
+
+		BaudRate:       rc.BaudRate,
+		Parity:         rc.Parity,
+		LineTerminator: rc.LineTerminator,
+		StripPrefix:    rc.StripPrefix,
+		StripSuffix:    rc.StripSuffix,
+
+		ImageDir: rc.ImageDir,
+
+		WatchDir:     rc.WatchDir,
+		PollInterval: rc.PollInterval,
+
+		KeystrokeInterval: rc.KeystrokeInterval,
+		RejectManualInput: rc.RejectManualInput,
+	}
+}
+
+// toProviderConfig converte a configuração da API (incluindo as fontes de uma
+// cadeia de provedores) para o formato do pacote api
+func toProviderConfig(ac config.APIConfig) api.ProviderConfig {
+	pc := api.ProviderConfig{
+		Provider:      ac.Provider,
+		BaseURL:       ac.BaseURL,
+		APIKey:        ac.APIKey,
+		Timeout:       ac.Timeout,
+		FieldPriority: ac.FieldPriority,
+
+		RequestsPerSecond: ac.RequestsPerSecond,
+		Burst:             ac.Burst,
+	}
+	for _, sub := range ac.Providers {
+		pc.Providers = append(pc.Providers, toProviderConfig(sub))
+	}
+	return pc
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"leitor-usbn/database"
+)
+
+const usage = `Uso: migrate [-db caminho] <comando> [argumento]
+
+Comandos:
+  status        mostra as migrações aplicadas e pendentes
+  up            aplica todas as migrações pendentes
+  down [n]      reverte as últimas n migrações (padrão: 1)
+  to <versão>   leva o schema à versão informada (0 remove tudo)
+`
+
+func main() {
+	dbPath := flag.String("db", "./books.db", "caminho para o arquivo sqlite")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nOpções:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := database.NewDatabase(*dbPath)
+	if err != nil {
+		log.Fatalf("erro ao abrir DB: %v", err)
+	}
+	defer db.Close()
+
+	switch cmd := flag.Arg(0); cmd {
+	case "status":
+		err = printStatus(db)
+	case "up":
+		err = db.Migrate()
+		if err == nil {
+			err = printVersion(db)
+		}
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("número de migrações inválido: %s", flag.Arg(1))
+			}
+		}
+		err = db.Rollback(steps)
+		if err == nil {
+			err = printVersion(db)
+		}
+	case "to":
+		if flag.NArg() < 2 {
+			log.Fatal("informe a versão de destino")
+		}
+		version, convErr := strconv.Atoi(flag.Arg(1))
+		if convErr != nil {
+			log.Fatalf("versão inválida: %s", flag.Arg(1))
+		}
+		err = db.MigrateTo(version)
+		if err == nil {
+			err = printVersion(db)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "comando desconhecido: %s\n\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printStatus lista cada migração com a data em que foi aplicada
+func printStatus(db *database.Database) error {
+	status, err := db.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		if s.Applied {
+			fmt.Printf("  [x] %03d %-30s aplicada em %s\n", s.Version, s.Name, s.AppliedAt.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("  [ ] %03d %-30s pendente\n", s.Version, s.Name)
+		}
+	}
+	return printVersion(db)
+}
+
+// printVersion mostra a versão atual do schema
+func printVersion(db *database.Database) error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Versão do schema: %d (última: %d)\n", version, database.LatestVersion())
+	return nil
+}
@@ -58,10 +58,11 @@ func main() {
 	successCount := 0
 	errorCount := 0
 
-	for isbn := range isbnReader.Read() {
+	for event := range isbnReader.Read() {
 		processedCount++
+		isbn := event.ISBN
 
-		fmt.Printf("\n--- Processando ISBN %d: %s ---\n", processedCount, isbn)
+		fmt.Printf("\n--- Processando ISBN %d: %s (%s) ---\n", processedCount, isbn, event.Origin())
 
 		// Consultar API
 		apiBook, err := apiClient.GetBookByISBN(isbn)
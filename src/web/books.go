@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"leitor-usbn/database"
+)
+
+// listParams são os parâmetros de listagem aceitos por /api/books,
+// /api/books/search e /ui
+type listParams struct {
+	Query   string
+	Filters database.BookFilters
+	Sort    database.BookSort
+	Page    database.Page
+}
+
+// parseListParams lê da query string a busca (q), os filtros (author,
+// publisher, year_from, year_to, missing), a ordenação (sort) e a paginação
+// (page, page_size)
+func parseListParams(r *http.Request) (listParams, error) {
+	q := r.URL.Query()
+	params := listParams{
+		Query: strings.TrimSpace(q.Get("q")),
+		Filters: database.BookFilters{
+			Author:    strings.TrimSpace(q.Get("author")),
+			Publisher: strings.TrimSpace(q.Get("publisher")),
+		},
+	}
+
+	ints := map[string]*int{
+		"page":      &params.Page.Number,
+		"page_size": &params.Page.Size,
+		"year_from": &params.Filters.YearFrom,
+		"year_to":   &params.Filters.YearTo,
+	}
+	for name, dst := range ints {
+		value := strings.TrimSpace(q.Get(name))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return params, fmt.Errorf("parâmetro %q inválido: %s", name, value)
+		}
+		*dst = n
+	}
+
+	var err error
+	if params.Filters.Missing, err = database.ParseMissingFields(q.Get("missing")); err != nil {
+		return params, err
+	}
+	if params.Sort, err = database.ParseBookSort(q.Get("sort")); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// pageURL devolve a URL da requisição apontando para outra página
+func pageURL(r *http.Request, page int) string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// pageLinks calcula as URLs da página anterior e da próxima (vazias se não houver)
+func pageLinks(r *http.Request, page, pageSize, total int) (prev, next string) {
+	if page > 1 {
+		prev = pageURL(r, page-1)
+	}
+	if page*pageSize < total {
+		next = pageURL(r, page+1)
+	}
+	return prev, next
+}
+
+// setPaginationHeaders informa o total de itens e as páginas vizinhas (Link, RFC 8288)
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, page, pageSize, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	w.Header().Set("X-Page-Size", strconv.Itoa(pageSize))
+
+	var links []string
+	prev, next := pageLinks(r, page, pageSize, total)
+	if prev != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", prev))
+	}
+	if next != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", next))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// handleListBooks responde GET /api/books com uma página de livros; o corpo
+// continua sendo a lista e o total vem nos cabeçalhos
+func handleListBooks(w http.ResponseWriter, r *http.Request, db *database.Database) {
+	params, err := parseListParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := db.ListBooks(params.Filters, params.Sort, params.Page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationHeaders(w, r, list.Page, list.PageSize, list.Total)
+	writeJSON(w, list.Books)
+}
+
+// handleSearchBooks responde GET /api/books/search
+func handleSearchBooks(w http.ResponseWriter, r *http.Request, db *database.Database) {
+	params, err := parseListParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.Query == "" {
+		http.Error(w, "informe o parâmetro \"q\"", http.StatusBadRequest)
+		return
+	}
+
+	results, err := db.SearchBooks(params.Query, params.Filters, params.Page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationHeaders(w, r, results.Page, results.PageSize, results.Total)
+	writeJSON(w, newSearchPage(results))
+}
+
+// searchResult é um resultado da busca como a API e /ui o exibem: o trecho
+// escapado, com os termos encontrados entre <mark>
+type searchResult struct {
+	*database.BookDetail
+	Snippet template.HTML `json:"snippet"`
+}
+
+// searchPage é uma página de resultados da busca
+type searchPage struct {
+	Query    string          `json:"query"`
+	Results  []*searchResult `json:"results"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// newSearchPage destaca os trechos dos resultados da busca
+func newSearchPage(results *database.SearchResults) *searchPage {
+	page := &searchPage{
+		Query:    results.Query,
+		Results:  make([]*searchResult, len(results.Results)),
+		Total:    results.Total,
+		Page:     results.Page,
+		PageSize: results.PageSize,
+	}
+	for i, r := range results.Results {
+		page.Results[i] = &searchResult{BookDetail: r.BookDetail, Snippet: highlight(r.Snippet, r.Matches)}
+	}
+	return page
+}
+
+// highlight escapa o trecho e envolve os termos encontrados em <mark>. Só o
+// que é gerado aqui é HTML, então o conteúdo do livro nunca vira marcação.
+func highlight(snippet string, matches []database.SnippetMatch) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.Start < last || m.End > len(snippet) || m.Start >= m.End {
+			continue
+		}
+		b.WriteString(html.EscapeString(snippet[last:m.Start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(snippet[m.Start:m.End]))
+		b.WriteString("</mark>")
+		last = m.End
+	}
+	b.WriteString(html.EscapeString(snippet[last:]))
+	return template.HTML(b.String())
+}
+
+// column é um cabeçalho da tabela de /ui; URL, se houver, ordena por ele
+type column struct {
+	Label string
+	URL   string
+	Arrow string
+}
+
+// bookColumns monta os cabeçalhos da tabela com os links de ordenação. Na
+// busca textual a ordem é por relevância, então não há links.
+func bookColumns(r *http.Request, params listParams) []column {
+	columns := []column{
+		{Label: "ISBN"},
+		{Label: "Título", URL: database.SortTitle},
+		{Label: "Autor", URL: database.SortAuthor},
+		{Label: "Editora"},
+		{Label: "Páginas", URL: database.SortPages},
+		{Label: "Publicado", URL: database.SortPublishDate},
+		{Label: "Fontes"},
+	}
+
+	for i, c := range columns {
+		field := c.URL
+		if field == "" || params.Query != "" {
+			columns[i].URL = ""
+			continue
+		}
+
+		sort := database.BookSort{Field: field}
+		if params.Sort.Field == field {
+			sort.Desc = !params.Sort.Desc
+			columns[i].Arrow = " ▲"
+			if params.Sort.Desc {
+				columns[i].Arrow = " ▼"
+			}
+		}
+
+		q := r.URL.Query()
+		q.Set("sort", sort.String())
+		q.Del("page")
+		columns[i].URL = r.URL.Path + "?" + q.Encode()
+	}
+	return columns
+}
+
+// missingOptions são as opções do filtro de metadados ausentes em /ui
+var missingOptions = []struct{ Value, Label string }{
+	{"any", "Com metadados faltando"},
+	{"author", "Sem autor"},
+	{"publisher", "Sem editora"},
+	{"publish_date", "Sem data de publicação"},
+	{"pages", "Sem número de páginas"},
+	{"description", "Sem descrição"},
+	{"cover_url", "Sem capa"},
+}
+
+// handleBooksPage responde GET /ui: a tabela paginada de livros ou, com q,
+// os resultados da busca textual
+func handleBooksPage(w http.ResponseWriter, r *http.Request, db *database.Database) {
+	params, err := parseListParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Query":          params.Query,
+		"Filters":        params.Filters,
+		"Missing":        strings.TrimSpace(r.URL.Query().Get("missing")),
+		"MissingOptions": missingOptions,
+		"Sort":           params.Sort.String(),
+		"Columns":        bookColumns(r, params),
+		"PageSize":       r.URL.Query().Get("page_size"),
+	}
+
+	var page, pageSize, total int
+	if params.Query != "" {
+		results, err := db.SearchBooks(params.Query, params.Filters, params.Page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data["Search"] = newSearchPage(results)
+		page, pageSize, total = results.Page, results.PageSize, results.Total
+	} else {
+		list, err := db.ListBooks(params.Filters, params.Sort, params.Page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data["Books"] = list.Books
+		page, pageSize, total = list.Page, list.PageSize, list.Total
+	}
+
+	data["Total"] = total
+	data["Page"] = page
+	data["PrevURL"], data["NextURL"] = pageLinks(r, page, pageSize, total)
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("erro ao renderizar livros: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"html/template"
+	"testing"
+
+	"leitor-usbn/database"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		matches []database.SnippetMatch
+		want    template.HTML
+	}{
+		{"sem termos", "Clean Code", nil, "Clean Code"},
+		{"termos", "Clean Code", []database.SnippetMatch{{Start: 0, End: 5}, {Start: 6, End: 10}}, "<mark>Clean</mark> <mark>Code</mark>"},
+		{"conteúdo escapado", `<b>clean</b> & "simple"`, []database.SnippetMatch{{Start: 3, End: 8}},
+			"&lt;b&gt;<mark>clean</mark>&lt;/b&gt; &amp; &#34;simple&#34;"},
+		{"posições inválidas ignoradas", "ação", []database.SnippetMatch{{Start: 2, End: 1}, {Start: 0, End: 20}, {Start: 0, End: 6}},
+			"<mark>ação</mark>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.snippet, tt.matches); got != tt.want {
+				t.Errorf("highlight = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
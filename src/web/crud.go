@@ -0,0 +1,554 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"leitor-usbn/api"
+	"leitor-usbn/database"
+	"leitor-usbn/isbn"
+)
+
+// sourceManual é a proveniência registrada para campos editados pela API
+const sourceManual = "manual"
+
+// maxBodySize limita o corpo das requisições de edição
+const maxBodySize = 1 << 20
+
+// apiError é o corpo JSON dos erros das rotas de edição
+type apiError struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"` // erros de validação por campo
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSONStatus(w, status, apiError{Error: msg})
+}
+
+// writeStoreError traduz os erros do banco para o status HTTP correspondente
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, database.ErrConflict), errors.Is(err, database.ErrStale):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// methodNotAllowed responde 405 informando os métodos aceitos
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "método não permitido")
+}
+
+// etag deriva a ETag do registro serializado. Assim ela muda sempre que a
+// resposta muda, inclusive quando um autor ou editora do livro é renomeado
+// sem alterar books.updated_at.
+func etag(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// matchesETag indica se o cabeçalho (If-Match/If-None-Match) contém a ETag
+func matchesETag(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions aplica If-Match (412 se a ETag do registro atual, v,
+// mudou) e o updated_at enviado no corpo (409 se for diferente do atual).
+// Retorna a versão que a gravação deve exigir do banco.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, v interface{}, current time.Time, sent *time.Time) (time.Time, bool) {
+	if header := r.Header.Get("If-Match"); header != "" && !matchesETag(header, etag(v)) {
+		writeError(w, http.StatusPreconditionFailed, "o registro foi alterado: a ETag informada em If-Match não é a atual")
+		return current, false
+	}
+	if sent != nil && !sent.Equal(current) {
+		writeError(w, http.StatusConflict, database.ErrStale.Error())
+		return current, false
+	}
+	return current, true
+}
+
+// writeVersioned responde com o registro e sua ETag; no GET, responde 304 se
+// If-None-Match tiver a ETag atual
+func writeVersioned(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	tag := etag(v)
+	w.Header().Set("ETag", tag)
+	if r.Method == http.MethodGet && matchesETag(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSONStatus(w, status, v)
+}
+
+// decodeBody lê o corpo JSON: 400 se for malformado, 422 se um campo tiver o tipo errado
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &typeErr):
+		writeJSONStatus(w, http.StatusUnprocessableEntity, apiError{
+			Error:  "dados inválidos",
+			Fields: map[string]string{typeErr.Field: fmt.Sprintf("esperado %s", typeErr.Type)},
+		})
+	case errors.As(err, &timeErr):
+		writeJSONStatus(w, http.StatusUnprocessableEntity, apiError{
+			Error:  "dados inválidos",
+			Fields: map[string]string{"updated_at": "data inválida, use RFC 3339"},
+		})
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("JSON inválido: %v", err))
+	}
+	return false
+}
+
+// writeValidation responde 422 com os erros por campo, se houver
+func writeValidation(w http.ResponseWriter, fields map[string]string) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	writeJSONStatus(w, http.StatusUnprocessableEntity, apiError{Error: "dados inválidos", Fields: fields})
+	return true
+}
+
+// bookInput é o corpo de POST/PUT/PATCH de livros. Os nomes seguem o JSON
+// de GET, para que o livro lido possa ser alterado e enviado de volta; campos
+// ausentes (nil) são mantidos no PATCH e apagados no PUT.
+type bookInput struct {
+	ISBN          *string                `json:"isbn"`
+	Title         *string                `json:"title"`
+	Authors       *[]database.BookAuthor `json:"authors"`
+	PublisherName *string                `json:"publisher_name"`
+	PublishDate   *string                `json:"publish_date"`
+	Pages         *int                   `json:"pages"`
+	Description   *string                `json:"description"`
+	CoverURL      *string                `json:"cover_url"`
+	UpdatedAt     *time.Time             `json:"updated_at"`
+}
+
+// validate confere os campos informados; full exige os obrigatórios (POST e PUT)
+func (in bookInput) validate(db *database.Database, full bool) map[string]string {
+	fields := make(map[string]string)
+
+	if in.Title == nil && full {
+		fields["title"] = "obrigatório"
+	} else if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
+		fields["title"] = "não pode ser vazio"
+	}
+	if in.Pages != nil && *in.Pages < 0 {
+		fields["pages"] = "não pode ser negativo"
+	}
+	if in.CoverURL != nil && *in.CoverURL != "" {
+		u, err := url.Parse(*in.CoverURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fields["cover_url"] = "deve ser uma URL http ou https"
+		}
+	}
+
+	if in.Authors != nil {
+		for i, a := range *in.Authors {
+			key := fmt.Sprintf("authors[%d]", i)
+			switch {
+			case !database.IsValidRole(a.Role):
+				fields[key] = fmt.Sprintf("papel inválido: %s", a.Role)
+			case a.AuthorID == 0 && strings.TrimSpace(a.Name) == "":
+				fields[key] = "informe author_id ou name"
+			case a.AuthorID != 0:
+				if author, err := db.GetAuthor(a.AuthorID); err != nil || author == nil {
+					fields[key] = fmt.Sprintf("autor %d não encontrado", a.AuthorID)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// apply monta o livro a gravar a partir do atual (nil na criação). Campos
+// alterados passam a ter proveniência "manual".
+func (in bookInput) apply(current *database.BookDetail, patch bool) (*database.Book, []database.BookAuthor, string) {
+	next := database.BookDetail{Authors: []database.BookAuthor{}}
+	if current != nil {
+		next.ISBN = current.ISBN
+		if patch {
+			next = *current
+		}
+	}
+
+	if in.Title != nil {
+		next.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.Authors != nil {
+		next.Authors = *in.Authors
+	}
+	if in.PublisherName != nil {
+		next.PublisherName = strings.TrimSpace(*in.PublisherName)
+	}
+	if in.PublishDate != nil {
+		next.PublishDate = strings.TrimSpace(*in.PublishDate)
+	}
+	if in.Pages != nil {
+		next.Pages = *in.Pages
+	}
+	if in.Description != nil {
+		next.Description = *in.Description
+	}
+	if in.CoverURL != nil {
+		next.CoverURL = strings.TrimSpace(*in.CoverURL)
+	}
+
+	sources := make(map[string]string)
+	var before database.BookDetail
+	if current != nil {
+		for field, provider := range current.Sources {
+			sources[field] = provider
+		}
+		before = *current
+	}
+	changed := map[string]bool{
+		api.FieldTitle:       before.Title != next.Title,
+		api.FieldAuthor:      !sameAuthors(before.Authors, next.Authors),
+		api.FieldPublisher:   before.PublisherName != next.PublisherName,
+		api.FieldPublishDate: before.PublishDate != next.PublishDate,
+		api.FieldPages:       before.Pages != next.Pages,
+		api.FieldDescription: before.Description != next.Description,
+		api.FieldCoverURL:    before.CoverURL != next.CoverURL,
+	}
+	for field, c := range changed {
+		if c {
+			sources[field] = sourceManual
+		}
+	}
+
+	book := &database.Book{
+		ISBN:        next.ISBN,
+		Title:       next.Title,
+		PublishDate: next.PublishDate,
+		Pages:       next.Pages,
+		Description: next.Description,
+		CoverURL:    next.CoverURL,
+		Sources:     sources,
+	}
+	return book, next.Authors, next.PublisherName
+}
+
+// sameAuthors compara duas listas de autores pelo id (ou nome) e papel, em ordem
+func sameAuthors(a, b []database.BookAuthor) bool {
+	key := func(list []database.BookAuthor) []string {
+		keys := make([]string, len(list))
+		for i, x := range list {
+			role := strings.ToLower(strings.TrimSpace(x.Role))
+			if role == "" {
+				role = database.RoleAuthor
+			}
+			id := strconv.Itoa(x.AuthorID)
+			if x.AuthorID == 0 {
+				id = strings.TrimSpace(x.Name)
+			}
+			keys[i] = id + "/" + role
+		}
+		return keys
+	}
+	return reflect.DeepEqual(key(a), key(b))
+}
+
+// findBook busca o livro pelo ISBN do caminho, como gravado ou normalizado
+func findBook(db *database.Database, raw string) (*database.BookDetail, error) {
+	book, err := db.GetBookDetail(raw)
+	if book != nil || err != nil {
+		return book, err
+	}
+	if code, err := isbn.Normalize(raw); err == nil && code != raw {
+		return db.GetBookDetail(code)
+	}
+	return nil, nil
+}
+
+// handleCreateBook responde POST /api/books
+func handleCreateBook(w http.ResponseWriter, r *http.Request, db *database.Database) {
+	var in bookInput
+	if !decodeBody(w, r, &in) {
+		return
+	}
+
+	fields := in.validate(db, true)
+	code := ""
+	if in.ISBN == nil || strings.TrimSpace(*in.ISBN) == "" {
+		fields["isbn"] = "obrigatório"
+	} else if c, err := isbn.Normalize(*in.ISBN); err != nil {
+		fields["isbn"] = err.Error()
+	} else {
+		code = c
+	}
+	if writeValidation(w, fields) {
+		return
+	}
+
+	book, authors, publisher := in.apply(nil, false)
+	book.ISBN = code
+	if _, err := db.CreateBook(book, authors, publisher); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	created, err := db.GetBookDetail(code)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/books/"+code)
+	writeVersioned(w, r, http.StatusCreated, created)
+}
+
+// handleBook responde GET/PUT/PATCH/DELETE /api/books/{isbn}
+func handleBook(w http.ResponseWriter, r *http.Request, db *database.Database) {
+	raw := strings.TrimPrefix(r.URL.Path, "/api/books/")
+	if raw == "" || strings.Contains(raw, "/") {
+		writeError(w, http.StatusNotFound, "recurso não encontrado")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+		return
+	}
+
+	current, err := findBook(db, raw)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if current == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("livro %s não encontrado", raw))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeVersioned(w, r, http.StatusOK, current)
+
+	case http.MethodDelete:
+		expected, ok := checkPreconditions(w, r, current, current.UpdatedAt, nil)
+		if !ok {
+			return
+		}
+		if err := db.DeleteBook(current.ISBN, expected); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		var in bookInput
+		if !decodeBody(w, r, &in) {
+			return
+		}
+		patch := r.Method == http.MethodPatch
+
+		fields := in.validate(db, !patch)
+		if in.ISBN != nil {
+			if code, err := isbn.Normalize(*in.ISBN); err != nil || (*in.ISBN != current.ISBN && code != current.ISBN) {
+				fields["isbn"] = "não pode ser alterado"
+			}
+		}
+		if writeValidation(w, fields) {
+			return
+		}
+
+		expected, ok := checkPreconditions(w, r, current, current.UpdatedAt, in.UpdatedAt)
+		if !ok {
+			return
+		}
+
+		book, authors, publisher := in.apply(current, patch)
+		if _, err := db.UpdateBook(book, authors, publisher, expected); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+
+		updated, err := db.GetBookDetail(current.ISBN)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, updated)
+	}
+}
+
+// namedInput é o corpo de POST/PUT/PATCH de autores e editoras
+type namedInput struct {
+	Name      *string    `json:"name"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// namedStore reúne as operações de autores ou de editoras. Os dois têm a
+// mesma forma, então editoras circulam aqui convertidas em database.Author.
+type namedStore struct {
+	label  string // usado nas mensagens
+	path   string // prefixo da rota, com a barra final
+	get    func(id int) (*database.Author, error)
+	create func(name string) (*database.Author, error)
+	update func(id int, name string, expected time.Time) (*database.Author, error)
+	remove func(id int, expected time.Time) error
+}
+
+func authorStore(db *database.Database) namedStore {
+	return namedStore{
+		label:  "autor",
+		path:   "/api/authors/",
+		get:    db.GetAuthor,
+		create: db.CreateAuthor,
+		update: db.UpdateAuthor,
+		remove: db.DeleteAuthor,
+	}
+}
+
+func publisherStore(db *database.Database) namedStore {
+	return namedStore{
+		label: "editora",
+		path:  "/api/publishers/",
+		get: func(id int) (*database.Author, error) {
+			p, err := db.GetPublisher(id)
+			return (*database.Author)(p), err
+		},
+		create: func(name string) (*database.Author, error) {
+			p, err := db.CreatePublisher(name)
+			return (*database.Author)(p), err
+		},
+		update: func(id int, name string, expected time.Time) (*database.Author, error) {
+			p, err := db.UpdatePublisher(id, name, expected)
+			return (*database.Author)(p), err
+		},
+		remove: db.DeletePublisher,
+	}
+}
+
+// validate confere o nome; full o exige (POST e PUT)
+func (in namedInput) validate(full bool) map[string]string {
+	fields := make(map[string]string)
+	if in.Name == nil && full {
+		fields["name"] = "obrigatório"
+	} else if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		fields["name"] = "não pode ser vazio"
+	}
+	return fields
+}
+
+// handleCollection responde POST /api/authors e POST /api/publishers
+func (s namedStore) handleCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var in namedInput
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if writeValidation(w, in.validate(true)) {
+		return
+	}
+
+	created, err := s.create(*in.Name)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", s.path+strconv.Itoa(created.ID))
+	writeVersioned(w, r, http.StatusCreated, created)
+}
+
+// handleItem responde GET/PUT/PATCH/DELETE /api/authors/{id} e /api/publishers/{id}
+func (s namedStore) handleItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, s.path))
+	if err != nil || id < 1 {
+		writeError(w, http.StatusNotFound, "recurso não encontrado")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+		return
+	}
+
+	current, err := s.get(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if current == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %d não existe", s.label, id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeVersioned(w, r, http.StatusOK, current)
+
+	case http.MethodDelete:
+		expected, ok := checkPreconditions(w, r, current, current.UpdatedAt, nil)
+		if !ok {
+			return
+		}
+		if err := s.remove(id, expected); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		var in namedInput
+		if !decodeBody(w, r, &in) {
+			return
+		}
+		if writeValidation(w, in.validate(r.Method == http.MethodPut)) {
+			return
+		}
+
+		expected, ok := checkPreconditions(w, r, current, current.UpdatedAt, in.UpdatedAt)
+		if !ok {
+			return
+		}
+
+		name := current.Name
+		if in.Name != nil {
+			name = *in.Name
+		}
+		updated, err := s.update(id, name, expected)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		writeVersioned(w, r, http.StatusOK, updated)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"leitor-usbn/database"
+)
+
+// newTestDB abre um banco com o schema atual em um diretório temporário
+func newTestDB(t *testing.T) *database.Database {
+	t.Helper()
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "books.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// crudHandler registra as rotas de edição como main
+func crudHandler(db *database.Database) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/books", func(w http.ResponseWriter, r *http.Request) {
+		handleCreateBook(w, r, db)
+	})
+	mux.HandleFunc("/api/books/", func(w http.ResponseWriter, r *http.Request) {
+		handleBook(w, r, db)
+	})
+	authors := authorStore(db)
+	mux.HandleFunc("/api/authors", authors.handleCollection)
+	mux.HandleFunc("/api/authors/", authors.handleItem)
+	publishers := publisherStore(db)
+	mux.HandleFunc("/api/publishers", publishers.handleCollection)
+	mux.HandleFunc("/api/publishers/", publishers.handleItem)
+	return mux
+}
+
+// send faz a requisição; headers alterna nome e valor
+func send(t *testing.T, h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// expectStatus confere o status e devolve o corpo decodificado em v, se informado
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int, v interface{}) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, esperado %d: %s", w.Code, status, w.Body.String())
+	}
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("corpo %q: %v", w.Body.String(), err)
+		}
+	}
+}
+
+const cleanCodeJSON = `{
+	"isbn": "0132350882",
+	"title": "Clean Code",
+	"authors": [{"name": "Robert C. Martin"}],
+	"publisher_name": "Prentice Hall",
+	"pages": 431
+}`
+
+func TestBookCRUD(t *testing.T) {
+	h := crudHandler(newTestDB(t))
+
+	w := send(t, h, http.MethodPost, "/api/books", cleanCodeJSON)
+	var created database.BookDetail
+	expectStatus(t, w, http.StatusCreated, &created)
+	if loc := w.Header().Get("Location"); loc != "/api/books/9780132350884" {
+		t.Errorf("Location = %q", loc)
+	}
+	tag := w.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("resposta sem ETag")
+	}
+	if created.ISBN != "9780132350884" || created.Pages != 431 || created.Sources["title"] != sourceManual {
+		t.Errorf("livro criado = %+v", created)
+	}
+
+	expectStatus(t, send(t, h, http.MethodPost, "/api/books", cleanCodeJSON), http.StatusConflict, nil)
+	expectStatus(t, send(t, h, http.MethodGet, "/api/books/9780596007126", ""), http.StatusNotFound, nil)
+
+	// O ISBN-10 do caminho encontra o livro gravado como ISBN-13
+	w = send(t, h, http.MethodGet, "/api/books/0132350882", "")
+	expectStatus(t, w, http.StatusOK, nil)
+	if w.Header().Get("ETag") != tag {
+		t.Errorf("ETag do GET = %s, esperado %s", w.Header().Get("ETag"), tag)
+	}
+
+	w = send(t, h, http.MethodGet, "/api/books/9780132350884", "", "If-None-Match", tag)
+	expectStatus(t, w, http.StatusNotModified, nil)
+	if w.Body.Len() != 0 || w.Header().Get("ETag") != tag {
+		t.Errorf("304 com corpo %q e ETag %q", w.Body.String(), w.Header().Get("ETag"))
+	}
+
+	w = send(t, h, http.MethodPatch, "/api/books/9780132350884", `{"pages": 464}`, "If-Match", tag)
+	var patched database.BookDetail
+	expectStatus(t, w, http.StatusOK, &patched)
+	if patched.Pages != 464 || patched.Title != "Clean Code" {
+		t.Errorf("livro após PATCH = %+v", patched)
+	}
+	newTag := w.Header().Get("ETag")
+	if newTag == tag {
+		t.Error("ETag não mudou após o PATCH")
+	}
+
+	// Versões antigas: If-Match responde 412 e updated_at no corpo, 409
+	expectStatus(t, send(t, h, http.MethodPatch, "/api/books/9780132350884", `{"pages": 1}`, "If-Match", tag),
+		http.StatusPreconditionFailed, nil)
+	stale := fmt.Sprintf(`{"pages": 1, "updated_at": %q}`, created.UpdatedAt.Format(time.RFC3339Nano))
+	var conflict apiError
+	expectStatus(t, send(t, h, http.MethodPatch, "/api/books/9780132350884", stale), http.StatusConflict, &conflict)
+	if conflict.Error != database.ErrStale.Error() {
+		t.Errorf("erro = %q", conflict.Error)
+	}
+
+	expectStatus(t, send(t, h, http.MethodDelete, "/api/books/9780132350884", "", "If-Match", tag), http.StatusPreconditionFailed, nil)
+	expectStatus(t, send(t, h, http.MethodDelete, "/api/books/9780132350884", "", "If-Match", newTag), http.StatusNoContent, nil)
+	expectStatus(t, send(t, h, http.MethodGet, "/api/books/9780132350884", ""), http.StatusNotFound, nil)
+}
+
+func TestBookValidation(t *testing.T) {
+	h := crudHandler(newTestDB(t))
+	expectStatus(t, send(t, h, http.MethodPost, "/api/books", cleanCodeJSON), http.StatusCreated, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		fields []string
+	}{
+		{"campos obrigatórios", http.MethodPost, "/api/books", `{}`, http.StatusUnprocessableEntity, []string{"isbn", "title"}},
+		{"ISBN inválido", http.MethodPost, "/api/books", `{"isbn": "123", "title": "X"}`, http.StatusUnprocessableEntity, []string{"isbn"}},
+		{"valores inválidos", http.MethodPost, "/api/books",
+			`{"isbn": "9780596007126", "title": " ", "pages": -1, "cover_url": "ftp://capa", "authors": [{"name": "X", "role": "revisor"}, {}]}`,
+			http.StatusUnprocessableEntity, []string{"title", "pages", "cover_url", "authors[0]", "authors[1]"}},
+		{"tipo errado", http.MethodPatch, "/api/books/9780132350884", `{"pages": "muitas"}`, http.StatusUnprocessableEntity, []string{"pages"}},
+		{"data inválida", http.MethodPatch, "/api/books/9780132350884", `{"updated_at": "ontem"}`, http.StatusUnprocessableEntity, []string{"updated_at"}},
+		{"ISBN alterado", http.MethodPatch, "/api/books/9780132350884", `{"isbn": "9780596007126"}`, http.StatusUnprocessableEntity, []string{"isbn"}},
+		{"autor inexistente", http.MethodPut, "/api/books/9780132350884", `{"title": "X", "authors": [{"author_id": 999}]}`, http.StatusUnprocessableEntity, []string{"authors[0]"}},
+		{"JSON malformado", http.MethodPost, "/api/books", `{"isbn": `, http.StatusBadRequest, nil},
+		{"método", http.MethodPost, "/api/books/9780132350884", `{}`, http.StatusMethodNotAllowed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body apiError
+			expectStatus(t, send(t, h, tt.method, tt.path, tt.body), tt.status, &body)
+			if len(body.Fields) != len(tt.fields) {
+				t.Errorf("campos = %v, esperado %v", body.Fields, tt.fields)
+			}
+			for _, f := range tt.fields {
+				if body.Fields[f] == "" {
+					t.Errorf("sem erro em %s: %v", f, body.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestBookETagFollowsAuthorRename(t *testing.T) {
+	h := crudHandler(newTestDB(t))
+
+	var book database.BookDetail
+	w := send(t, h, http.MethodPost, "/api/books", cleanCodeJSON)
+	expectStatus(t, w, http.StatusCreated, &book)
+	tag := w.Header().Get("ETag")
+
+	path := fmt.Sprintf("/api/authors/%d", book.Authors[0].AuthorID)
+	expectStatus(t, send(t, h, http.MethodPatch, path, `{"name": "Uncle Bob"}`), http.StatusOK, nil)
+
+	// books.updated_at não mudou, mas a resposta sim
+	w = send(t, h, http.MethodGet, "/api/books/9780132350884", "", "If-None-Match", tag)
+	var renamed database.BookDetail
+	expectStatus(t, w, http.StatusOK, &renamed)
+	if renamed.Authors[0].Name != "Uncle Bob" || w.Header().Get("ETag") == tag {
+		t.Errorf("autores = %+v, ETag %s", renamed.Authors, w.Header().Get("ETag"))
+	}
+	expectStatus(t, send(t, h, http.MethodPatch, "/api/books/9780132350884", `{"pages": 1}`, "If-Match", tag),
+		http.StatusPreconditionFailed, nil)
+}
+
+func TestNamedCRUD(t *testing.T) {
+	for _, prefix := range []string{"/api/authors", "/api/publishers"} {
+		t.Run(prefix, func(t *testing.T) {
+			db := newTestDB(t)
+			h := crudHandler(db)
+
+			var created database.Author
+			w := send(t, h, http.MethodPost, prefix, `{"name": "Addison-Wesley"}`)
+			expectStatus(t, w, http.StatusCreated, &created)
+			item := fmt.Sprintf("%s/%d", prefix, created.ID)
+			if loc := w.Header().Get("Location"); loc != item {
+				t.Errorf("Location = %q, esperado %q", loc, item)
+			}
+			tag := w.Header().Get("ETag")
+
+			expectStatus(t, send(t, h, http.MethodPost, prefix, `{"name": "Addison-Wesley"}`), http.StatusConflict, nil)
+			expectStatus(t, send(t, h, http.MethodPost, prefix, `{"name": ""}`), http.StatusUnprocessableEntity, nil)
+			expectStatus(t, send(t, h, http.MethodPut, item, `{}`), http.StatusUnprocessableEntity, nil)
+			expectStatus(t, send(t, h, http.MethodGet, prefix+"/999", ""), http.StatusNotFound, nil)
+			expectStatus(t, send(t, h, http.MethodGet, prefix+"/abc", ""), http.StatusNotFound, nil)
+			expectStatus(t, send(t, h, http.MethodGet, item, "", "If-None-Match", tag), http.StatusNotModified, nil)
+
+			var renamed database.Author
+			w = send(t, h, http.MethodPut, item, `{"name": "Addison Wesley"}`, "If-Match", tag)
+			expectStatus(t, w, http.StatusOK, &renamed)
+			if renamed.Name != "Addison Wesley" || !renamed.UpdatedAt.After(created.UpdatedAt) {
+				t.Errorf("registro renomeado = %+v", renamed)
+			}
+			expectStatus(t, send(t, h, http.MethodPut, item, `{"name": "X"}`, "If-Match", tag), http.StatusPreconditionFailed, nil)
+			stale := fmt.Sprintf(`{"name": "X", "updated_at": %q}`, created.UpdatedAt.Format(time.RFC3339Nano))
+			expectStatus(t, send(t, h, http.MethodPatch, item, stale), http.StatusConflict, nil)
+
+			// Em uso por um livro, não pode ser removido
+			book := fmt.Sprintf(`{"isbn": "9780201633610", "title": "Design Patterns", "authors": [{"author_id": %d}]}`, created.ID)
+			if prefix == "/api/publishers" {
+				book = `{"isbn": "9780201633610", "title": "Design Patterns", "publisher_name": "Addison Wesley"}`
+			}
+			expectStatus(t, send(t, h, http.MethodPost, "/api/books", book), http.StatusCreated, nil)
+			expectStatus(t, send(t, h, http.MethodDelete, item, ""), http.StatusConflict, nil)
+
+			expectStatus(t, send(t, h, http.MethodDelete, "/api/books/9780201633610", ""), http.StatusNoContent, nil)
+			expectStatus(t, send(t, h, http.MethodDelete, item, ""), http.StatusNoContent, nil)
+			expectStatus(t, send(t, h, http.MethodGet, item, ""), http.StatusNotFound, nil)
+		})
+	}
+}
+
+func TestNamedStoreStaleWrite(t *testing.T) {
+	db := newTestDB(t)
+	store := authorStore(db)
+	author, err := db.CreateAuthor("Robert C. Martin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outra gravação entre a leitura do handler e a do banco: a ETag de
+	// If-Match confere, mas o banco detecta a versão nova
+	store.update = func(id int, name string, expected time.Time) (*database.Author, error) {
+		if _, err := db.UpdateAuthor(id, "Uncle Bob", expected); err != nil {
+			return nil, err
+		}
+		return db.UpdateAuthor(id, name, expected)
+	}
+
+	path := fmt.Sprintf("/api/authors/%d", author.ID)
+	r := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"name": "Bob Martin"}`))
+	r.Header.Set("If-Match", etag(author))
+	w := httptest.NewRecorder()
+	store.handleItem(w, r)
+
+	var body apiError
+	expectStatus(t, w, http.StatusConflict, &body)
+	if body.Error != database.ErrStale.Error() {
+		t.Errorf("erro = %q", body.Error)
+	}
+}
+
+func TestWriteStoreError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: 9780132350884", database.ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: nome em uso", database.ErrConflict), http.StatusConflict},
+		{database.ErrStale, http.StatusConflict},
+		{fmt.Errorf("disco cheio"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		writeStoreError(w, tt.err)
+		if w.Code != tt.status {
+			t.Errorf("writeStoreError(%v) = %d, esperado %d", tt.err, w.Code, tt.status)
+		}
+	}
+}
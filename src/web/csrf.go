@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+)
+
+// csrfCookie é o nome do cookie e do campo de formulário com o token anti-CSRF
+// da interface web
+const csrfCookie = "csrf_token"
+
+// csrfToken retorna o token do navegador, criando o cookie na primeira visita.
+// Os formulários repetem o token em um campo oculto (double submit): outro
+// site consegue enviar o formulário, mas não consegue ler o cookie.
+func csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value != "" {
+		return c.Value
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/ui",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// validCSRF confere se o campo do formulário repete o token do cookie
+func validCSRF(r *http.Request) bool {
+	c, err := r.Cookie(csrfCookie)
+	if err != nil || c.Value == "" {
+		return false
+	}
+	sent := r.PostFormValue(csrfCookie)
+	return subtle.ConstantTimeCompare([]byte(sent), []byte(c.Value)) == 1
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"leitor-usbn/api"
+	"leitor-usbn/database"
+	"leitor-usbn/processor"
+	"leitor-usbn/reader"
+)
+
+// bookTmpl é a página de um livro. Usa html/template porque exibe textos dos
+// provedores e valores digitados no formulário.
+var bookTmpl *template.Template
+
+// refetchTimeout limita a nova consulta aos provedores feita pela página do livro
+const refetchTimeout = 30 * time.Second
+
+// fieldLabels nomeia os campos de metadados na página do livro
+var fieldLabels = map[string]string{
+	api.FieldTitle:       "Título",
+	api.FieldAuthor:      "Autores",
+	api.FieldPublisher:   "Editora",
+	api.FieldPublishDate: "Publicação",
+	api.FieldPages:       "Páginas",
+	api.FieldDescription: "Descrição",
+	api.FieldCoverURL:    "Capa",
+}
+
+// bookMessages são os avisos exibidos após um redirecionamento (?done=...)
+var bookMessages = map[string]string{
+	"saved":     "Alterações salvas.",
+	"refetched": "Metadados consultados novamente no provedor.",
+}
+
+// bookForm são os campos do formulário de edição, como texto
+type bookForm struct {
+	Title       string
+	Authors     string // um por linha: "Nome" ou "Nome (papel)"
+	Publisher   string
+	PublishDate string
+	Pages       string
+	Description string
+	CoverURL    string
+	UpdatedAt   string
+}
+
+// bookPage são os dados do template book.html
+type bookPage struct {
+	Book    *database.BookDetail
+	Copies  []*database.BookCopy
+	History []*database.BookChange
+	Labels  map[string]string
+	Roles   []string
+	Form    bookForm
+	Errors  map[string]string
+	CSRF    string
+	Message string
+	Error   string
+}
+
+// newBookForm preenche o formulário com os dados atuais do livro
+func newBookForm(book *database.BookDetail) bookForm {
+	lines := make([]string, len(book.Authors))
+	for i, a := range book.Authors {
+		lines[i] = a.Name
+		if a.Role != "" && a.Role != database.RoleAuthor {
+			lines[i] += " (" + a.Role + ")"
+		}
+	}
+
+	form := bookForm{
+		Title:       book.Title,
+		Authors:     strings.Join(lines, "\n"),
+		Publisher:   book.PublisherName,
+		PublishDate: book.PublishDate,
+		Description: book.Description,
+		CoverURL:    book.CoverURL,
+		UpdatedAt:   book.UpdatedAt.Format(time.RFC3339Nano),
+	}
+	if book.Pages > 0 {
+		form.Pages = strconv.Itoa(book.Pages)
+	}
+	return form
+}
+
+// readBookForm lê o formulário enviado; quebras de linha vêm como CRLF dos navegadores
+func readBookForm(r *http.Request) bookForm {
+	value := func(name string) string {
+		return strings.ReplaceAll(r.PostFormValue(name), "\r\n", "\n")
+	}
+	return bookForm{
+		Title:       value("title"),
+		Authors:     value("authors"),
+		Publisher:   value("publisher_name"),
+		PublishDate: value("publish_date"),
+		Pages:       value("pages"),
+		Description: value("description"),
+		CoverURL:    value("cover_url"),
+		UpdatedAt:   value("updated_at"),
+	}
+}
+
+// input converte o formulário na mesma entrada usada pelo PUT da API. Autores
+// que já estão no livro são identificados pelo id, para que a proveniência só
+// mude quando a lista mudar de fato.
+func (f bookForm) input(current []database.BookAuthor) (bookInput, map[string]string) {
+	fields := make(map[string]string)
+
+	pages := 0
+	if s := strings.TrimSpace(f.Pages); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			fields["pages"] = "deve ser um número"
+		}
+		pages = n
+	}
+
+	authors := make([]database.BookAuthor, 0)
+	for _, line := range strings.Split(f.Authors, "\n") {
+		a := parseAuthorLine(line)
+		if a.Name == "" {
+			continue
+		}
+		for _, c := range current {
+			if c.Name == a.Name {
+				a.AuthorID = c.AuthorID
+				break
+			}
+		}
+		authors = append(authors, a)
+	}
+
+	return bookInput{
+		Title:         &f.Title,
+		Authors:       &authors,
+		PublisherName: &f.Publisher,
+		PublishDate:   &f.PublishDate,
+		Pages:         &pages,
+		Description:   &f.Description,
+		CoverURL:      &f.CoverURL,
+	}, fields
+}
+
+// parseAuthorLine lê "Nome" ou "Nome (papel)"; parênteses que não contêm um
+// papel conhecido fazem parte do nome
+func parseAuthorLine(line string) database.BookAuthor {
+	line = strings.TrimSpace(line)
+	if strings.HasSuffix(line, ")") {
+		if i := strings.LastIndex(line, "("); i > 0 {
+			role := strings.ToLower(strings.TrimSpace(line[i+1 : len(line)-1]))
+			if role != "" && database.IsValidRole(role) {
+				return database.BookAuthor{Name: strings.TrimSpace(line[:i]), Role: role}
+			}
+		}
+	}
+	return database.BookAuthor{Name: line, Role: database.RoleAuthor}
+}
+
+// handleBookPage responde /ui/books/{isbn} (GET exibe, POST salva a edição) e
+// POST /ui/books/{isbn}/refetch
+func handleBookPage(w http.ResponseWriter, r *http.Request, db *database.Database, proc *processor.Processor) {
+	rest := strings.TrimPrefix(r.URL.Path, "/ui/books/")
+	raw, action := rest, ""
+	if i := strings.Index(rest, "/"); i >= 0 {
+		raw, action = rest[:i], rest[i+1:]
+	}
+	if action != "" && action != "refetch" {
+		http.NotFound(w, r)
+		return
+	}
+
+	book, err := findBook(db, raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if book == nil {
+		http.Error(w, "livro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		if book.ISBN != raw {
+			http.Redirect(w, r, bookPageURL(book.ISBN), http.StatusMovedPermanently)
+			return
+		}
+		page := bookPage{Form: newBookForm(book), Message: bookMessages[r.URL.Query().Get("done")]}
+		renderBookPage(w, r, db, book, page, http.StatusOK)
+	case action == "" && r.Method == http.MethodPost:
+		handleEditBook(w, r, db, book)
+	case action == "refetch" && r.Method == http.MethodPost:
+		handleRefetchBook(w, r, db, proc, book)
+	case action == "":
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	default:
+		methodNotAllowed(w, http.MethodPost)
+	}
+}
+
+// handleEditBook grava o formulário de edição pelo repositório, com a mesma
+// validação e o mesmo controle de concorrência do PUT /api/books/{isbn}
+func handleEditBook(w http.ResponseWriter, r *http.Request, db *database.Database, book *database.BookDetail) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, fmt.Sprintf("formulário inválido: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !validCSRF(r) {
+		http.Error(w, "token CSRF inválido; recarregue a página e tente de novo", http.StatusForbidden)
+		return
+	}
+
+	form := readBookForm(r)
+	sent, err := time.Parse(time.RFC3339Nano, form.UpdatedAt)
+	if err != nil {
+		http.Error(w, "formulário sem a versão do livro (updated_at)", http.StatusBadRequest)
+		return
+	}
+
+	in, fields := form.input(book.Authors)
+	for field, msg := range in.validate(db, true) {
+		fields[field] = msg
+	}
+	if len(fields) > 0 {
+		page := bookPage{Form: form, Errors: fields, Error: "Corrija os campos indicados."}
+		renderBookPage(w, r, db, book, page, http.StatusUnprocessableEntity)
+		return
+	}
+
+	next, authors, publisher := in.apply(book, false)
+	_, err = db.UpdateBook(next, authors, publisher, sent)
+	if errors.Is(err, database.ErrStale) {
+		// Mantém o que foi digitado; salvar de novo sobrescreve a versão atual
+		current, err := findBook(db, book.ISBN)
+		if err != nil || current == nil {
+			http.Error(w, "erro ao recarregar livro", http.StatusInternalServerError)
+			return
+		}
+		form.UpdatedAt = current.UpdatedAt.Format(time.RFC3339Nano)
+		page := bookPage{Form: form, Error: "O livro foi alterado desde que a página foi aberta (veja o histórico). Confira os dados e salve de novo para sobrescrever."}
+		renderBookPage(w, r, db, current, page, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, bookPageURL(book.ISBN)+"?done=saved", http.StatusSeeOther)
+}
+
+// handleRefetchBook consulta de novo os provedores de metadados para o ISBN e
+// grava o resultado como o processador faz com uma leitura
+func handleRefetchBook(w http.ResponseWriter, r *http.Request, db *database.Database, proc *processor.Processor, book *database.BookDetail) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if !validCSRF(r) {
+		http.Error(w, "token CSRF inválido; recarregue a página e tente de novo", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), refetchTimeout)
+	defer cancel()
+
+	result := proc.ProcessEvent(ctx, reader.ScanEvent{
+		ISBN:      book.ISBN,
+		Raw:       book.ISBN,
+		Source:    "interface web",
+		Timestamp: time.Now(),
+	})
+	if !result.Success {
+		page := bookPage{Form: newBookForm(book), Error: "Não foi possível consultar o provedor: " + result.Error}
+		renderBookPage(w, r, db, book, page, http.StatusBadGateway)
+		return
+	}
+
+	http.Redirect(w, r, bookPageURL(book.ISBN)+"?done=refetched", http.StatusSeeOther)
+}
+
+// renderBookPage completa os dados da página (exemplares, histórico, token) e a exibe
+func renderBookPage(w http.ResponseWriter, r *http.Request, db *database.Database, book *database.BookDetail, page bookPage, status int) {
+	copies, err := db.GetBookCopies(book.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	history, err := db.GetBookHistory(book.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page.Book = book
+	page.Copies = copies
+	page.History = history
+	page.Labels = fieldLabels
+	page.Roles = []string{database.RoleAuthor, database.RoleEditor, database.RoleTranslator, database.RoleIllustrator}
+	page.CSRF = csrfToken(w, r)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := bookTmpl.Execute(w, page); err != nil {
+		log.Printf("erro ao renderizar livro %s: %v", book.ISBN, err)
+	}
+}
+
+// bookPageURL é o endereço da página de um livro
+func bookPageURL(code string) string {
+	return "/ui/books/" + url.PathEscape(code)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"leitor-usbn/api"
+	"leitor-usbn/database"
+	"leitor-usbn/processor"
+)
+
+func TestParseAuthorLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want database.BookAuthor
+	}{
+		{"Robert C. Martin", database.BookAuthor{Name: "Robert C. Martin", Role: database.RoleAuthor}},
+		{"  Elisabeth Robson (editor) ", database.BookAuthor{Name: "Elisabeth Robson", Role: database.RoleEditor}},
+		{"Eric Freeman (Translator)", database.BookAuthor{Name: "Eric Freeman", Role: database.RoleTranslator}},
+		{"Marcelo (Tradução) (illustrator)", database.BookAuthor{Name: "Marcelo (Tradução)", Role: database.RoleIllustrator}},
+		{"Grupo (Brasil)", database.BookAuthor{Name: "Grupo (Brasil)", Role: database.RoleAuthor}},
+		{"Sem papel ()", database.BookAuthor{Name: "Sem papel ()", Role: database.RoleAuthor}},
+		{"(editor)", database.BookAuthor{Name: "(editor)", Role: database.RoleAuthor}},
+		{"", database.BookAuthor{Role: database.RoleAuthor}},
+	}
+
+	for _, tt := range tests {
+		if got := parseAuthorLine(tt.line); got != tt.want {
+			t.Errorf("parseAuthorLine(%q) = %+v, esperado %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// fakeProvider devolve sempre o mesmo livro ou o mesmo erro
+type fakeProvider struct {
+	book *api.BookData
+	err  error
+}
+
+func (p *fakeProvider) GetBookData(ctx context.Context, isbn string) (*api.BookData, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	book := *p.book
+	book.ISBN = isbn
+	return &book, nil
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+// bookPageTest é um livro cadastrado e o handler da sua página
+type bookPageTest struct {
+	db       *database.Database
+	provider *fakeProvider
+	handler  http.Handler
+	book     *database.BookDetail
+}
+
+func newBookPageTest(t *testing.T) *bookPageTest {
+	t.Helper()
+	bookTmpl = template.Must(template.ParseFiles("templates/book.html"))
+
+	db := newTestDB(t)
+	_, err := db.CreateBook(&database.Book{ISBN: "9780132350884", Title: "Clean Code", Pages: 431},
+		[]database.BookAuthor{{Name: "Robert C. Martin"}}, "Prentice Hall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &fakeProvider{book: &api.BookData{Title: "Clean Code: A Handbook", Author: "Robert C. Martin", Pages: 464}}
+	proc := processor.NewProcessor(db, provider, nil, processor.ProcessorConfig{MaxRetries: 1, MaxResults: 1})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleBookPage(w, r, db, proc)
+	})
+	return &bookPageTest{db: db, provider: provider, handler: handler, book: book}
+}
+
+// post envia o formulário com o cookie informado ("" = sem cookie)
+func (p *bookPageTest) post(path, cookie string, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != "" {
+		r.AddCookie(&http.Cookie{Name: csrfCookie, Value: cookie})
+	}
+	w := httptest.NewRecorder()
+	p.handler.ServeHTTP(w, r)
+	return w
+}
+
+// editForm é o formulário da página preenchido com os dados do livro
+func (p *bookPageTest) editForm(token string) url.Values {
+	form := newBookForm(p.book)
+	return url.Values{
+		csrfCookie:       {token},
+		"updated_at":     {form.UpdatedAt},
+		"title":          {form.Title},
+		"authors":        {form.Authors},
+		"publisher_name": {form.Publisher},
+		"pages":          {form.Pages},
+	}
+}
+
+func TestBookPageCSRF(t *testing.T) {
+	p := newBookPageTest(t)
+
+	// A página cria o cookie e repete o token no formulário
+	w := httptest.NewRecorder()
+	p.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/books/9780132350884", nil))
+	expectStatus(t, w, http.StatusOK, nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != csrfCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("cookies = %+v", cookies)
+	}
+	token := cookies[0].Value
+	if !strings.Contains(w.Body.String(), `value="`+token+`"`) {
+		t.Error("formulário sem o token do cookie")
+	}
+
+	tests := []struct {
+		name          string
+		cookie, field string
+	}{
+		{"sem cookie", "", token},
+		{"sem campo", token, ""},
+		{"token diferente", token, token + "x"},
+	}
+	for _, path := range []string{"/ui/books/9780132350884", "/ui/books/9780132350884/refetch"} {
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				form := p.editForm(tt.field)
+				form.Set("title", "Alterado")
+				w := p.post(path, tt.cookie, form)
+				expectStatus(t, w, http.StatusForbidden, nil)
+			})
+		}
+	}
+
+	stored, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.UpdatedAt.Equal(p.book.UpdatedAt) {
+		t.Errorf("livro alterado por requisição sem token válido: %+v", stored)
+	}
+}
+
+func TestEditBook(t *testing.T) {
+	p := newBookPageTest(t)
+	const token = "token-de-teste"
+
+	form := p.editForm(token)
+	form.Set("title", "Clean Code (2ª ed.)")
+	form.Set("authors", "Robert C. Martin\r\nDean Wampler (editor)")
+	w := p.post("/ui/books/9780132350884", token, form)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/ui/books/9780132350884?done=saved" {
+		t.Fatalf("status %d, Location %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+
+	saved, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Title != "Clean Code (2ª ed.)" || len(saved.Authors) != 2 || saved.Authors[1].Role != database.RoleEditor {
+		t.Errorf("livro salvo = %+v", saved)
+	}
+	if saved.Sources[api.FieldTitle] != sourceManual {
+		t.Errorf("proveniência do título = %q", saved.Sources[api.FieldTitle])
+	}
+}
+
+func TestEditBookStale(t *testing.T) {
+	p := newBookPageTest(t)
+	const token = "token-de-teste"
+
+	// Outra edição depois que a página foi aberta
+	_, err := p.db.UpdateBook(&database.Book{ISBN: "9780132350884", Title: "Clean Code (outra aba)", Pages: 431},
+		[]database.BookAuthor{{Name: "Robert C. Martin"}}, "Prentice Hall", p.book.UpdatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := p.editForm(token)
+	form.Set("title", "Título <digitado>")
+	form.Set("pages", "500")
+	w := p.post("/ui/books/9780132350884", token, form)
+	expectStatus(t, w, http.StatusConflict, nil)
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`value="Título &lt;digitado&gt;"`,
+		`value="500"`,
+		`name="updated_at" value="` + current.UpdatedAt.Format(time.RFC3339Nano) + `"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("página sem %s", want)
+		}
+	}
+
+	stored, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Clean Code (outra aba)" {
+		t.Errorf("título gravado = %q", stored.Title)
+	}
+}
+
+func TestEditBookValidation(t *testing.T) {
+	p := newBookPageTest(t)
+	const token = "token-de-teste"
+
+	tests := []struct {
+		name, field, value, message string
+	}{
+		{"páginas não numéricas", "pages", "muitas", "deve ser um número"},
+		{"páginas negativas", "pages", "-3", "não pode ser negativo"},
+		{"título vazio", "title", " ", "não pode ser vazio"},
+		{"capa sem http", "cover_url", "capa.jpg", "deve ser uma URL http ou https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := p.editForm(token)
+			form.Set(tt.field, tt.value)
+			w := p.post("/ui/books/9780132350884", token, form)
+			expectStatus(t, w, http.StatusUnprocessableEntity, nil)
+			body := w.Body.String()
+			if !strings.Contains(body, tt.message) || !strings.Contains(body, `value="`+template.HTMLEscapeString(tt.value)+`"`) {
+				t.Errorf("página sem a mensagem %q ou sem o valor digitado", tt.message)
+			}
+		})
+	}
+
+	stored, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.UpdatedAt.Equal(p.book.UpdatedAt) {
+		t.Error("livro alterado por formulário inválido")
+	}
+}
+
+func TestRefetchBook(t *testing.T) {
+	p := newBookPageTest(t)
+	const token = "token-de-teste"
+	form := url.Values{csrfCookie: {token}}
+
+	p.provider.err = errors.New("provedor fora do ar")
+	w := p.post("/ui/books/9780132350884/refetch", token, form)
+	expectStatus(t, w, http.StatusBadGateway, nil)
+	if !strings.Contains(w.Body.String(), "provedor fora do ar") {
+		t.Error("página sem o erro do provedor")
+	}
+
+	p.provider.err = nil
+	w = p.post("/ui/books/9780132350884/refetch", token, form)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/ui/books/9780132350884?done=refetched" {
+		t.Fatalf("status %d, Location %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	stored, err := p.db.GetBookDetail("9780132350884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Pages != 464 {
+		t.Errorf("páginas após nova consulta = %d", stored.Pages)
+	}
+}
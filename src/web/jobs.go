@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"leitor-usbn/api"
+	"leitor-usbn/database"
+	"leitor-usbn/isbn"
+	"leitor-usbn/processor"
+	"leitor-usbn/reader"
+)
+
+// jobsTmpl contém as páginas de importação (jobs.html e job.html)
+var jobsTmpl *template.Template
+
+// maxListSize limita a lista de ISBNs enviada para uma importação
+const maxListSize = 2 << 20
+
+// maxJobs é o número de importações mantidas em memória; as mais antigas já
+// concluídas são descartadas
+const maxJobs = 20
+
+// sseKeepAlive é o intervalo dos comentários que mantêm o stream aberto em proxies
+const sseKeepAlive = 15 * time.Second
+
+// rejectedLine é uma linha da lista que não contém um ISBN válido
+type rejectedLine struct {
+	Line  int
+	Input string
+	Error string
+}
+
+// jobProgress é o evento SSE enviado a cada ISBN processado
+type jobProgress struct {
+	Index      int    `json:"index"` // 1 para o primeiro resultado
+	Total      int    `json:"total"`
+	ISBN       string `json:"isbn"`
+	Origin     string `json:"origin"`
+	Success    bool   `json:"success"`
+	Title      string `json:"title,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+// job é uma importação de lista de ISBNs rodando no processo do servidor
+type job struct {
+	ID        string
+	Name      string // arquivo enviado ou "lista colada"
+	Total     int    // ISBNs válidos enviados ao processador
+	Rejected  []rejectedLine
+	CreatedAt time.Time
+
+	mu       sync.Mutex
+	progress []jobProgress
+	done     bool
+	changed  chan struct{} // fechado e substituído a cada novo evento
+}
+
+// add registra o resultado de um ISBN e acorda os streams abertos
+func (j *job) add(r *processor.ProcessResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	p := jobProgress{
+		Index:      len(j.progress) + 1,
+		Total:      j.Total,
+		ISBN:       r.ISBN,
+		Origin:     r.Event.Origin(),
+		Success:    r.Success,
+		Error:      r.Error,
+		ErrorClass: r.ErrorClass,
+	}
+	if r.Book != nil {
+		p.Title = r.Book.Title
+	}
+	j.progress = append(j.progress, p)
+	j.notify()
+}
+
+// finish marca a importação como concluída
+func (j *job) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = true
+	j.notify()
+}
+
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// since retorna os eventos a partir do índice informado, se a importação
+// terminou e o canal que será fechado no próximo evento
+func (j *job) since(from int) ([]jobProgress, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if from > len(j.progress) {
+		from = len(j.progress)
+	}
+	return append([]jobProgress(nil), j.progress[from:]...), j.done, j.changed
+}
+
+// Done indica se a importação terminou
+func (j *job) Done() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done
+}
+
+// Processed é o número de ISBNs já processados
+func (j *job) Processed() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.progress)
+}
+
+// jobSummary é o resumo da importação, com os mesmos números do impresso por
+// PrintSummary na linha de comando
+type jobSummary struct {
+	Total        int
+	Success      int
+	Errors       int
+	ErrorClasses []processor.ErrorClassCount // em ordem alfabética de classe
+	Failed       []jobProgress
+	Succeeded    []jobProgress
+}
+
+// Summary monta o resumo a partir do progresso da importação: o processador
+// guarda só os últimos keptResults resultados
+func (j *job) Summary() jobSummary {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	summary := jobSummary{Total: len(j.progress)}
+	classes := make(map[string]int)
+	for _, p := range j.progress {
+		if !p.Success {
+			summary.Errors++
+			classes[p.ErrorClass]++
+			summary.Failed = append(summary.Failed, p)
+			continue
+		}
+		summary.Success++
+		summary.Succeeded = append(summary.Succeeded, p)
+	}
+
+	for class, count := range classes {
+		summary.ErrorClasses = append(summary.ErrorClasses, processor.ErrorClassCount{Class: class, Count: count})
+	}
+	sort.Slice(summary.ErrorClasses, func(a, b int) bool {
+		return summary.ErrorClasses[a].Class < summary.ErrorClasses[b].Class
+	})
+	return summary
+}
+
+// jobStore inicia as importações e guarda as recentes
+type jobStore struct {
+	ctx      context.Context
+	db       *database.Database
+	provider api.MetadataProvider
+	workers  int
+
+	mu   sync.Mutex
+	jobs []*job // da mais antiga para a mais recente
+}
+
+// newJobStore cria o registro de importações; ctx encerra as que estiverem rodando
+func newJobStore(ctx context.Context, db *database.Database, provider api.MetadataProvider, workers int) *jobStore {
+	return &jobStore{ctx: ctx, db: db, provider: provider, workers: workers}
+}
+
+// start valida a lista e inicia o processamento em segundo plano
+func (s *jobStore) start(name, list string) (*job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	j := &job{ID: id, Name: name, CreatedAt: time.Now(), changed: make(chan struct{})}
+
+	// Mesmas regras do leitor de arquivo: linhas vazias e comentários são ignorados
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		if _, err := isbn.Normalize(raw); err != nil {
+			j.Rejected = append(j.Rejected, rejectedLine{Line: line, Input: raw, Error: err.Error()})
+			continue
+		}
+		j.Total++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler lista: %w", err)
+	}
+	if j.Total == 0 {
+		return nil, fmt.Errorf("nenhum ISBN válido na lista")
+	}
+
+	listReader := reader.NewFileISBNReaderWithInput(strings.NewReader(list), reader.ReaderConfig{FilePath: name})
+	proc := processor.NewProcessor(s.db, s.provider, listReader, processor.ProcessorConfig{
+		MaxWorkers: s.workers,
+		MaxResults: keptResults,
+	})
+	proc.OnResult(j.add)
+
+	if err := listReader.Start(s.ctx); err != nil {
+		return nil, fmt.Errorf("erro ao iniciar leitor: %w", err)
+	}
+	go func() {
+		defer j.finish()
+		if err := proc.Process(s.ctx); err != nil {
+			log.Printf("erro na importação %s: %v", j.ID, err)
+		}
+	}()
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, j)
+	s.prune()
+	s.mu.Unlock()
+	return j, nil
+}
+
+// prune descarta as importações concluídas mais antigas além de maxJobs
+func (s *jobStore) prune() {
+	excess := len(s.jobs) - maxJobs
+	kept := s.jobs[:0]
+	for _, j := range s.jobs {
+		if excess > 0 && j.Done() {
+			excess--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	s.jobs = kept
+}
+
+// get obtém uma importação pelo id
+func (s *jobStore) get(id string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// recent retorna as importações da mais recente para a mais antiga
+func (s *jobStore) recent() []*job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*job, len(s.jobs))
+	for i, j := range s.jobs {
+		jobs[len(s.jobs)-1-i] = j
+	}
+	return jobs
+}
+
+// handleCollection responde /ui/jobs: GET exibe o formulário e as importações
+// recentes, POST inicia uma importação com a lista colada ou enviada
+func (s *jobStore) handleCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.renderJobs(w, r, "", "", http.StatusOK)
+	case http.MethodPost:
+		s.handleStart(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleStart inicia uma importação com a lista colada ou, se houver, com o
+// arquivo enviado
+func (s *jobStore) handleStart(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxListSize)
+	if err := r.ParseMultipartForm(maxListSize); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, fmt.Sprintf("formulário inválido: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !validCSRF(r) {
+		http.Error(w, "token CSRF inválido; recarregue a página e tente de novo", http.StatusForbidden)
+		return
+	}
+
+	name, list := "lista colada", r.PostFormValue("isbns")
+	if file, header, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("erro ao ler arquivo: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(data) > 0 {
+			name = filepath.Base(header.Filename)
+			list = string(data)
+		}
+	}
+
+	if strings.TrimSpace(list) == "" {
+		s.renderJobs(w, r, list, "Cole uma lista de ISBNs ou envie um arquivo.", http.StatusUnprocessableEntity)
+		return
+	}
+
+	j, err := s.start(name, list)
+	if err != nil {
+		s.renderJobs(w, r, list, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	http.Redirect(w, r, "/ui/jobs/"+j.ID, http.StatusSeeOther)
+}
+
+// handleItem responde GET /ui/jobs/{id} (página da importação) e
+// GET /ui/jobs/{id}/events (progresso via Server-Sent Events)
+func (s *jobStore) handleItem(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/ui/jobs/")
+	id, action := rest, ""
+	if i := strings.Index(rest, "/"); i >= 0 {
+		id, action = rest[:i], rest[i+1:]
+	}
+	if action != "" && action != "events" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	j := s.get(id)
+	if j == nil {
+		http.Error(w, "importação não encontrada", http.StatusNotFound)
+		return
+	}
+
+	if action == "events" {
+		streamJob(w, r, j)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := jobsTmpl.ExecuteTemplate(w, "job.html", map[string]interface{}{"Job": j}); err != nil {
+		log.Printf("erro ao renderizar importação %s: %v", j.ID, err)
+	}
+}
+
+// streamJob envia um evento "result" por ISBN processado e "done" no fim.
+// O id de cada evento é o número de resultados já enviados, para que o
+// navegador retome do ponto certo (Last-Event-ID) ao reconectar.
+func streamJob(w http.ResponseWriter, r *http.Request, j *job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+
+	next, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	if next < 0 {
+		next = 0
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		progress, done, changed := j.since(next)
+		for _, p := range progress {
+			data, _ := json.Marshal(p)
+			fmt.Fprintf(w, "id: %d\nevent: result\ndata: %s\n\n", p.Index, data)
+			next = p.Index
+		}
+		if done {
+			fmt.Fprintf(w, "event: done\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// renderJobs exibe o formulário de importação e as importações recentes
+func (s *jobStore) renderJobs(w http.ResponseWriter, r *http.Request, list, message string, status int) {
+	data := map[string]interface{}{
+		"Jobs":  s.recent(),
+		"List":  list,
+		"Error": message,
+		"CSRF":  csrfToken(w, r),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := jobsTmpl.ExecuteTemplate(w, "jobs.html", data); err != nil {
+		log.Printf("erro ao renderizar importações: %v", err)
+	}
+}
+
+// newJobID gera um identificador aleatório para uma importação
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar id da importação: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
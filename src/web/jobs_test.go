@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"leitor-usbn/processor"
+	"leitor-usbn/reader"
+)
+
+// sseEvent é um evento lido do stream
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// sseStream lê os eventos de uma conexão a /ui/jobs/{id}/events
+type sseStream struct {
+	body   io.ReadCloser
+	lines  *bufio.Reader
+	cancel context.CancelFunc
+}
+
+// openStream conecta ao stream da importação, retomando de lastID se informado
+func openStream(t *testing.T, srv *httptest.Server, id, lastID string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/ui/jobs/"+id+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		cancel()
+		t.Fatalf("status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	s := &sseStream{body: resp.Body, lines: bufio.NewReader(resp.Body), cancel: cancel}
+	t.Cleanup(s.close)
+	return s
+}
+
+func (s *sseStream) close() {
+	s.cancel()
+	s.body.Close()
+}
+
+// next lê o próximo evento; comentários (keep-alive) são ignorados
+func (s *sseStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := s.lines.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream encerrado antes do evento: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if ev.Event != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id: "):
+			ev.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// results lê n eventos "result" e retorna os ISBNs, conferindo que o id de
+// cada um é o seu índice
+func (s *sseStream) results(t *testing.T, n int) []string {
+	t.Helper()
+	var isbns []string
+	for i := 0; i < n; i++ {
+		ev := s.next(t)
+		if ev.Event != "result" {
+			t.Fatalf("evento %q, esperado result", ev.Event)
+		}
+		var p jobProgress
+		if err := json.Unmarshal([]byte(ev.Data), &p); err != nil {
+			t.Fatal(err)
+		}
+		if ev.ID != fmt.Sprint(p.Index) {
+			t.Errorf("id %s para o resultado %d", ev.ID, p.Index)
+		}
+		isbns = append(isbns, p.ISBN)
+	}
+	return isbns
+}
+
+// expectDone confere que o próximo evento é "done" e que o stream termina
+func (s *sseStream) expectDone(t *testing.T) {
+	t.Helper()
+	if ev := s.next(t); ev.Event != "done" {
+		t.Fatalf("evento %q, esperado done", ev.Event)
+	}
+	if rest, err := io.ReadAll(s.lines); err != nil || len(rest) != 0 {
+		t.Errorf("stream continuou após done: %q, %v", rest, err)
+	}
+}
+
+func TestStreamJobResume(t *testing.T) {
+	isbns := []string{"9780132350884", "9780596007126", "9780201633610", "9780134685991", "9780262033848"}
+	j := &job{ID: "abc123", Total: len(isbns), changed: make(chan struct{})}
+	add := func(i int) {
+		j.add(&processor.ProcessResult{
+			ISBN:    isbns[i],
+			Success: i%2 == 0,
+			Event:   reader.ScanEvent{ISBN: isbns[i], Source: "lista.txt", Line: i + 1},
+		})
+	}
+	store := &jobStore{jobs: []*job{j}}
+	srv := httptest.NewServer(http.HandlerFunc(store.handleItem))
+	defer srv.Close()
+
+	add(0)
+	add(1)
+	first := openStream(t, srv, j.ID, "")
+	received := first.results(t, 2)
+	first.close()
+
+	// Resultados enquanto o navegador estava desconectado
+	add(2)
+	add(3)
+
+	second := openStream(t, srv, j.ID, "2")
+	received = append(received, second.results(t, 2)...)
+	add(4)
+	received = append(received, second.results(t, 1)...)
+	j.finish()
+	second.expectDone(t)
+
+	if strings.Join(received, ",") != strings.Join(isbns, ",") {
+		t.Errorf("recebidos %v, esperado %v", received, isbns)
+	}
+
+	// Reconectar depois do fim só confirma o fim; sem Last-Event-ID repete tudo
+	openStream(t, srv, j.ID, "5").expectDone(t)
+	all := openStream(t, srv, j.ID, "")
+	if got := all.results(t, len(isbns)); len(got) != len(isbns) {
+		t.Errorf("recebidos %v", got)
+	}
+	all.expectDone(t)
+
+	// Last-Event-ID inválido ou além do fim não repete nem perde eventos
+	openStream(t, srv, j.ID, "99").expectDone(t)
+	if got := openStream(t, srv, j.ID, "-1").results(t, 1); got[0] != isbns[0] {
+		t.Errorf("Last-Event-ID negativo começou em %v", got)
+	}
+}
+
+func TestJobSummary(t *testing.T) {
+	j := &job{Total: 3, changed: make(chan struct{})}
+	j.add(&processor.ProcessResult{ISBN: "9780132350884", Success: true})
+	j.add(&processor.ProcessResult{ISBN: "9780596007126", ErrorClass: "not_found"})
+	j.add(&processor.ProcessResult{ISBN: "9780201633610", ErrorClass: "network"})
+
+	s := j.Summary()
+	if s.Total != 3 || s.Success != 1 || s.Errors != 2 || len(s.Failed) != 2 || len(s.Succeeded) != 1 {
+		t.Errorf("resumo = %+v", s)
+	}
+	if len(s.ErrorClasses) != 2 || s.ErrorClasses[0].Class != "network" || s.ErrorClasses[1].Class != "not_found" {
+		t.Errorf("classes = %+v", s.ErrorClasses)
+	}
+}
@@ -1,22 +1,41 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"html/template"
 	"log"
 	"net/http"
 	"path/filepath"
-	"text/template"
+	"strings"
 
+	"leitor-usbn/api"
 	"leitor-usbn/database"
+	"leitor-usbn/processor"
+	"leitor-usbn/reader"
 )
 
 var tmpl *template.Template
 
+// keptResults é quantos resultados recentes os processadores do servidor guardam
+const keptResults = 100
+
 func main() {
 	dbPath := flag.String("db", "../books.db", "caminho para o arquivo sqlite")
 	port := flag.Int("port", 8080, "porta HTTP")
+	apiProvider := flag.String("api-provider", api.ProviderOpenLibrary, "provedor de metadados (openlibrary ou googlebooks)")
+	apiURL := flag.String("api-url", "", "URL base da API de livros (vazio usa o endpoint do provedor)")
+	apiKey := flag.String("api-key", "", "chave da API (opcional, Google Books)")
+	cacheDir := flag.String("cache-dir", "./.cache/metadata", "diretório do cache de metadados")
+	noCache := flag.Bool("no-cache", false, "consulta a API sem usar o cache de metadados")
+	apiProviders := flag.String("api-providers", "", "cadeia de provedores em ordem de prioridade (ex.: openlibrary,googlebooks)")
+	apiTimeout := flag.Int("api-timeout", 10, "timeout das requisições à API, em segundos")
+	rate := flag.Float64("api-rate", 2, "limite de requisições por segundo a cada provedor, compartilhado pelos workers")
+	burst := flag.Int("api-burst", 1, "rajada máxima de requisições acima do limite")
+	workers := flag.Int("workers", 1, "número de workers do processador de leituras")
 	flag.Parse()
 
 	abs, _ := filepath.Abs(*dbPath)
@@ -32,31 +51,145 @@ func main() {
 	if err != nil {
 		log.Fatalf("erro ao inicializar schema: %v", err)
 	}
+	if module, err := db.SearchModule(); err == nil && module != "fts5" {
+		log.Printf("aviso: índice de busca usando %s; compile com -tags sqlite_fts5 para ordenar a busca por relevância", module)
+	}
+
+	// processador das leituras recebidas via POST /api/scans
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scanReader := reader.NewHTTPISBNReader(reader.ReaderConfig{})
+	if err := scanReader.Start(ctx); err != nil {
+		log.Fatalf("erro ao iniciar leitor HTTP: %v", err)
+	}
+
+	providerConfig := api.ProviderConfig{
+		Provider: *apiProvider,
+		BaseURL:  *apiURL,
+		APIKey:   *apiKey,
+		Timeout:  *apiTimeout,
+
+		RequestsPerSecond: *rate,
+		Burst:             *burst,
+	}
+	if *apiProviders != "" {
+		providerConfig.Provider = api.ProviderChain
+		for _, name := range strings.Split(*apiProviders, ",") {
+			providerConfig.Providers = append(providerConfig.Providers, api.ProviderConfig{
+				Provider: strings.TrimSpace(name),
+				APIKey:   *apiKey,
+			})
+		}
+	}
+	// a nova consulta pedida pela página do livro ignora o cache, mas grava a
+	// resposta nele; as duas pilhas compartilham as fontes e o limite de
+	// requisições
+	caches := []*api.Cache{nil, nil}
+	if !*noCache {
+		cache, err := api.NewCache(api.CacheConfig{Dir: *cacheDir})
+		if err != nil {
+			log.Fatalf("erro ao criar cache de metadados: %v", err)
+		}
+		refreshCache, err := api.NewCache(api.CacheConfig{Dir: *cacheDir, Refresh: true})
+		if err != nil {
+			log.Fatalf("erro ao criar cache de metadados: %v", err)
+		}
+		caches = []*api.Cache{cache, refreshCache}
+	}
+	providers, err := api.NewProviders(providerConfig, caches...)
+	if err != nil {
+		log.Fatalf("erro ao criar cliente API: %v", err)
+	}
+	provider, refetchProvider := providers[0], providers[1]
+
+	// Os resultados destes processadores só são lidos pelos callbacks; guardar
+	// poucos evita que a memória cresça enquanto o servidor estiver no ar
+	refetchProc := processor.NewProcessor(db, refetchProvider, nil, processor.ProcessorConfig{
+		MaxResults: keptResults,
+	})
+	proc := processor.NewProcessor(db, provider, scanReader, processor.ProcessorConfig{
+		MaxWorkers: *workers,
+		MaxResults: keptResults,
+	})
+	proc.OnResult(func(r *processor.ProcessResult) {
+		title := ""
+		if r.Book != nil {
+			title = r.Book.Title
+		}
+		scanReader.Complete(r.Event.ID, r.Success, title, r.Error)
+	})
+	go func() {
+		if err := proc.Process(ctx); err != nil {
+			log.Printf("erro no processador: %v", err)
+		}
+	}()
 
 	// carregar templates (caminho relativo ao workspace)
 	tmpl = template.Must(template.ParseFiles("src/web/templates/books.html"))
+	bookTmpl = template.Must(template.ParseFiles("src/web/templates/book.html"))
+	jobsTmpl = template.Must(template.ParseFiles("src/web/templates/jobs.html", "src/web/templates/job.html"))
 
 	// handlers
 	http.HandleFunc("/api/books", func(w http.ResponseWriter, r *http.Request) {
-		books, err := db.GetBooksWithDetails()
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		switch r.Method {
+		case http.MethodGet:
+			handleListBooks(w, r, db)
+		case http.MethodPost:
+			handleCreateBook(w, r, db)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
 		}
+	})
 
-		writeJSON(w, books)
+	http.HandleFunc("/api/books/search", func(w http.ResponseWriter, r *http.Request) {
+		handleSearchBooks(w, r, db)
 	})
 
-	http.HandleFunc("/ui", func(w http.ResponseWriter, r *http.Request) {
-		books, err := db.GetBooksWithDetails()
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	http.HandleFunc("/api/books/", func(w http.ResponseWriter, r *http.Request) {
+		handleBook(w, r, db)
+	})
+
+	authors := authorStore(db)
+	http.HandleFunc("/api/authors", authors.handleCollection)
+	http.HandleFunc("/api/authors/", authors.handleItem)
+
+	publishers := publisherStore(db)
+	http.HandleFunc("/api/publishers", publishers.handleCollection)
+	http.HandleFunc("/api/publishers/", publishers.handleItem)
+
+	http.HandleFunc("/api/scans", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		handleSubmitScans(w, r, scanReader)
+	})
+
+	http.HandleFunc("/api/scans/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/scans/")
+		scan, ok := scanReader.GetScan(id)
+		if !ok {
+			http.Error(w, "leitura não encontrada", http.StatusNotFound)
 			return
 		}
-		data := map[string]interface{}{"Books": books}
-		tmpl.Execute(w, data)
+		writeJSON(w, scan)
+	})
+
+	http.HandleFunc("/ui", func(w http.ResponseWriter, r *http.Request) {
+		handleBooksPage(w, r, db)
 	})
 
+	http.HandleFunc("/ui/books/", func(w http.ResponseWriter, r *http.Request) {
+		handleBookPage(w, r, db, refetchProc)
+	})
+
+	// importação de listas de ISBNs, cada uma com seu processador
+	jobs := newJobStore(ctx, db, provider, *workers)
+	http.HandleFunc("/ui/jobs", jobs.handleCollection)
+	http.HandleFunc("/ui/jobs/", jobs.handleItem)
+
 	// Redirect root to UI
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/ui", http.StatusSeeOther)
@@ -73,8 +206,66 @@ func main() {
 	log.Fatal(http.ListenAndServe(addr, nil))
 }
 
+// scanRequest aceita um ISBN único ou um lote
+type scanRequest struct {
+	ISBN  string   `json:"isbn"`
+	ISBNs []string `json:"isbns"`
+}
+
+type scanError struct {
+	Input string `json:"input"`
+	Error string `json:"error"`
+}
+
+// handleSubmitScans valida os ISBNs recebidos e os enfileira no processador
+func handleSubmitScans(w http.ResponseWriter, r *http.Request, scanReader *reader.HTTPISBNReader) {
+	var req scanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("JSON inválido: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	codes := req.ISBNs
+	if req.ISBN != "" {
+		codes = append([]string{req.ISBN}, codes...)
+	}
+	if len(codes) == 0 {
+		http.Error(w, "informe \"isbn\" ou \"isbns\"", http.StatusBadRequest)
+		return
+	}
+
+	scans := make([]*reader.Scan, 0, len(codes))
+	scanErrors := make([]scanError, 0)
+	queueFull := false
+	for _, code := range codes {
+		scan, err := scanReader.Submit(code)
+		if err != nil {
+			queueFull = queueFull || errors.Is(err, reader.ErrQueueFull)
+			scanErrors = append(scanErrors, scanError{Input: code, Error: err.Error()})
+			continue
+		}
+		scans = append(scans, scan)
+	}
+
+	status := http.StatusAccepted
+	if len(scans) == 0 {
+		status = http.StatusUnprocessableEntity
+		if queueFull {
+			w.Header().Set("Retry-After", "5")
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSONStatus(w, status, map[string]interface{}{"scans": scans, "errors": scanErrors})
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
+	writeJSONStatus(w, http.StatusOK, v)
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	_ = enc.Encode(v)